@@ -4,34 +4,87 @@ package agent
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"math/rand"
+	"net"
 	"net/http"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"monitoring-agent/config"
+	"monitoring-agent/logging"
 	pbClient "monitoring-agent/pocketbase"
 )
 
 type Agent struct {
-	config        *config.Config
+	liveConfig    atomic.Pointer[config.Config] // Replaced as a whole on reload, read through config()
 	httpClient    *http.Client
 	pocketBase    *pbClient.PocketBaseClient
+	grpcClient    grpcMetricsClient // Set when TRANSPORT=grpc and the client connected
+	influxSink    *InfluxSink       // Set when TRANSPORT=influxdb
 	ctx           context.Context
 	cancel        context.CancelFunc
+	reload        chan *config.Config // Configuration reloads waiting to be applied by the collection loop
 	wg            sync.WaitGroup
 	
 	// Control state
 	isMonitoring  bool
 	controlMutex  sync.RWMutex
-	serverRecord  *pbClient.ServerRecord // Store server record for updates
+	serverRecord  *pbClient.ServerRecord // Store server record for updates, guarded by recordMutex
+	recordMutex   sync.Mutex             // Guards serverRecord, replaced by status polls
+	extraServers  []*extraServer         // Further AGENT_ID entries reported with the same metrics
+	extraMutex    sync.Mutex             // Guards extraServers and their records
 	currentTicker *time.Ticker           // Current ticker for dynamic interval changes
 	tickerMutex   sync.Mutex             // Mutex for ticker operations
+	
+	// Readiness state for /ready
+	readyMutex    sync.RWMutex
+	registered    bool      // Server record initialized
+	nextRegistration    time.Time     // Earliest retry of a failed registration
+	registrationBackoff time.Duration // Delay after the last failed registration
+	lastPush      time.Time // Last metrics push accepted by PocketBase
+	debug         debugState // Recent errors and pushes for /debug
+	
+	// Collector state
+	entropyWarned   bool // Software-only entropy warning already logged
+	completedCycles atomic.Int64 // Monitoring cycles completed since startup
+	cycleRunning    atomic.Bool // Set while a cycle runs, including one abandoned after COLLECTION_TIMEOUT
+	statsd          *statsdListener // Application metrics received over StatsD, nil when disabled
+	lastCoreDumps   int  // Core dump count from the previous cycle, -1 before the first
+	pendingMetrics  []pbClient.ServerMetricsRecord // Detailed metrics not yet accepted by PocketBase
+	batchDisabled   bool          // PocketBase answered /api/batch with 403 or 404, records are sent one by one
+	clampedInterval time.Duration // Requested interval last warned about for being outside MIN/MAX_CHECK_INTERVAL
+	egress          *egressState  // Outbound bytes in the current quota period, loaded on first use
+	alerts          map[string]*alertState // Threshold state per alertable metric
+	selfCPU         selfCPUSample // Agent CPU time at the previous self-usage sample
+	dryRun          bool          // Collect only: no alerts sent and no state persisted
+	collector       *SystemCollector // Long-lived collector sampling CPU in the background once started
+	configUpdates   chan runtimeUpdate // config_update settings for the collection loop
+	commandInterval time.Duration      // Command poll interval, changed by config_update on the command goroutine
 }
 
+// config returns the running configuration. It is shared by the collection, command and
+// HTTP goroutines, so it must be treated as read-only; changes store a modified copy.
+func (a *Agent) config() *config.Config {
+	return a.liveConfig.Load()
+}
+
+// maxPendingMetrics bounds how many unsent detailed metrics records are kept for the next batch
+const maxPendingMetrics = 120
+
+// stopTimeout bounds how long Stop waits for in-flight collection after cancelling it
+const stopTimeout = 10 * time.Second
+
+// errCycleRunning is returned by runCycle when the previous cycle hasn't returned yet
+var errCycleRunning = errors.New("previous collection cycle is still running")
+
 type SystemMetrics struct {
 	AgentID       string    `json:"agent_id"`
 	Timestamp     time.Time `json:"timestamp"`
@@ -42,6 +95,7 @@ type SystemMetrics struct {
 	Uptime        int64     `json:"uptime"`
 	GoRoutines    int       `json:"goroutines"`
 	Status        string    `json:"status"`
+	Agent         SelfUsage `json:"agent"` // The agent process's own resource usage
 }
 
 type NetworkStats struct {
@@ -49,6 +103,8 @@ type NetworkStats struct {
 	BytesReceived uint64 `json:"bytes_received"`
 	PacketsSent   uint64 `json:"packets_sent"`
 	PacketsReceived uint64 `json:"packets_received"`
+	RxSpeed       uint64 `json:"rx_speed"` // Bytes per second
+	TxSpeed       uint64 `json:"tx_speed"` // Bytes per second
 }
 
 type HealthStatus struct {
@@ -56,57 +112,139 @@ type HealthStatus struct {
 	Timestamp time.Time `json:"timestamp"`
 	AgentID   string    `json:"agent_id"`
 	Version   string    `json:"version"`
+	Commit    string    `json:"commit"`
+	BuildDate string    `json:"build_date"`
+}
+
+// Build information reported by /health, the agent status record and -version. Set at
+// build time with -ldflags "-X monitoring-agent/agent.Version=1.2.3", see the Makefile.
+var (
+	Version   = "dev"
+	Commit    = "dev"
+	BuildDate = "dev"
+)
+
+// userAgent identifies the agent in the User-Agent header of outbound requests
+func userAgent(cfg *config.Config) string {
+	return fmt.Sprintf("monitoring-agent/%s (%s)", Version, cfg.AgentID)
 }
 
 func New(cfg *config.Config) *Agent {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	agent := &Agent{
-		config: cfg,
 		httpClient: &http.Client{
-			Timeout: cfg.RequestTimeout,
+			Timeout:   cfg.RequestTimeout,
+			Transport: pbClient.NewHeaderTransport(nil, userAgent(cfg)),
 		},
 		ctx:          ctx,
 		cancel:       cancel,
+		reload:       make(chan *config.Config, 1),
+		configUpdates: make(chan runtimeUpdate, 1),
+		commandInterval: cfg.CommandCheckInterval,
 		isMonitoring: true,
+		lastCoreDumps: -1,
+		collector:     NewSystemCollector(),
 	}
+	agent.liveConfig.Store(cfg)
+
+	agent.configureCollector()
+
+	// The first self-usage sample reports CPU used since startup
+	agent.selfCPU.ticks, _, _ = readSelfProcStat()
+	agent.selfCPU.time = time.Now()
 
 	// Initialize PocketBase client if enabled and configured
 	if cfg.PocketBaseEnabled && cfg.PocketBaseURL != "" {
-		pbClient, err := pbClient.NewPocketBaseClient(cfg.PocketBaseURL)
+		tlsConfig, err := pbClient.NewTLSConfig(cfg.PocketBaseCACert, cfg.PocketBaseInsecureSkipVerify)
 		if err != nil {
-			log.Printf("Failed to initialize PocketBase client: %v", err)
+			logging.Warnf("Failed to load PocketBase TLS settings, using system defaults: %v", err)
+		}
+
+		pbClient, err := pbClient.NewPocketBaseClient(cfg.PocketBaseURL,
+			pbClient.WithTimeout(cfg.PocketBaseTimeout),
+			pbClient.WithMaxIdleConns(cfg.PocketBaseMaxIdleConns),
+			pbClient.WithTLSConfig(tlsConfig),
+			pbClient.WithUserAgent(userAgent(cfg)),
+		)
+		if err != nil {
+			logging.Errorf("Failed to initialize PocketBase client: %v", err)
 		} else {
+			pbClient.SetRetryPolicy(cfg.MaxRetries, cfg.RetryBackoffBase)
 			agent.pocketBase = pbClient
-			log.Printf("PocketBase client initialized successfully for %s", cfg.PocketBaseURL)
+			logging.Infof("PocketBase client initialized successfully for %s", cfg.PocketBaseURL)
 		}
 	} else {
-		log.Printf("PocketBase disabled or URL not configured")
+		logging.Infof("PocketBase disabled or URL not configured")
+	}
+
+	// Initialize the gRPC client when it is the configured transport
+	if cfg.Transport == "grpc" {
+		client, err := newGRPCMetricsClient(cfg)
+		if err != nil {
+			logging.Errorf("Failed to initialize gRPC client: %v", err)
+		} else {
+			agent.grpcClient = client
+			logging.Infof("gRPC client initialized for %s", cfg.GRPCServerAddr)
+		}
+	}
+
+	// Initialize the InfluxDB sink when it is the configured transport
+	if cfg.Transport == "influxdb" {
+		sink, err := NewInfluxSink(ctx, cfg, agent.collector, agent.httpClient)
+		if err != nil {
+			logging.Errorf("Failed to initialize InfluxDB sink: %v", err)
+		} else {
+			agent.influxSink = sink
+			logging.Infof("InfluxDB sink initialized for %s (bucket %s)", cfg.InfluxDBURL, cfg.InfluxDBBucket)
+		}
 	}
 
 	return agent
 }
 
 func (a *Agent) Start() error {
-	log.Printf("Starting monitoring agent with ID: %s", a.config.AgentID)
+	logging.Infof("Starting monitoring agent with ID: %s", a.config().AgentID)
 	
 	// Validate configuration
 	if err := a.validateConfiguration(); err != nil {
-		log.Printf("Configuration validation failed: %v", err)
+		logging.Errorf("Configuration validation failed: %v", err)
 		return err
 	}
 	
-	// Initialize or find existing server record
-	if err := a.initializeServerRecord(); err != nil {
-		log.Printf("Failed to initialize server record: %v", err)
-		return err
+	// Initialize or find existing server record. If PocketBase stays unreachable, metrics are
+	// collected and queued while registration is retried from the collection loop.
+	if err := a.registerWithRetry(); err != nil {
+		logging.Errorf("Failed to initialize server record: %v", err)
+		logging.Warnf("Starting unregistered, metrics are collected but not reported until registration succeeds")
 	}
 	
+	// Emit a reboot event if the host rebooted since the last run
+	a.detectReboot()
+	
 	// Update agent status (optional - don't fail if collection doesn't exist)
 	if err := a.updateAgentStatus("running", "Agent started successfully"); err != nil {
-		log.Printf("Warning: Failed to update agent status (this is optional): %v", err)
+		logging.Warnf("Failed to update agent status (this is optional): %v", err)
+	}
+	
+	// Start the StatsD listener before collection so the first cycle can include app metrics
+	if a.config().StatsDEnabled {
+		listener, err := newStatsDListener(a.config().StatsDPort)
+		if err != nil {
+			logging.Warnf("StatsD listener disabled: %v", err)
+		} else {
+			a.statsd = listener
+			go listener.serve()
+			logging.Infof("StatsD listener started on 127.0.0.1:%d", a.config().StatsDPort)
+		}
 	}
 	
+	// Continue from the baselines saved on shutdown so the first readings aren't cold-start deltas
+	a.restoreCollectorState()
+	
+	// Sample CPU in the background so collection cycles and /status read it without waiting
+	a.collector.StartCPUSampling(a.ctx, cpuSampleInterval)
+	
 	// Start metrics collection
 	a.wg.Add(1)
 	go a.collectMetrics()
@@ -116,7 +254,7 @@ func (a *Agent) Start() error {
 	go a.startHealthCheckServer()
 	
 	// Start remote control listener if enabled
-	if a.config.RemoteControlEnabled {
+	if a.config().RemoteControlEnabled {
 		a.wg.Add(1)
 		go a.listenForCommands()
 	}
@@ -126,45 +264,84 @@ func (a *Agent) Start() error {
 	return nil
 }
 
+// RunOnce registers the server if needed, runs a single collection cycle and returns
+// whether every push succeeded. It is meant for cron-style runs and closes the gRPC
+// connection before returning; use Start for the long-running agent.
+func (a *Agent) RunOnce() error {
+	if a.grpcClient != nil {
+		defer a.grpcClient.Close()
+	}
+	
+	if err := a.validateConfiguration(); err != nil {
+		return err
+	}
+	if err := a.initializeServerRecord(); err != nil {
+		return fmt.Errorf("failed to initialize server record: %v", err)
+	}
+	
+	// initializeServerRecord stops monitoring when the server record is paused. The other
+	// AGENT_ID servers are still reported unless they are paused too.
+	a.controlMutex.RLock()
+	paused := !a.isMonitoring
+	a.controlMutex.RUnlock()
+	if paused && !a.hasActiveExtraServers() {
+		logging.Infof("Server %s is paused, nothing to collect", a.config().AgentID)
+		return nil
+	}
+	
+	// Consecutive runs from cron measure rates against each other
+	a.restoreCollectorState()
+	defer a.saveCollectorState()
+	
+	return a.runCycle(time.Now(), 0, false)
+}
+
 func (a *Agent) validateConfiguration() error {
 	// Check basic configuration
-	if a.config.AgentID == "" {
+	if a.config().AgentID == "" {
 		return fmt.Errorf("AGENT_ID is required")
 	}
 	
 	// Check PocketBase configuration if enabled
-	if a.config.PocketBaseEnabled {
-		if a.config.PocketBaseURL == "" {
+	if a.config().PocketBaseEnabled {
+		if a.config().PocketBaseURL == "" {
 			return fmt.Errorf("POCKETBASE_URL is required when POCKETBASE_ENABLED=true")
 		}
-		if a.config.ServerName == "" {
+		if a.config().ServerName == "" {
 			return fmt.Errorf("SERVER_NAME is required when POCKETBASE_ENABLED=true")
 		}
-		if a.config.ServerToken == "" {
+		if a.config().ServerToken == "" {
 			return fmt.Errorf("SERVER_TOKEN is required when POCKETBASE_ENABLED=true")
 		}
 	}
 	
-	// Check fallback HTTP configuration if PocketBase is disabled
-	if !a.config.PocketBaseEnabled {
-		if a.config.ServerURL == "" {
-			return fmt.Errorf("SERVER_URL is required when POCKETBASE_ENABLED=false")
+	// Check fallback HTTP configuration
+	if a.config().Transport == "http" {
+		if a.config().ServerURL == "" {
+			return fmt.Errorf("SERVER_URL is required when TRANSPORT=http (or POCKETBASE_ENABLED=false)")
 		}
-		if a.config.APIKey == "" {
-			log.Printf("Warning: API_KEY not set for HTTP fallback")
+		if a.config().APIKey == "" {
+			logging.Warnf("API_KEY not set for HTTP fallback")
 		}
 	}
 	
-	log.Printf("Configuration validation passed")
+	// Check InfluxDB configuration
+	if a.config().Transport == "influxdb" {
+		if a.config().InfluxDBURL == "" || a.config().InfluxDBBucket == "" {
+			return fmt.Errorf("INFLUXDB_URL and INFLUXDB_BUCKET are required when TRANSPORT=influxdb")
+		}
+	}
+	
+	logging.Infof("Configuration validation passed")
 	return nil
 }
 
 func (a *Agent) Stop() {
-	log.Println("Stopping monitoring agent...")
+	logging.Infof("Stopping monitoring agent...")
 	
 	// Update agent status
 	if err := a.updateAgentStatus("stopped", "Agent stopped by user"); err != nil {
-		log.Printf("Failed to update agent status: %v", err)
+		logging.Errorf("Failed to update agent status: %v", err)
 	}
 	
 	// Stop current ticker if exists
@@ -174,112 +351,166 @@ func (a *Agent) Stop() {
 	}
 	a.tickerMutex.Unlock()
 	
+	if a.statsd != nil {
+		a.statsd.close()
+	}
+	if a.grpcClient != nil {
+		a.grpcClient.Close()
+	}
+	
 	a.cancel()
-	a.wg.Wait()
+	
+	// Cancellation aborts collector commands and API calls, but don't hang on anything that ignores it
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(stopTimeout):
+		logging.Warnf("In-flight work did not finish within %v, stopping anyway", stopTimeout)
+	}
+	
+	a.saveCollectorState()
 }
 
 func (a *Agent) initializeServerRecord() error {
 	if a.pocketBase == nil {
-		log.Printf("PocketBase not available, skipping server record initialization")
+		logging.Infof("PocketBase not available, skipping server record initialization")
 		return nil
 	}
 
+	server, err := a.findOrCreateServerRecord(a.config().AgentID, a.config().ServerName)
+	if err != nil {
+		return err
+	}
+	a.setServerRecord(server)
+	
+	// Check if server is paused initially
+	if server.Status == "paused" {
+		logging.Infof("Server %s is currently paused", a.config().AgentID)
+		a.controlMutex.Lock()
+		a.isMonitoring = false
+		a.controlMutex.Unlock()
+	}
+	
+	a.initializeExtraServers()
+	return nil
+}
+
+// findOrCreateServerRecord returns the server record for agentID, registering it under name
+// when PocketBase doesn't have one yet
+func (a *Agent) findOrCreateServerRecord(agentID, name string) (*pbClient.ServerRecord, error) {
 	// Get real hostname and system info
-	collector := NewSystemCollector()
+	collector := a.collector
 	sysInfo := collector.GetSystemInfo()
 
 	// Try to find existing server record by server_id (AgentID)
-	existingServer, err := a.pocketBase.GetServerByID(a.config.AgentID)
+	existingServer, err := a.pocketBase.GetServerByID(a.ctx, agentID)
 	if err == nil {
 		// Server record exists, use it
-		a.serverRecord = existingServer
-		log.Printf("Found existing server record for agent %s (ID: %s)", a.config.AgentID, existingServer.ID)
-		
-		// Check if server is paused initially
-		if existingServer.Status == "paused" {
-			log.Printf("Server %s is currently paused", a.config.AgentID)
-			a.controlMutex.Lock()
-			a.isMonitoring = false
-			a.controlMutex.Unlock()
-		}
-		
-		return nil
+		logging.Infof("Found existing server record for agent %s (ID: %s)", agentID, existingServer.ID)
+		return existingServer, nil
 	}
 
 	// Server record doesn't exist, create a new one
-	log.Printf("Creating new server record for agent %s", a.config.AgentID)
-	
-	// Format comprehensive system info
-	systemInfoString := fmt.Sprintf("%s %s | %s | Kernel: %s | CPU: %s (%d cores) | RAM: %.1f GB | Go %s | IP: %s", 
-		sysInfo.OSName, 
-		sysInfo.OSVersion,
-		sysInfo.Architecture,
-		sysInfo.KernelVersion,
-		sysInfo.CPUModel,
-		sysInfo.CPUCores,
-		float64(sysInfo.TotalRAM)/1024/1024/1024,
-		sysInfo.GoVersion,
-		sysInfo.IPAddress,
-	)
+	logging.Infof("Creating new server record for agent %s", agentID)
+	
+	// Format comprehensive system info, the same way every push does
+	sysInfo.DockerAvailable = collector.IsDockerAvailable()
+	systemInfoString := sysInfo.Format()
 	
 	serverRecord := pbClient.ServerRecord{
-		ServerID:      a.config.AgentID,
-		Name:          a.config.ServerName,
+		ServerID:      agentID,
+		Name:          name,
 		Hostname:      sysInfo.Hostname,  // Use real hostname
 		IPAddress:     sysInfo.IPAddress, // Use real IP address
+		IPv6Address:   sysInfo.IPv6Address,
 		OSType:        sysInfo.OSType,    // Use real OS type
 		Status:        "up",
-		ServerToken:   a.config.ServerToken,
+		ServerToken:   a.config().ServerToken,
 		LastChecked:   pbClient.FlexibleTime{Time: time.Now()},
 		Connection:    "connected",
+		BootTime:      pbClient.FlexibleTime{Time: collector.GetBootTime()},
+		Timezone:      collector.GetTimezone(),
+		Locale:        collector.GetLocale(),
 		SystemInfo:    systemInfoString, // Comprehensive system info
-		CheckInterval: pbClient.FlexibleInt{Value: int(a.config.CheckInterval.Seconds())}, // Set default check interval
+		OSName:        sysInfo.OSName,
+		OSVersion:     sysInfo.OSVersion,
+		KernelVersion: sysInfo.KernelVersion,
+		Architecture:  sysInfo.Architecture,
+		CPUModel:      sysInfo.CPUModel,
+		GoVersion:     sysInfo.GoVersion,
+		CheckInterval: pbClient.FlexibleInt{Value: int(a.config().CheckInterval.Seconds())}, // Set default check interval
 	}
 
-	if err := a.pocketBase.SaveServerMetrics(serverRecord); err != nil {
-		return fmt.Errorf("failed to create server record: %v", err)
+	if err := a.pocketBase.SaveServerMetrics(a.ctx, serverRecord); err != nil {
+		return nil, fmt.Errorf("failed to create server record: %v", err)
 	}
 
 	// Fetch the created record to get the ID
-	createdServer, err := a.pocketBase.GetServerByID(a.config.AgentID)
+	createdServer, err := a.pocketBase.GetServerByID(a.ctx, agentID)
 	if err != nil {
-		return fmt.Errorf("failed to fetch created server record: %v", err)
+		return nil, fmt.Errorf("failed to fetch created server record: %v", err)
 	}
 
-	a.serverRecord = createdServer
-	log.Printf("Successfully created server record with ID: %s", a.serverRecord.ID)
-	return nil
+	logging.Infof("Successfully created server record with ID: %s", createdServer.ID)
+	return createdServer, nil
+}
+
+// currentServerRecord returns the latest server record, nil until registration succeeded.
+// The record itself is never modified, status polls replace it.
+func (a *Agent) currentServerRecord() *pbClient.ServerRecord {
+	a.recordMutex.Lock()
+	defer a.recordMutex.Unlock()
+	return a.serverRecord
+}
+
+func (a *Agent) setServerRecord(record *pbClient.ServerRecord) {
+	a.recordMutex.Lock()
+	a.serverRecord = record
+	a.recordMutex.Unlock()
 }
 
 func (a *Agent) checkServerStatus() (bool, time.Duration, error) {
-	if a.pocketBase == nil || a.serverRecord == nil {
-		return true, a.config.CheckInterval, nil // Default to monitoring if no PocketBase
+	if a.pocketBase == nil || a.currentServerRecord() == nil {
+		return true, a.config().CheckInterval, nil // Default to monitoring if no PocketBase
 	}
 
 	// Fetch current server record to check status and interval
-	currentServer, err := a.pocketBase.GetServerByID(a.config.AgentID)
+	currentServer, err := a.pocketBase.GetServerByID(a.ctx, a.config().AgentID)
 	if err != nil {
-		log.Printf("Failed to fetch server status: %v", err)
-		return true, a.config.CheckInterval, nil // Continue monitoring on error
+		logging.Errorf("Failed to fetch server status: %v", err)
+		return true, a.config().CheckInterval, nil // Continue monitoring on error
 	}
 
 	// Update our local copy
-	a.serverRecord = currentServer
+	a.setServerRecord(currentServer)
 	
-	// Get check interval from server record, fallback to config default
-	checkInterval := a.config.CheckInterval
+	// Get check interval from server record, fallback to config default. Zero or negative
+	// values are treated as unset.
+	checkInterval := a.config().CheckInterval
 	if currentServer.CheckInterval.Value > 0 {
 		checkInterval = time.Duration(currentServer.CheckInterval.Value) * time.Second
 		//log.Printf("Using check interval from server record: %v", checkInterval)
 	}
+	checkInterval = a.clampCheckInterval(checkInterval)
 	
 	// Check if server is paused
 	isPaused := currentServer.Status == "paused"
 	if isPaused {
-		log.Printf("Server %s is paused, skipping monitoring", a.config.AgentID)
 		a.controlMutex.Lock()
+		wasMonitoring := a.isMonitoring
 		a.isMonitoring = false
 		a.controlMutex.Unlock()
+		
+		// Polled often while paused, so only log the transition
+		if wasMonitoring {
+			logging.Infof("Server %s is paused, skipping monitoring", a.config().AgentID)
+		} else {
+			logging.Debugf("Server %s is still paused", a.config().AgentID)
+		}
 	} else {
 		a.controlMutex.Lock()
 		wasMonitoring := a.isMonitoring
@@ -287,118 +518,421 @@ func (a *Agent) checkServerStatus() (bool, time.Duration, error) {
 		a.controlMutex.Unlock()
 		
 		if !wasMonitoring {
-			log.Printf("Server %s monitoring resumed", a.config.AgentID)
+			logging.Infof("Server %s monitoring resumed", a.config().AgentID)
 		}
 	}
 	
 	return !isPaused, checkInterval, nil
 }
 
+// clampCheckInterval keeps an interval within MIN_CHECK_INTERVAL and MAX_CHECK_INTERVAL, so a
+// mistyped dashboard value neither hammers a shared PocketBase nor silences the server
+func (a *Agent) clampCheckInterval(interval time.Duration) time.Duration {
+	clamped, bound := interval, ""
+	if a.config().MinCheckInterval > 0 && interval < a.config().MinCheckInterval {
+		clamped, bound = a.config().MinCheckInterval, "below MIN_CHECK_INTERVAL"
+	} else if a.config().MaxCheckInterval > 0 && interval > a.config().MaxCheckInterval {
+		clamped, bound = a.config().MaxCheckInterval, "above MAX_CHECK_INTERVAL"
+	}
+
+	if bound != "" && interval != a.clampedInterval {
+		logging.Warnf("Check interval %v is %s, using %v", interval, bound, clamped)
+		a.clampedInterval = interval
+	}
+	return clamped
+}
+
 func (a *Agent) collectMetrics() {
 	defer a.wg.Done()
 	
 	// Start with default interval
-	currentInterval := a.config.CheckInterval
+	currentInterval := a.config().CheckInterval
 	a.tickerMutex.Lock()
-	a.currentTicker = time.NewTicker(currentInterval)
+	a.currentTicker = time.NewTicker(a.jitteredInterval(currentInterval))
 	ticker := a.currentTicker
 	a.tickerMutex.Unlock()
 	
 	defer ticker.Stop()
 	
-	for {
-		select {
-		case <-a.ctx.Done():
+	var lastCycleDuration time.Duration
+	
+	// updateInterval restarts the ticker when the effective check interval changed
+	updateInterval := func(newInterval time.Duration) {
+		if newInterval == currentInterval {
 			return
-		case <-ticker.C:
-			// Check server status and get current interval
-			shouldMonitor, newInterval, err := a.checkServerStatus()
-			if err != nil {
-				log.Printf("Error checking server status: %v", err)
-			}
-			
-			// Update ticker if interval changed
-			if newInterval != currentInterval {
-				log.Printf("Check interval changed from %v to %v", currentInterval, newInterval)
-				currentInterval = newInterval
-				
-				a.tickerMutex.Lock()
-				ticker.Stop()
-				a.currentTicker = time.NewTicker(currentInterval)
-				ticker = a.currentTicker
-				a.tickerMutex.Unlock()
-			}
-			
-			if !shouldMonitor {
-				continue // Skip this cycle if server is paused
+		}
+		logging.Infof("Check interval changed from %v to %v", currentInterval, newInterval)
+		currentInterval = newInterval
+		
+		a.tickerMutex.Lock()
+		ticker.Stop()
+		a.currentTicker = time.NewTicker(a.jitteredInterval(currentInterval))
+		ticker = a.currentTicker
+		a.tickerMutex.Unlock()
+	}
+	
+	// Status is polled on its own ticker and cached between polls, so collection doesn't wait
+	// on a server record fetch every cycle. Polling speeds up to PAUSED_POLL_INTERVAL while
+	// the server is paused so a resume is picked up promptly.
+	paused := false
+	statusTicker := time.NewTicker(a.statusPollInterval(paused))
+	defer statusTicker.Stop()
+	
+	// collect runs a collection cycle unless monitoring was stopped by a remote command. While
+	// the server record is paused, cycles still run for the other AGENT_ID servers that aren't.
+	collect := func(cycleStart time.Time) {
+		if paused {
+			if !a.hasActiveExtraServers() {
+				return
 			}
-			
+		} else {
 			a.controlMutex.RLock()
-			if !a.isMonitoring {
-				a.controlMutex.RUnlock()
-				continue
-			}
+			monitoring := a.isMonitoring
 			a.controlMutex.RUnlock()
-			
-			// Collect server metrics for the servers collection
-			serverMetrics := a.gatherServerMetrics()
-			
-			// Collect detailed server metrics for the server_metrics collection
-			detailedMetrics := a.gatherDetailedServerMetrics()
-			
-			// Update server record instead of creating new one
-			if err := a.updateServerRecord(serverMetrics); err != nil {
-				log.Printf("Failed to update server record: %v", err)
-			} else {
-				//log.Printf("Successfully updated server record at %s", time.Now().Format(time.RFC3339))
-			}
-			
-			// Send detailed metrics to the server_metrics collection
-			if err := a.sendDetailedServerMetrics(detailedMetrics); err != nil {
-				log.Printf("Failed to send detailed server metrics: %v", err)
-			} else {
-			//	log.Printf("Successfully sent detailed server metrics at %s", time.Now().Format(time.RFC3339))
+			if !monitoring {
+				return
 			}
+		}
+		
+		// Shed optional collectors for this cycle if the previous one overran its budget
+		budget := a.cycleBudget(currentInterval)
+		shedOptional := budget > 0 && lastCycleDuration > budget
+		if shedOptional {
+			logging.Infof("Previous collection cycle took %v (budget %v), shedding optional collectors this cycle", lastCycleDuration, budget)
+		}
+		
+		if err := a.runCycle(cycleStart, budget, shedOptional); errors.Is(err, errCycleRunning) {
+			logging.Warnf("Skipping collection cycle: %v", err)
+			return
+		} else if err != nil {
+			logging.Warnf("Collection cycle incomplete: %v", err)
+		}
+		
+		if a.completedCycles.Add(1) == int64(a.config().WarmupCycles) {
+			logging.Infof("Warmup complete after %d cycles", a.config().WarmupCycles)
+		}
+		lastCycleDuration = time.Since(cycleStart)
+	}
+	
+	pollStatus := func() {
+		// A cycle abandoned after COLLECTION_TIMEOUT is still pushing with the current server
+		// and extra server records, so they are only refreshed once it returned
+		if a.cycleRunning.Load() {
+			logging.Debugf("Skipping server status poll until the abandoned collection cycle returns")
+			return
+		}
+		
+		shouldMonitor, newInterval, err := a.checkServerStatus()
+		if err != nil {
+			logging.Errorf("Error checking server status: %v", err)
+			a.recordError("server_status", err)
+		}
+		if a.pocketBase != nil {
+			a.pollExtraServers()
+		}
+		
+		// Update ticker if interval changed
+		updateInterval(newInterval)
+		
+		if shouldMonitor != paused {
+			return
+		}
+		paused = !shouldMonitor
+		statusTicker.Reset(a.statusPollInterval(paused))
+		if !paused {
+			// Collect right away and restart the normal interval from here
+			a.tickerMutex.Lock()
+			ticker.Reset(a.jitteredInterval(currentInterval))
+			a.tickerMutex.Unlock()
+			collect(time.Now())
+		}
+	}
+	
+	// Pick up the server record's interval and pause state before the first tick
+	pollStatus()
+	
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case cfg := <-a.reload:
+			a.applyReload(cfg)
+			statusTicker.Reset(a.statusPollInterval(paused))
 			
-			// Handle Docker monitoring if enabled
-			if serverMetrics.Docker.Value {
-			//	log.Printf("Docker is available, collecting Docker metrics...")
-				
-				// Collect Docker container records
-				dockerRecords := a.gatherDockerContainers()
-				if err := a.sendDockerRecords(dockerRecords); err != nil {
-					log.Printf("Failed to send Docker records: %v", err)
-				} else if len(dockerRecords) > 0 {
-				//	log.Printf("Successfully sent %d Docker records at %s", len(dockerRecords), time.Now().Format(time.RFC3339))
-				}
-				
-				// Collect Docker metrics
-				dockerMetrics := a.gatherDockerMetrics()
-				if err := a.sendDockerMetrics(dockerMetrics); err != nil {
-					log.Printf("Failed to send Docker metrics: %v", err)
-				} else if len(dockerMetrics) > 0 {
-					//log.Printf("Successfully sent %d Docker metrics at %s", len(dockerMetrics), time.Now().Format(time.RFC3339))
-				}
-			} else {
-			//	log.Printf("Docker is not available on this server, skipping Docker monitoring")
-			}
+			// The server record's check_interval still wins over CHECK_INTERVAL
+			pollStatus()
+		case update := <-a.configUpdates:
+			a.applyRuntimeUpdate(update)
+			pollStatus()
+		case <-statusTicker.C:
+			pollStatus()
+		case <-ticker.C:
+			collect(time.Now())
+		}
+	}
+}
+
+// statusPollInterval is how often collectMetrics refreshes the server record
+func (a *Agent) statusPollInterval(paused bool) time.Duration {
+	if paused && a.config().PausedPollInterval > 0 {
+		return a.config().PausedPollInterval
+	}
+	return a.config().StatusPollInterval
+}
+
+// cycleStage records which collector a cycle is running so a timeout can name it
+type cycleStage struct {
+	mu   sync.Mutex
+	name string
+}
+
+func (s *cycleStage) set(name string) {
+	s.mu.Lock()
+	s.name = name
+	s.mu.Unlock()
+}
+
+func (s *cycleStage) get() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.name
+}
+
+// runCycle runs one collection cycle bounded by COLLECTION_TIMEOUT. A cycle stuck in a
+// collector that ignores cancellation (a Statfs on a dead NFS mount) is abandoned so the
+// collection loop isn't blocked. Its context is cancelled, so it skips any push it hasn't
+// started and aborts PocketBase requests in flight. Until it returns, later cycles are
+// skipped with errCycleRunning rather than run alongside it on the same agent state, and
+// status polls leave the server records alone. The cycle reads the server record through
+// a snapshot taken when it started.
+func (a *Agent) runCycle(cycleStart time.Time, budget time.Duration, shedOptional bool) error {
+	if !a.cycleRunning.CompareAndSwap(false, true) {
+		return errCycleRunning
+	}
+	
+	timeout := a.config().CollectionTimeout
+	if timeout <= 0 {
+		defer a.cycleRunning.Store(false)
+		return a.runOnce(a.ctx, &cycleStage{}, cycleStart, budget, shedOptional)
+	}
+	
+	ctx, cancel := context.WithTimeout(a.ctx, timeout)
+	defer cancel()
+	
+	stage := &cycleStage{}
+	done := make(chan error, 1)
+	go func() {
+		defer a.cycleRunning.Store(false)
+		done <- a.runOnce(ctx, stage, cycleStart, budget, shedOptional)
+	}()
+	
+	select {
+	case err := <-done:
+		if errors.Is(err, context.DeadlineExceeded) {
+			return a.collectionTimedOut(timeout, stage.get())
+		}
+		return err
+	case <-ctx.Done():
+		if a.ctx.Err() != nil {
+			return a.ctx.Err()
+		}
+		return a.collectionTimedOut(timeout, stage.get())
+	}
+}
+
+// collectionTimedOut logs and records a cycle that ran past COLLECTION_TIMEOUT
+func (a *Agent) collectionTimedOut(timeout time.Duration, collector string) error {
+	err := fmt.Errorf("collection timed out after %v in collector %s", timeout, collector)
+	logging.Warnf("Collector %s did not finish within COLLECTION_TIMEOUT (%v), abandoning this cycle", collector, timeout)
+	a.recordError("collection", err)
+	return err
+}
+
+// runOnce collects metrics and pushes them over the configured transport, followed by the
+// Docker collections when enabled. Failures are logged and recorded for /debug as they
+// happen; the returned error lists the collections that failed. Cancelling ctx aborts
+// collectors still running, and nothing is pushed once it is done. stage is updated with
+// the collector currently running.
+func (a *Agent) runOnce(ctx context.Context, stage *cycleStage, cycleStart time.Time, budget time.Duration, shedOptional bool) error {
+	var failed []string
+	
+	// HTTP and gRPC transports push the summary metrics instead of the PocketBase records
+	if a.config().Transport != "pocketbase" {
+		stage.set("system_metrics")
+		systemMetrics := a.gatherSystemMetrics()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		stage.set(a.config().Transport + " push")
+		if err := a.sendSystemMetrics(systemMetrics); err != nil {
+			logging.Errorf("Failed to send metrics via %s: %v", a.config().Transport, err)
+			a.recordError(a.config().Transport, err)
+			return fmt.Errorf("failed to push metrics via %s", a.config().Transport)
+		}
+		a.recordPush(a.config().Transport)
+		a.readyMutex.Lock()
+		a.lastPush = time.Now()
+		a.readyMutex.Unlock()
+		return nil
+	}
+	
+	// Without a server record only the detailed metrics can be collected; they are queued
+	// and sent with the first push after registration succeeds
+	if a.pocketBase != nil && a.currentServerRecord() == nil && !a.retryRegistration() {
+		stage.set("detailed_server_metrics")
+		detailedMetrics := a.gatherDetailedServerMetrics(ctx)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		a.queueDetailedServerMetrics(detailedMetrics)
+		return fmt.Errorf("server not registered, %d server metrics records queued", len(a.pendingMetrics))
+	}
+	
+	// The cycle works on the server record as of its start, status polls may replace it
+	server := a.currentServerRecord()
+	
+	// Collect server metrics for the servers collection
+	stage.set("server_metrics")
+	serverMetrics := a.gatherServerMetrics(ctx, server, shedOptional)
+	
+	// Collect detailed server metrics for the server_metrics collection
+	stage.set("detailed_server_metrics")
+	detailedMetrics := a.gatherDetailedServerMetrics(ctx)
+	
+	// Records gathered after cancellation are incomplete, so don't push them
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	stage.set("pocketbase push")
+	
+	// A paused server record only leaves the other AGENT_ID servers to report. Docker records
+	// belong to the first AGENT_ID, so they are skipped too.
+	if server != nil && server.Status == "paused" {
+		failed = append(failed, a.pushExtraServers(ctx, serverMetrics, detailedMetrics)...)
+		return pushError(failed)
+	}
+	
+	// Update server record instead of creating new one
+	if err := a.updateServerRecord(ctx, server, serverMetrics); err != nil {
+		logging.Errorf("Failed to update server record: %v", err)
+		a.recordError("servers", err)
+		failed = append(failed, "servers")
+	} else {
+		a.recordPush("servers")
+		logging.Debugf("Successfully updated server record at %s", time.Now().Format(time.RFC3339))
+	}
+	
+	// Send detailed metrics to the server_metrics collection
+	if err := a.sendDetailedServerMetrics(ctx, detailedMetrics); err != nil {
+		logging.Errorf("Failed to send detailed server metrics: %v", err)
+		a.recordError("server_metrics", err)
+		failed = append(failed, "server_metrics")
+	} else {
+		a.recordPush("server_metrics")
+		a.readyMutex.Lock()
+		a.lastPush = time.Now()
+		a.readyMutex.Unlock()
+		logging.Debugf("Successfully sent detailed server metrics at %s", time.Now().Format(time.RFC3339))
+	}
+	
+	// Further AGENT_ID entries get the same metrics under their own records
+	failed = append(failed, a.pushExtraServers(ctx, serverMetrics, detailedMetrics)...)
+	
+	// Handle Docker monitoring if enabled
+	if serverMetrics.Docker.Value && (shedOptional || a.overBudget(cycleStart, budget)) {
+		logging.Infof("Skipping Docker collection to stay within the collection budget (cycle elapsed %v)", time.Since(cycleStart))
+	} else if serverMetrics.Docker.Value {
+		logging.Debugf("Docker is available, collecting Docker metrics...")
+		
+		// Containers are listed once per cycle for both the records and the metrics, so
+		// network speeds cover the whole check interval
+		stage.set("docker")
+		dockerInfo := a.gatherDockerInfo(ctx, server)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		
+		stage.set("pocketbase push")
+		dockerRecords := a.gatherDockerContainers(dockerInfo)
+		if err := a.sendDockerRecords(ctx, dockerRecords); err != nil {
+			logging.Errorf("Failed to send Docker records: %v", err)
+			a.recordError("dockers", err)
+			failed = append(failed, "dockers")
+		} else if len(dockerRecords) > 0 {
+			a.recordPush("dockers")
+			logging.Debugf("Successfully sent %d Docker records at %s", len(dockerRecords), time.Now().Format(time.RFC3339))
+		}
+		
+		dockerMetrics := a.gatherDockerMetrics(dockerInfo)
+		if err := a.sendDockerMetrics(ctx, dockerMetrics); err != nil {
+			logging.Errorf("Failed to send Docker metrics: %v", err)
+			a.recordError("docker_metrics", err)
+			failed = append(failed, "docker_metrics")
+		} else if len(dockerMetrics) > 0 {
+			a.recordPush("docker_metrics")
+			logging.Debugf("Successfully sent %d Docker metrics at %s", len(dockerMetrics), time.Now().Format(time.RFC3339))
 		}
+	} else {
+		logging.Debugf("Docker is not available on this server, skipping Docker monitoring")
 	}
+	
+	return pushError(failed)
 }
 
-func (a *Agent) updateServerRecord(serverMetrics pbClient.ServerRecord) error {
-	if a.pocketBase == nil || a.serverRecord == nil {
+// pushError lists the collections a cycle failed to push, or is nil when all succeeded
+func pushError(failed []string) error {
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to push %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// inWarmup reports whether metrics are still within the startup warmup period
+func (a *Agent) inWarmup() bool {
+	return a.completedCycles.Load() < int64(a.config().WarmupCycles)
+}
+
+// jitteredInterval offsets the ticker period by a random amount of up to
+// CHECK_INTERVAL_JITTER percent either way, so agents started together drift apart
+// instead of pushing to PocketBase at the same moment
+func (a *Agent) jitteredInterval(interval time.Duration) time.Duration {
+	maxOffset := int64(interval) * int64(a.config().CheckIntervalJitter) / 100
+	if maxOffset <= 0 {
+		return interval
+	}
+	jittered := interval + time.Duration(rand.Int63n(2*maxOffset+1)-maxOffset)
+	logging.Debugf("Check interval %v jittered to %v", interval, jittered)
+	return jittered
+}
+
+// cycleBudget returns how long a collection cycle may run before optional collectors are shed
+func (a *Agent) cycleBudget(interval time.Duration) time.Duration {
+	if a.config().CollectionBudgetPercent <= 0 {
+		return 0
+	}
+	return interval * time.Duration(a.config().CollectionBudgetPercent) / 100
+}
+
+// overBudget reports whether the current cycle has already used up its budget
+func (a *Agent) overBudget(cycleStart time.Time, budget time.Duration) bool {
+	return budget > 0 && time.Since(cycleStart) > budget
+}
+
+func (a *Agent) updateServerRecord(ctx context.Context, server *pbClient.ServerRecord, serverMetrics pbClient.ServerRecord) error {
+	if a.pocketBase == nil || server == nil {
 		return fmt.Errorf("no PocketBase client or server record available")
 	}
 
 	// Update the existing server record
-	return a.pocketBase.UpdateServerStatus(a.serverRecord.ID, serverMetrics)
+	return a.pocketBase.UpdateServerStatus(ctx, server.ID, serverMetrics)
 }
 
 func (a *Agent) listenForCommands() {
 	defer a.wg.Done()
 	
-	ticker := time.NewTicker(a.config.CommandCheckInterval)
+	interval := a.commandInterval
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	
 	for {
@@ -407,7 +941,14 @@ func (a *Agent) listenForCommands() {
 			return
 		case <-ticker.C:
 			if err := a.checkForCommands(); err != nil {
-				log.Printf("Warning: Failed to check for commands (this is optional): %v", err)
+				logging.Warnf("Failed to check for commands (this is optional): %v", err)
+			}
+			
+			// config_update may have changed the poll interval
+			if a.commandInterval != interval {
+				logging.Infof("Command check interval changed from %v to %v", interval, a.commandInterval)
+				interval = a.commandInterval
+				ticker.Reset(interval)
 			}
 		}
 	}
@@ -416,7 +957,7 @@ func (a *Agent) listenForCommands() {
 func (a *Agent) checkForCommands() error {
 	// Check PocketBase for commands
 	if a.pocketBase != nil {
-		commands, err := a.pocketBase.GetPendingCommands(a.config.AgentID)
+		commands, err := a.pocketBase.GetPendingCommands(a.ctx, a.config().AgentID)
 		if err != nil {
 			return err
 		}
@@ -426,19 +967,43 @@ func (a *Agent) checkForCommands() error {
 			// Fix: cmd.Parameters is already a string from PocketBase
 			if cmd.Parameters != "" {
 				if err := json.Unmarshal([]byte(cmd.Parameters), &parameters); err != nil {
-					log.Printf("Failed to parse command parameters: %v", err)
+					logging.Errorf("Failed to parse command parameters: %v", err)
+					if !isProbeCommand(cmd.Command) {
+						continue
+					}
+					
+					// A probe with bad parameters still gets a result, then counts as executed
+					if err := a.recordFailedProbe(cmd.Command, fmt.Errorf("malformed parameters: %v", err)); err != nil {
+						logging.Errorf("Failed to record probe result: %v", err)
+						continue
+					}
+					if err := a.pocketBase.MarkCommandExecuted(a.ctx, cmd.ID); err != nil {
+						logging.Errorf("Failed to mark command as executed: %v", err)
+					}
 					continue
 				}
 			}
 			
-			if err := a.executeCommand(cmd.Command, parameters); err != nil {
-				log.Printf("Failed to execute command %s: %v", cmd.Command, err)
+			result, err := a.executeCommand(cmd.Command, parameters)
+			if err != nil {
+				logging.Errorf("Failed to execute command %s: %v", cmd.Command, err)
 				continue
 			}
 			
+			// Commands with output write it back to the record along with the executed flag.
+			// If that fails the command is still marked executed, so it doesn't run again on
+			// every poll.
+			if result != nil {
+				err := a.pocketBase.SaveCommandResult(a.ctx, cmd.ID, *result)
+				if err == nil {
+					continue
+				}
+				logging.Errorf("Failed to save command result: %v", err)
+			}
+			
 			// Fix: Use cmd.ID which now exists in the CommandRecord
-			if err := a.pocketBase.MarkCommandExecuted(cmd.ID); err != nil {
-				log.Printf("Failed to mark command as executed: %v", err)
+			if err := a.pocketBase.MarkCommandExecuted(a.ctx, cmd.ID); err != nil {
+				logging.Errorf("Failed to mark command as executed: %v", err)
 			}
 		}
 	}
@@ -446,23 +1011,29 @@ func (a *Agent) checkForCommands() error {
 	return nil
 }
 
-func (a *Agent) executeCommand(command string, parameters map[string]string) error {
-	log.Printf("Executing command: %s with parameters: %v", command, parameters)
+// executeCommand runs a remote command. Commands that produce output (exec, config_update)
+// return a result to write back to the command record; the others return nil.
+func (a *Agent) executeCommand(command string, parameters map[string]string) (*pbClient.CommandResult, error) {
+	logging.Infof("Executing command: %s with parameters: %v", command, parameters)
 	
 	switch command {
 	case "start":
-		return a.startMonitoring()
+		return nil, a.startMonitoring()
 	case "stop":
-		return a.stopMonitoring()
+		return nil, a.stopMonitoring()
 	case "restart":
 		if err := a.stopMonitoring(); err != nil {
-			return err
+			return nil, err
 		}
-		return a.startMonitoring()
+		return nil, a.startMonitoring()
 	case "config_update":
 		return a.updateConfiguration(parameters)
+	case "check_http", "check_tcp":
+		return nil, a.runProbe(command, parameters)
+	case "exec":
+		return a.runExec(parameters), nil
 	default:
-		return fmt.Errorf("unknown command: %s", command)
+		return nil, fmt.Errorf("unknown command: %s", command)
 	}
 }
 
@@ -471,7 +1042,7 @@ func (a *Agent) startMonitoring() error {
 	defer a.controlMutex.Unlock()
 	
 	a.isMonitoring = true
-	log.Println("Monitoring started via remote command")
+	logging.Infof("Monitoring started via remote command")
 	return a.updateAgentStatus("running", "Monitoring started via remote command")
 }
 
@@ -480,31 +1051,24 @@ func (a *Agent) stopMonitoring() error {
 	defer a.controlMutex.Unlock()
 	
 	a.isMonitoring = false
-	log.Println("Monitoring stopped via remote command")
+	logging.Infof("Monitoring stopped via remote command")
 	return a.updateAgentStatus("paused", "Monitoring stopped via remote command")
 }
 
-func (a *Agent) updateConfiguration(parameters map[string]string) error {
-	// Update configuration based on parameters
-	// This is a simplified implementation
-	log.Printf("Configuration update requested with parameters: %v", parameters)
-	return a.updateAgentStatus("running", "Configuration updated via remote command")
-}
-
 func (a *Agent) updateAgentStatus(status, message string) error {
 	// Update via PocketBase
 	if a.pocketBase != nil {
 		statusRecord := pbClient.AgentStatusRecord{
-			AgentID:  a.config.AgentID,
+			AgentID:  a.config().AgentID,
 			Status:   status,
 			LastSeen: time.Now(),
-			Version:  "1.0.0",
+			Version:  Version,
 			Message:  message,
 		}
 		
-		if err := a.pocketBase.UpdateAgentStatus(statusRecord); err != nil {
+		if err := a.pocketBase.UpdateAgentStatus(a.ctx, statusRecord); err != nil {
 			// Don't treat this as a fatal error, just log it
-			log.Printf("Warning: Failed to update status via PocketBase: %v", err)
+			logging.Warnf("Failed to update status via PocketBase: %v", err)
 			return err
 		}
 	}
@@ -516,53 +1080,74 @@ func (a *Agent) gatherSystemMetrics() SystemMetrics {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 	
-	collector := NewSystemCollector()
+	collector := a.collector
 	uptimeSeconds := collector.GetSystemUptime()
 	
 	return SystemMetrics{
-		AgentID:     a.config.AgentID,
-		Timestamp:   time.Now(),
-		CPUUsage:    collector.GetCPUUsage(),
-		MemoryUsage: float64(m.Alloc) / 1024 / 1024, // MB
-		DiskUsage:   a.getDiskUsage(),
-		NetworkStats: NetworkStats{
-			BytesSent:       0, // Would implement actual network stats
-			BytesReceived:   0,
-			PacketsSent:     0,
-			PacketsReceived: 0,
-		},
+		AgentID:      a.config().AgentID,
+		Timestamp:    time.Now(),
+		CPUUsage:     collector.GetCPUUsage(),
+		MemoryUsage:  float64(m.Alloc) / 1024 / 1024, // MB
+		DiskUsage:    a.getDiskUsage(),
+		NetworkStats: collector.GetNetworkStats(),
 		Uptime:     uptimeSeconds,
 		GoRoutines: runtime.NumGoroutine(),
 		Status:     "healthy",
+		Agent:      a.getSelfUsage(),
 	}
 }
 
 func (a *Agent) getCPUUsage() float64 {
-	collector := NewSystemCollector()
-	return collector.GetCPUUsage()
+	return a.collector.GetCPUUsage()
+}
+
+// configureCollector applies the collector settings from the configuration
+func (a *Agent) configureCollector() {
+	cfg := a.config()
+	a.collector.SetContainerRuntime(cfg.ContainerRuntime)
+	a.collector.SetDockerStatsConcurrency(cfg.DockerStatsConcurrency)
+	a.collector.SetContainerFilter(cfg.DockerIncludePattern, cfg.DockerExcludePattern, cfg.DockerLabelFilter)
+	a.collector.SetDiskRootPath(cfg.DiskRootPath)
+	a.collector.SetReportInterface(cfg.ReportInterface)
 }
 
 func (a *Agent) getDiskUsage() float64 {
-	collector := NewSystemCollector()
-	_, _, percentage := collector.GetDiskUsage()
+	_, _, percentage := a.collector.GetDiskUsage()
 	return percentage
 }
 
+// sendSystemMetrics pushes summary metrics over the HTTP, gRPC or InfluxDB transport
+func (a *Agent) sendSystemMetrics(metrics SystemMetrics) error {
+	switch a.config().Transport {
+	case "grpc":
+		if a.grpcClient == nil {
+			return fmt.Errorf("no gRPC client available")
+		}
+		return a.grpcClient.SendMetrics(metrics)
+	case "influxdb":
+		if a.influxSink == nil {
+			return fmt.Errorf("no InfluxDB sink available")
+		}
+		return a.influxSink.SendMetrics(metrics)
+	}
+	return a.sendMetricsHTTP(metrics)
+}
+
 func (a *Agent) sendMetricsHTTP(metrics SystemMetrics) error {
 	jsonData, err := json.Marshal(metrics)
 	if err != nil {
 		return fmt.Errorf("failed to marshal metrics: %w", err)
 	}
 	
-	url := fmt.Sprintf("%s/api/metrics", a.config.ServerURL)
+	url := fmt.Sprintf("%s/api/metrics", a.config().ServerURL)
 	req, err := http.NewRequestWithContext(a.ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 	
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+a.config.APIKey)
-	req.Header.Set("X-Agent-ID", a.config.AgentID)
+	req.Header.Set("Authorization", "Bearer "+a.config().APIKey)
+	req.Header.Set("X-Agent-ID", a.config().AgentID)
 	
 	resp, err := a.httpClient.Do(req)
 	if err != nil {
@@ -574,7 +1159,7 @@ func (a *Agent) sendMetricsHTTP(metrics SystemMetrics) error {
 		return fmt.Errorf("server returned status %d", resp.StatusCode)
 	}
 	
-	log.Printf("Successfully sent metrics via HTTP at %s", metrics.Timestamp.Format(time.RFC3339))
+	logging.Debugf("Successfully sent metrics via HTTP at %s", metrics.Timestamp.Format(time.RFC3339))
 	return nil
 }
 
@@ -583,19 +1168,33 @@ func (a *Agent) startHealthCheckServer() {
 	
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", a.healthHandler)
+	mux.HandleFunc("/ready", a.readyHandler)
 	mux.HandleFunc("/status", a.statusHandler)
-	mux.HandleFunc("/control/start", a.controlStartHandler)
-	mux.HandleFunc("/control/stop", a.controlStopHandler)
+	mux.HandleFunc("/debug", a.requireControlAuth(a.debugHandler))
+	mux.HandleFunc("/control/start", a.requireControlAuth(a.controlStartHandler))
+	mux.HandleFunc("/control/stop", a.requireControlAuth(a.controlStopHandler))
+	
+	if a.config().ControlAuthToken == "" {
+		logging.Warnf("Control endpoints are unauthenticated, set CONTROL_AUTH_TOKEN to require a bearer token")
+	}
 	
+	bind := strings.TrimSuffix(strings.TrimPrefix(a.config().HealthCheckBind, "["), "]")
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", a.config.HealthCheckPort),
+		Addr:    net.JoinHostPort(bind, strconv.Itoa(a.config().HealthCheckPort)),
 		Handler: mux,
 	}
 	
 	go func() {
-		log.Printf("Health check server starting on port %d", a.config.HealthCheckPort)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("Health check server error: %v", err)
+		var err error
+		if a.config().HealthCheckTLSCert != "" {
+			logging.Infof("Health check server starting on %s (HTTPS)", server.Addr)
+			err = server.ListenAndServeTLS(a.config().HealthCheckTLSCert, a.config().HealthCheckTLSKey)
+		} else {
+			logging.Infof("Health check server starting on %s", server.Addr)
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logging.Errorf("Health check server error: %v", err)
 		}
 	}()
 	
@@ -605,7 +1204,7 @@ func (a *Agent) startHealthCheckServer() {
 	defer cancel()
 	
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("Health check server shutdown error: %v", err)
+		logging.Errorf("Health check server shutdown error: %v", err)
 	}
 }
 
@@ -613,20 +1212,66 @@ func (a *Agent) healthHandler(w http.ResponseWriter, r *http.Request) {
 	health := HealthStatus{
 		Status:    "healthy",
 		Timestamp: time.Now(),
-		AgentID:   a.config.AgentID,
-		Version:   "1.0.0",
+		AgentID:   a.config().AgentID,
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(health)
 }
 
+// readyHandler returns 200 once the server record is registered and a metrics push went
+// through, 503 with the reason otherwise. Without PocketBase there is nothing to push to.
+func (a *Agent) readyHandler(w http.ResponseWriter, r *http.Request) {
+	a.readyMutex.RLock()
+	registered, lastPush := a.registered, a.lastPush
+	a.readyMutex.RUnlock()
+	
+	response := map[string]interface{}{"ready": false}
+	status := http.StatusServiceUnavailable
+	switch {
+	case !registered:
+		response["reason"] = "server record not initialized"
+	case a.pocketBase != nil && lastPush.IsZero():
+		response["reason"] = "no metrics pushed yet"
+	case a.grpcClient != nil && !a.grpcClient.IsConnected():
+		response["reason"] = "gRPC server not connected"
+	default:
+		response["ready"] = true
+		status = http.StatusOK
+		if !lastPush.IsZero() {
+			response["last_push"] = lastPush.Format(time.RFC3339)
+		}
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
 func (a *Agent) statusHandler(w http.ResponseWriter, r *http.Request) {
 	metrics := a.gatherSystemMetrics()
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(metrics)
 }
 
+// requireControlAuth rejects requests without a matching bearer token when CONTROL_AUTH_TOKEN is set
+func (a *Agent) requireControlAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := a.config().ControlAuthToken; token != "" {
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
 func (a *Agent) controlStartHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
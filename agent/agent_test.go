@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"monitoring-agent/config"
+	pbClient "monitoring-agent/pocketbase"
+)
+
+// newTestAgent returns an agent running with cfg and no transport configured
+func newTestAgent(cfg *config.Config) *Agent {
+	a := &Agent{ctx: context.Background(), lastCoreDumps: -1, collector: NewSystemCollector()}
+	a.liveConfig.Store(cfg)
+	return a
+}
+
+func TestRunCycleSkipsWhilePreviousCycleRuns(t *testing.T) {
+	a := newTestAgent(&config.Config{CollectionTimeout: time.Second})
+
+	// An abandoned cycle holds the flag until its runOnce returns
+	a.cycleRunning.Store(true)
+	if err := a.runCycle(time.Now(), 0, false); !errors.Is(err, errCycleRunning) {
+		t.Fatalf("runCycle during a running cycle = %v, want errCycleRunning", err)
+	}
+	if !a.cycleRunning.Load() {
+		t.Error("a skipped cycle cleared the running flag of the cycle it skipped")
+	}
+}
+
+func TestCheckForCommandsMarksExecutedWhenResultIsRejected(t *testing.T) {
+	var mu sync.Mutex
+	var patches []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"items": []pbClient.CommandRecord{{ID: "cmd1", Command: "exec", Parameters: `{"command":"uptime"}`}},
+			})
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var patch map[string]interface{}
+		json.Unmarshal(body, &patch)
+		mu.Lock()
+		patches = append(patches, patch)
+		mu.Unlock()
+
+		// PocketBase rejects the output, e.g. because the collection lacks the result fields
+		if _, hasResult := patch["result"]; hasResult {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	pb, err := pbClient.NewPocketBaseClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestAgent(&config.Config{AgentID: "agent-1"})
+	a.pocketBase = pb
+
+	if err := a.checkForCommands(); err != nil {
+		t.Fatalf("checkForCommands: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(patches) != 2 {
+		t.Fatalf("got %d PATCH requests, want the result followed by the executed flag", len(patches))
+	}
+	if executed, _ := patches[1]["executed"].(bool); !executed || len(patches[1]) != 1 {
+		t.Errorf("fallback PATCH = %v, want only executed=true", patches[1])
+	}
+}
+
+func TestAbandonedCycleKeepsItsServerRecord(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var patched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"items": []pbClient.ServerRecord{{ID: "rec-new", ServerID: "host", Status: "up"}},
+			})
+		case http.MethodPatch:
+			mu.Lock()
+			patched = append(patched, r.URL.Path)
+			mu.Unlock()
+			<-release // Hold the cycle past COLLECTION_TIMEOUT
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	pb, err := pbClient.NewPocketBaseClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb.SetRetryPolicy(0, 0)
+	a := newTestAgent(&config.Config{AgentID: "host", Transport: "pocketbase", CollectionTimeout: 2 * time.Second})
+	a.pocketBase = pb
+	a.setServerRecord(&pbClient.ServerRecord{ID: "rec-old", ServerID: "host", Status: "up"})
+
+	if err := a.runCycle(time.Now(), 0, false); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("runCycle = %v, want a timeout", err)
+	}
+
+	// A status poll from the collection loop replaces the record while the cycle still runs
+	if _, _, err := a.checkServerStatus(); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.currentServerRecord().ID; got != "rec-new" {
+		t.Fatalf("server record %q after the poll, want rec-new", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(patched) != 1 || !strings.HasSuffix(patched[0], "/rec-old") {
+		t.Errorf("cycle updated %v, want the record it started with", patched)
+	}
+}
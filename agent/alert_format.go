@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Attachment and embed colors for fired and resolved alerts
+const (
+	alertColorFiring   = "#E01E5A"
+	alertColorResolved = "#2EB67D"
+)
+
+// alertPayload encodes an alert for the webhook type: the raw Alert for generic
+// webhooks, or a formatted message for Slack and Discord incoming webhooks
+func alertPayload(webhookType string, alert Alert) ([]byte, error) {
+	switch webhookType {
+	case "slack":
+		return json.Marshal(slackAlertPayload(alert))
+	case "discord":
+		return json.Marshal(discordAlertPayload(alert))
+	default:
+		return json.Marshal(alert)
+	}
+}
+
+// alertTitle summarizes an alert, e.g. "[FIRING] CPU above 90% on web-1"
+func alertTitle(alert Alert) string {
+	server := alert.Server
+	if server == "" {
+		server = alert.Hostname
+	}
+
+	direction := "above"
+	if alert.Status == "resolved" {
+		direction = "back below"
+	}
+	return fmt.Sprintf("[%s] %s %s %.0f%% on %s", strings.ToUpper(alert.Status), alertMetricName(alert.Metric), direction, alert.Threshold, server)
+}
+
+// alertMetricName returns the display name of an alertable metric
+func alertMetricName(metric string) string {
+	switch metric {
+	case "cpu":
+		return "CPU"
+	case "memory":
+		return "Memory"
+	case "disk":
+		return "Disk"
+	}
+	return metric
+}
+
+// alertColor returns the hex color for the alert status
+func alertColor(alert Alert) string {
+	if alert.Status == "resolved" {
+		return alertColorResolved
+	}
+	return alertColorFiring
+}
+
+// alertFields returns the name/value pairs shown under the title, in display order.
+// Empty values are left out since Discord rejects embed fields without a value.
+func alertFields(alert Alert) [][2]string {
+	candidates := [][2]string{
+		{"Server", alert.Server},
+		{"Hostname", alert.Hostname},
+		{"Metric", alertMetricName(alert.Metric)},
+		{"Value", fmt.Sprintf("%.1f%%", alert.Value)},
+		{"Threshold", fmt.Sprintf("%.0f%%", alert.Threshold)},
+		{"Since", alert.Since.Format(time.RFC3339)},
+	}
+
+	fields := make([][2]string, 0, len(candidates))
+	for _, field := range candidates {
+		if field[1] != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// slackAlertPayload formats an alert as a Slack message with a colored attachment
+func slackAlertPayload(alert Alert) map[string]interface{} {
+	var fields []map[string]interface{}
+	for _, field := range alertFields(alert) {
+		fields = append(fields, map[string]interface{}{
+			"title": field[0],
+			"value": field[1],
+			"short": true,
+		})
+	}
+
+	title := alertTitle(alert)
+	return map[string]interface{}{
+		"text": title,
+		"attachments": []map[string]interface{}{{
+			"fallback": title,
+			"color":    alertColor(alert),
+			"title":    title,
+			"fields":   fields,
+			"footer":   "agent " + alert.AgentID,
+			"ts":       alert.Timestamp.Unix(),
+		}},
+	}
+}
+
+// discordAlertPayload formats an alert as a Discord message with a colored embed
+func discordAlertPayload(alert Alert) map[string]interface{} {
+	var fields []map[string]interface{}
+	for _, field := range alertFields(alert) {
+		fields = append(fields, map[string]interface{}{
+			"name":   field[0],
+			"value":  field[1],
+			"inline": true,
+		})
+	}
+
+	// Discord takes the embed color as a decimal RGB integer
+	var color int
+	fmt.Sscanf(strings.TrimPrefix(alertColor(alert), "#"), "%x", &color)
+
+	return map[string]interface{}{
+		"embeds": []map[string]interface{}{{
+			"title":     alertTitle(alert),
+			"color":     color,
+			"fields":    fields,
+			"footer":    map[string]string{"text": "agent " + alert.AgentID},
+			"timestamp": alert.Timestamp.Format(time.RFC3339),
+		}},
+	}
+}
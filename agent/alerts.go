@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"monitoring-agent/logging"
+)
+
+// alertRecoveryMargin is how many percentage points below its threshold a metric must
+// drop before a firing alert resolves, so a value hovering at the threshold doesn't flap
+const alertRecoveryMargin = 5.0
+
+// alertState tracks one threshold across collection cycles
+type alertState struct {
+	BreachedSince time.Time // Zero while the metric is below its threshold
+	Firing        bool
+}
+
+// Alert is the JSON payload POSTed to ALERT_WEBHOOK_URL when an alert fires or resolves
+type Alert struct {
+	Status    string    `json:"status"` // "firing" or "resolved"
+	AgentID   string    `json:"agent_id"`
+	Server    string    `json:"server"`
+	Hostname  string    `json:"hostname"`
+	Metric    string    `json:"metric"` // cpu, memory or disk
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Since     time.Time `json:"since"` // When the breach began
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// alertThresholds returns the configured threshold of each alertable metric, 0 when disabled
+func (a *Agent) alertThresholds() map[string]float64 {
+	return map[string]float64{
+		"cpu":    float64(a.config().AlertCPUPercent),
+		"memory": float64(a.config().AlertMemoryPercent),
+		"disk":   float64(a.config().AlertDiskPercent),
+	}
+}
+
+// evaluateAlerts compares this cycle's values against the thresholds. An alert fires once
+// when a breach has lasted ALERT_DURATION and resolves once when the value drops back
+// below the threshold by alertRecoveryMargin.
+func (a *Agent) evaluateAlerts(hostname string, values map[string]float64, now time.Time) {
+	if a.config().AlertWebhookURL == "" {
+		return
+	}
+	if a.alerts == nil {
+		a.alerts = make(map[string]*alertState)
+	}
+
+	thresholds := a.alertThresholds()
+	metrics := make([]string, 0, len(thresholds))
+	for metric := range thresholds {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	for _, metric := range metrics {
+		threshold := thresholds[metric]
+		value, ok := values[metric]
+		if threshold <= 0 || !ok {
+			delete(a.alerts, metric)
+			continue
+		}
+
+		state := a.alerts[metric]
+		if state == nil {
+			state = &alertState{}
+			a.alerts[metric] = state
+		}
+
+		alert := Alert{
+			AgentID:   a.config().AgentID,
+			Server:    a.config().ServerName,
+			Hostname:  hostname,
+			Metric:    metric,
+			Value:     value,
+			Threshold: threshold,
+			Timestamp: now,
+		}
+
+		switch {
+		case value >= threshold:
+			if state.BreachedSince.IsZero() {
+				state.BreachedSince = now
+			}
+			if !state.Firing && now.Sub(state.BreachedSince) >= a.config().AlertDuration {
+				state.Firing = true
+				alert.Status = "firing"
+				alert.Since = state.BreachedSince
+				go a.sendAlert(a.config().AlertWebhookURL, a.config().AlertWebhookType, alert)
+			}
+		case state.Firing:
+			if value < threshold-alertRecoveryMargin {
+				alert.Status = "resolved"
+				alert.Since = state.BreachedSince
+				*state = alertState{}
+				go a.sendAlert(a.config().AlertWebhookURL, a.config().AlertWebhookType, alert)
+			}
+		default:
+			// Dropped below before the breach lasted long enough to fire
+			state.BreachedSince = time.Time{}
+		}
+	}
+}
+
+// sendAlert POSTs an alert to the webhook, logging rather than retrying on failure.
+// The webhook is passed in since a reload may change the config while this runs.
+func (a *Agent) sendAlert(webhookURL, webhookType string, alert Alert) {
+	logging.Infof("Alert %s: %s at %.1f%% (threshold %.0f%%)", alert.Status, alert.Metric, alert.Value, alert.Threshold)
+
+	if err := a.postAlert(webhookURL, webhookType, alert); err != nil {
+		logging.Errorf("Failed to send %s alert for %s: %v", alert.Status, alert.Metric, err)
+		a.recordError("alerts", err)
+		return
+	}
+	a.recordPush("alerts")
+}
+
+// postAlert delivers one alert to the webhook in the shape its type expects
+func (a *Agent) postAlert(webhookURL, webhookType string, alert Alert) error {
+	payload, err := alertPayload(webhookType, alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(a.ctx, "POST", webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// getBootTime returns when the host booted, from /proc/stat btime or derived from uptime
+func (sc *SystemCollector) getBootTime() time.Time {
+	file, err := os.Open("/proc/stat")
+	if err == nil {
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 2 && fields[0] == "btime" {
+				if btime, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+					return time.Unix(btime, 0)
+				}
+			}
+		}
+	}
+
+	// Fallback: derive from uptime, truncated to absorb jitter between calls
+	uptime, err := sc.getUptime()
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Now().Add(-time.Duration(uptime) * time.Second).Truncate(time.Minute)
+}
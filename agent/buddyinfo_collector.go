@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BuddyInfo summarizes free page blocks per allocation order across all zones
+type BuddyInfo struct {
+	FreeBlocks           []int64 // Free blocks of 2^order pages, indexed by order
+	HighOrderFreePercent float64 // Share of free memory held in blocks of at least the high order
+}
+
+// getBuddyInfo parses /proc/buddyinfo; lines look like
+// "Node 0, zone   Normal   1582   3141    650 ..." with one column per order
+func (sc *SystemCollector) getBuddyInfo(highOrder int) (BuddyInfo, error) {
+	file, err := os.Open("/proc/buddyinfo")
+	if err != nil {
+		return BuddyInfo{}, err
+	}
+	defer file.Close()
+
+	info := BuddyInfo{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[2] != "zone" {
+			continue
+		}
+
+		for order, field := range fields[4:] {
+			blocks, err := strconv.ParseInt(field, 10, 64)
+			if err != nil {
+				continue
+			}
+			for len(info.FreeBlocks) <= order {
+				info.FreeBlocks = append(info.FreeBlocks, 0)
+			}
+			info.FreeBlocks[order] += blocks
+		}
+	}
+
+	// Weigh blocks by their size in pages so the percentage reflects free memory
+	var totalPages, highOrderPages int64
+	for order, blocks := range info.FreeBlocks {
+		pages := blocks << uint(order)
+		totalPages += pages
+		if order >= highOrder {
+			highOrderPages += pages
+		}
+	}
+	if totalPages > 0 {
+		info.HighOrderFreePercent = float64(highOrderPages) / float64(totalPages) * 100.0
+	}
+
+	return info, nil
+}
@@ -0,0 +1,26 @@
+//go:build darwin && !cgo
+
+package agent
+
+import (
+	"fmt"
+)
+
+// errNoHostStatistics is returned when the agent was built without cgo, since
+// host_statistics is only reachable through the Mach APIs
+var errNoHostStatistics = fmt.Errorf("host_statistics requires building with CGO_ENABLED=1")
+
+// getCPUStats is unavailable without cgo
+func (sc *SystemCollector) getCPUStats() (CPUStats, error) {
+	return CPUStats{}, errNoHostStatistics
+}
+
+// getPerCoreCPUStats is unavailable without cgo
+func (sc *SystemCollector) getPerCoreCPUStats() (map[int]CPUStats, error) {
+	return nil, errNoHostStatistics
+}
+
+// getMemInfo is unavailable without cgo
+func (sc *SystemCollector) getMemInfo() (map[string]int64, error) {
+	return nil, errNoHostStatistics
+}
@@ -0,0 +1,47 @@
+//go:build !linux && !windows && !darwin && !freebsd
+
+package agent
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// errUnsupportedPlatform is returned by data sources that have no implementation on this OS,
+// so callers fall back to the same placeholders they use when /proc is unreadable
+var errUnsupportedPlatform = fmt.Errorf("not supported on %s", runtime.GOOS)
+
+// getCPUStats is not implemented on this platform
+func (sc *SystemCollector) getCPUStats() (CPUStats, error) {
+	return CPUStats{}, errUnsupportedPlatform
+}
+
+// getPerCoreCPUStats is not implemented on this platform
+func (sc *SystemCollector) getPerCoreCPUStats() (map[int]CPUStats, error) {
+	return nil, errUnsupportedPlatform
+}
+
+// getMemInfo is not implemented on this platform
+func (sc *SystemCollector) getMemInfo() (map[string]int64, error) {
+	return nil, errUnsupportedPlatform
+}
+
+// getOSInfo is not implemented on this platform
+func (sc *SystemCollector) getOSInfo() map[string]string {
+	return nil
+}
+
+// getKernelVersion is not implemented on this platform
+func (sc *SystemCollector) getKernelVersion() string {
+	return ""
+}
+
+// getCPUModel is not implemented on this platform
+func (sc *SystemCollector) getCPUModel() string {
+	return ""
+}
+
+// getUptime is not implemented on this platform
+func (sc *SystemCollector) getUptime() (int64, error) {
+	return 0, errUnsupportedPlatform
+}
@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"monitoring-agent/logging"
+)
+
+// maxCollectorStateAge is how old a saved baseline may be and still be restored. Rates
+// over a longer gap would be averaged over the whole time the agent was down.
+const maxCollectorStateAge = 10 * time.Minute
+
+// collectorState is the CPU and network baseline persisted in STATE_DIR/state.json, so the
+// first usage and speed readings after a restart are measured against the last ones
+// before it instead of a cold start
+type collectorState struct {
+	SavedAt       time.Time               `json:"saved_at"`
+	BootTime      time.Time               `json:"boot_time"` // Counters restart on reboot, making older baselines useless
+	CPU           CPUStats                `json:"cpu"`
+	CPUTime       time.Time               `json:"cpu_time"`
+	Network       NetworkStats            `json:"network"`
+	NetworkTime   time.Time               `json:"network_time"`
+	Interfaces    map[string]NetworkStats `json:"interfaces,omitempty"`
+	InterfaceTime time.Time               `json:"interface_time"`
+}
+
+// exportState snapshots the collector's CPU and network baselines
+func (sc *SystemCollector) exportState() collectorState {
+	bootTime := sc.getBootTime()
+
+	sc.deltaMu.Lock()
+	defer sc.deltaMu.Unlock()
+
+	return collectorState{
+		SavedAt:       time.Now(),
+		BootTime:      bootTime,
+		CPU:           sc.lastCPUStats,
+		CPUTime:       sc.lastCPUTime,
+		Network:       sc.lastNetworkStats,
+		NetworkTime:   sc.lastNetworkTime,
+		Interfaces:    sc.lastInterfaceStats,
+		InterfaceTime: sc.lastInterfaceTime,
+	}
+}
+
+// restoreState installs saved baselines. Call it before the collector takes its first samples.
+func (sc *SystemCollector) restoreState(state collectorState) {
+	sc.deltaMu.Lock()
+	defer sc.deltaMu.Unlock()
+
+	if state.CPU.Total > 0 {
+		sc.lastCPUStats = state.CPU
+		sc.lastCPUTime = state.CPUTime
+		sc.initialized = true
+	}
+	if !state.NetworkTime.IsZero() {
+		sc.lastNetworkStats = state.Network
+		sc.lastNetworkTime = state.NetworkTime
+	}
+	if len(state.Interfaces) > 0 {
+		sc.lastInterfaceStats = state.Interfaces
+		sc.lastInterfaceTime = state.InterfaceTime
+	}
+}
+
+// restoreCollectorState loads state.json when it was saved recently during the current boot
+func (a *Agent) restoreCollectorState() {
+	statePath := filepath.Join(a.config().StateDir, "state.json")
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		return
+	}
+
+	var state collectorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		logging.Warnf("Ignoring unreadable collector state %s: %v", statePath, err)
+		return
+	}
+
+	// Boot times derived from uptime jitter by a second or so between reads
+	bootTime := a.collector.GetBootTime()
+	if diff := bootTime.Sub(state.BootTime); diff > 2*time.Second || diff < -2*time.Second {
+		logging.Debugf("Collector state in %s is from a previous boot, ignoring it", statePath)
+		return
+	}
+	if age := time.Since(state.SavedAt); age > maxCollectorStateAge || age < 0 {
+		logging.Debugf("Collector state in %s is %v old, ignoring it", statePath, age.Round(time.Second))
+		return
+	}
+
+	a.collector.restoreState(state)
+	logging.Infof("Restored CPU and network baselines saved %v ago", time.Since(state.SavedAt).Round(time.Second))
+}
+
+// saveCollectorState writes the current baselines to state.json for the next start
+func (a *Agent) saveCollectorState() {
+	if a.dryRun {
+		return
+	}
+
+	data, err := json.Marshal(a.collector.exportState())
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(a.config().StateDir, 0755); err != nil {
+		logging.Warnf("Could not create state directory %s: %v", a.config().StateDir, err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(a.config().StateDir, "state.json"), data, 0644); err != nil {
+		logging.Warnf("Could not persist collector state: %v", err)
+	}
+}
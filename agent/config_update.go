@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitoring-agent/logging"
+	pbClient "monitoring-agent/pocketbase"
+)
+
+// runtimeUpdate carries config_update settings that the collection loop applies between
+// cycles, so they take effect alongside interval changes from the server record
+type runtimeUpdate struct {
+	checkInterval time.Duration // Zero leaves CHECK_INTERVAL unchanged
+	logLevel      string        // Empty leaves LOG_LEVEL unchanged
+}
+
+// updateConfiguration applies the supported config_update parameters: check_interval,
+// command_check_interval, log_level and docker. The outcome of every key, including
+// unsupported ones, is reported in the command result.
+func (a *Agent) updateConfiguration(parameters map[string]string) (*pbClient.CommandResult, error) {
+	logging.Infof("Configuration update requested with parameters: %v", parameters)
+
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var applied, invalid, unsupported []string
+	var update runtimeUpdate
+	for _, key := range keys {
+		value := parameters[key]
+
+		var err error
+		switch key {
+		case "check_interval":
+			var interval time.Duration
+			if interval, err = parseDurationParam(value); err == nil {
+				// The server record's check_interval wins over CHECK_INTERVAL, so change both
+				err = a.updateServerFields(map[string]interface{}{"check_interval": int(interval.Seconds())})
+				update.checkInterval = interval
+			}
+		case "command_check_interval":
+			var interval time.Duration
+			if interval, err = parseDurationParam(value); err == nil {
+				// Runs on the command goroutine, which picks it up after this check
+				a.commandInterval = interval
+			}
+		case "log_level":
+			level, ok := logging.ParseLevel(value)
+			if !ok {
+				err = fmt.Errorf("must be debug, info, warn or error")
+				break
+			}
+			logging.SetLevel(level)
+			update.logLevel = strings.ToLower(value)
+		case "docker":
+			var enabled bool
+			if enabled, err = strconv.ParseBool(value); err == nil {
+				// Docker monitoring follows the server record's docker flag
+				err = a.updateServerFields(map[string]interface{}{"docker": enabled})
+			}
+		default:
+			unsupported = append(unsupported, key)
+			continue
+		}
+
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s (%v)", key, err))
+			continue
+		}
+		applied = append(applied, key+"="+value)
+	}
+
+	if update != (runtimeUpdate{}) {
+		select {
+		case a.configUpdates <- update:
+		default:
+			logging.Warnf("Configuration update already pending, check_interval and log_level apply with the next one")
+		}
+	}
+
+	var summary []string
+	if len(applied) > 0 {
+		summary = append(summary, "applied: "+strings.Join(applied, ", "))
+	}
+	if len(invalid) > 0 {
+		summary = append(summary, "invalid: "+strings.Join(invalid, ", "))
+	}
+	if len(unsupported) > 0 {
+		summary = append(summary, "unsupported: "+strings.Join(unsupported, ", "))
+	}
+	if len(summary) == 0 {
+		summary = append(summary, "no parameters given")
+	}
+	result := strings.Join(summary, "; ")
+	logging.Infof("Configuration update %s", result)
+
+	if err := a.updateAgentStatus("running", "Configuration updated via remote command"); err != nil {
+		logging.Warnf("Failed to update agent status: %v", err)
+	}
+	return &pbClient.CommandResult{Result: result}, nil
+}
+
+// applyRuntimeUpdate applies a config_update on the collection goroutine, swapping in an
+// updated copy of the configuration like applyReload
+func (a *Agent) applyRuntimeUpdate(update runtimeUpdate) {
+	updated := *a.config()
+	if update.checkInterval > 0 {
+		updated.CheckInterval = update.checkInterval
+	}
+	if update.logLevel != "" {
+		updated.LogLevel = update.logLevel
+	}
+	a.liveConfig.Store(&updated)
+}
+
+// updateServerFields patches this agent's server record in PocketBase
+func (a *Agent) updateServerFields(fields map[string]interface{}) error {
+	if a.pocketBase == nil {
+		return fmt.Errorf("requires PocketBase")
+	}
+
+	// Look the record up rather than reading serverRecord, which the collection loop replaces
+	server, err := a.pocketBase.GetServerByID(a.ctx, a.config().AgentID)
+	if err != nil {
+		return err
+	}
+	return a.pocketBase.UpdateServerFields(a.ctx, server.ID, fields)
+}
+
+// parseDurationParam accepts a duration ("30s") or plain seconds, which must be positive
+func parseDurationParam(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.ParseFloat(value, 64)
+		if convErr != nil {
+			return 0, fmt.Errorf("must be a duration or seconds (got %q)", value)
+		}
+		duration = time.Duration(seconds * float64(time.Second))
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("must be positive (got %q)", value)
+	}
+	return duration, nil
+}
@@ -0,0 +1,13 @@
+package agent
+
+// getConntrackUsage reads netfilter conntrack table usage, returning zeros when conntrack isn't loaded
+func (sc *SystemCollector) getConntrackUsage() (count int64, max int64, percentage float64) {
+	count = readIntFile("/proc/sys/net/netfilter/nf_conntrack_count")
+	max = readIntFile("/proc/sys/net/netfilter/nf_conntrack_max")
+
+	if max > 0 {
+		percentage = float64(count) / float64(max) * 100.0
+	}
+
+	return count, max, percentage
+}
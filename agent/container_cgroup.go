@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BlkioThrottle describes block I/O limits, I/O pressure and traffic for a container's cgroup.
+// Neither cgroup version counts time spent throttled by those limits, so stalls are reported
+// as what they are: I/O pressure, whatever its cause.
+type BlkioThrottle struct {
+	Limited            bool  // Any read/write bps or iops limit is configured
+	IOPressureFullUsec int64 // Cumulative time all tasks stalled on I/O (cgroup v2 io.pressure "full")
+	Bytes              int64 // Bytes read and written through the cgroup
+}
+
+// findContainerCgroup locates the cgroup directory of a container from its (short) ID,
+// covering systemd and cgroupfs drivers for Docker and Podman. For cgroup v1 the blkio
+// hierarchy is searched. Returns an empty path when the cgroup can't be found.
+func findContainerCgroup(containerID string) (path string, v2 bool) {
+	if containerID == "" {
+		return "", false
+	}
+
+	v2 = pathExists("/sys/fs/cgroup/cgroup.controllers")
+	root := "/sys/fs/cgroup"
+	if !v2 {
+		root = "/sys/fs/cgroup/blkio"
+	}
+
+	patterns := []string{
+		filepath.Join(root, "system.slice", "docker-"+containerID+"*.scope"),
+		filepath.Join(root, "docker", containerID+"*"),
+		filepath.Join(root, "machine.slice", "libpod-"+containerID+"*.scope"),
+		filepath.Join(root, "user.slice", "*", "*", "*", "libpod-"+containerID+"*.scope"),
+		filepath.Join(root, "libpod_parent", "libpod-"+containerID+"*"),
+	}
+
+	for _, pattern := range patterns {
+		if matches, err := filepath.Glob(pattern); err == nil && len(matches) > 0 {
+			return matches[0], v2
+		}
+	}
+
+	return "", v2
+}
+
+// getContainerMemoryMax reads memory.max from a container's cgroup v2 directory. ok is false
+// on cgroup v1 hosts or when the cgroup can't be found, and limit is 0 when it reads "max".
+func getContainerMemoryMax(containerID string) (limit int64, ok bool) {
+	dir, v2 := findContainerCgroup(containerID)
+	if dir == "" || !v2 {
+		return 0, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "memory.max"))
+	if err != nil {
+		return 0, false
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "max" {
+		return 0, true
+	}
+	limit, err = strconv.ParseInt(value, 10, 64)
+	return limit, err == nil
+}
+
+// getContainerBlkioThrottle reads block I/O limits and throttling from the container's cgroup
+func (sc *SystemCollector) getContainerBlkioThrottle(containerID string) (BlkioThrottle, bool) {
+	dir, v2 := findContainerCgroup(containerID)
+	if dir == "" {
+		return BlkioThrottle{}, false
+	}
+
+	if v2 {
+		return readBlkioThrottleV2(dir), true
+	}
+	return readBlkioThrottleV1(dir), true
+}
+
+// readBlkioThrottleV2 reads io.max, io.stat and io.pressure from a cgroup v2 directory
+func readBlkioThrottleV2(dir string) BlkioThrottle {
+	throttle := BlkioThrottle{}
+
+	// io.max lines look like "8:0 rbps=1048576 wbps=max riops=max wiops=max"
+	if data, err := os.ReadFile(filepath.Join(dir, "io.max")); err == nil {
+		for _, field := range strings.Fields(string(data)) {
+			if parts := strings.SplitN(field, "=", 2); len(parts) == 2 && parts[1] != "max" {
+				throttle.Limited = true
+			}
+		}
+	}
+
+	// io.stat lines look like "8:0 rbytes=1024 wbytes=2048 rios=1 wios=2 dbytes=0 dios=0"
+	if data, err := os.ReadFile(filepath.Join(dir, "io.stat")); err == nil {
+		for _, field := range strings.Fields(string(data)) {
+			parts := strings.SplitN(field, "=", 2)
+			if len(parts) != 2 || (parts[0] != "rbytes" && parts[0] != "wbytes") {
+				continue
+			}
+			if value, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+				throttle.Bytes += value
+			}
+		}
+	}
+
+	// io.pressure: "full avg10=0.00 avg60=0.00 avg300=0.00 total=12345"
+	if data, err := os.ReadFile(filepath.Join(dir, "io.pressure")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if !strings.HasPrefix(line, "full ") {
+				continue
+			}
+			for _, field := range strings.Fields(line) {
+				if value, ok := strings.CutPrefix(field, "total="); ok {
+					throttle.IOPressureFullUsec, _ = strconv.ParseInt(value, 10, 64)
+				}
+			}
+		}
+	}
+
+	return throttle
+}
+
+// readBlkioThrottleV1 reads the blkio.throttle.* files from a cgroup v1 blkio directory.
+// v1 doesn't account stall time, so IOPressureFullUsec stays zero.
+func readBlkioThrottleV1(dir string) BlkioThrottle {
+	throttle := BlkioThrottle{}
+
+	for _, name := range []string{"read_bps_device", "write_bps_device", "read_iops_device", "write_iops_device"} {
+		if data, err := os.ReadFile(filepath.Join(dir, "blkio.throttle."+name)); err == nil && strings.TrimSpace(string(data)) != "" {
+			throttle.Limited = true
+		}
+	}
+
+	// io_service_bytes ends with a "Total <bytes>" line
+	if data, err := os.ReadFile(filepath.Join(dir, "blkio.throttle.io_service_bytes")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "Total" {
+				throttle.Bytes, _ = strconv.ParseInt(fields[1], 10, 64)
+			}
+		}
+	}
+
+	return throttle
+}
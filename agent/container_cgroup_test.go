@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeCgroupFiles creates a fake cgroup directory holding files
+func writeCgroupFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadBlkioThrottleV2(t *testing.T) {
+	dir := writeCgroupFiles(t, map[string]string{
+		"io.max":      "8:0 rbps=1048576 wbps=max riops=max wiops=max\n",
+		"io.stat":     "8:0 rbytes=1024 wbytes=2048 rios=1 wios=2 dbytes=0 dios=0\n8:16 rbytes=100 wbytes=0 rios=1 wios=0 dbytes=0 dios=0\n",
+		"io.pressure": "some avg10=0.00 avg60=0.00 avg300=0.00 total=99999\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=12345\n",
+	})
+
+	got := readBlkioThrottleV2(dir)
+	want := BlkioThrottle{Limited: true, IOPressureFullUsec: 12345, Bytes: 3172}
+	if got != want {
+		t.Errorf("readBlkioThrottleV2() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadBlkioThrottleV2WithoutLimits(t *testing.T) {
+	dir := writeCgroupFiles(t, map[string]string{
+		"io.max": "",
+	})
+
+	if got := readBlkioThrottleV2(dir); got != (BlkioThrottle{}) {
+		t.Errorf("readBlkioThrottleV2() = %+v, want no limits, pressure or traffic", got)
+	}
+}
+
+func TestReadBlkioThrottleV1(t *testing.T) {
+	dir := writeCgroupFiles(t, map[string]string{
+		"blkio.throttle.read_bps_device":  "",
+		"blkio.throttle.write_bps_device": "8:0 1048576\n",
+		"blkio.throttle.io_service_bytes": "8:0 Read 4096\n8:0 Write 8192\n8:0 Total 12288\nTotal 12288\n",
+	})
+
+	got := readBlkioThrottleV1(dir)
+	want := BlkioThrottle{Limited: true, Bytes: 12288}
+	if got != want {
+		t.Errorf("readBlkioThrottleV1() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// containerRuntime describes how to reach a docker-compatible container engine
+type containerRuntime struct {
+	Name          string   // "docker" or "podman"
+	Label         string   // Name used in OS templates, e.g. "Docker"
+	Binaries      []string // CLI candidates, tried in order
+	Sockets       []string // Engine API socket candidates, tried in order
+	VersionFormat string   // Go template printing the engine version
+	RequireSocket bool     // Whether the CLI is only usable with the socket present
+}
+
+// dockerRuntime returns the Docker engine description
+func dockerRuntime() containerRuntime {
+	return containerRuntime{
+		Name:  "docker",
+		Label: "Docker",
+		Binaries: []string{
+			"/usr/bin/docker",
+			"/usr/local/bin/docker",
+			"/bin/docker",
+			"/usr/sbin/docker",
+			"docker", // fallback to PATH
+		},
+		Sockets: []string{
+			"/var/run/docker.sock",
+			"/run/docker.sock",
+		},
+		VersionFormat: "{{.Server.Version}}",
+		RequireSocket: true,
+	}
+}
+
+// podmanRuntime returns the Podman engine description, including the rootless socket
+func podmanRuntime() containerRuntime {
+	var sockets []string
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		sockets = append(sockets, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	sockets = append(sockets, "/run/podman/podman.sock")
+
+	return containerRuntime{
+		Name:  "podman",
+		Label: "Podman",
+		Binaries: []string{
+			"/usr/bin/podman",
+			"/usr/local/bin/podman",
+			"/bin/podman",
+			"podman", // fallback to PATH
+		},
+		Sockets:       sockets,
+		VersionFormat: "{{.Client.Version}}",
+		// Rootless podman works without a running API service
+		RequireSocket: false,
+	}
+}
+
+// runtimeCandidates returns the runtimes to probe for a CONTAINER_RUNTIME setting
+func runtimeCandidates(setting string) []containerRuntime {
+	switch setting {
+	case "docker":
+		return []containerRuntime{dockerRuntime()}
+	case "podman":
+		return []containerRuntime{podmanRuntime()}
+	default:
+		return []containerRuntime{dockerRuntime(), podmanRuntime()}
+	}
+}
+
+// hasSocket reports whether any of the runtime's sockets exist
+func (rt containerRuntime) hasSocket() bool {
+	for _, socketPath := range rt.Sockets {
+		if _, err := os.Stat(socketPath); err == nil {
+			return true
+		}
+	}
+	return false
+}
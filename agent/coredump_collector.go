@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CoreDumpInfo summarizes core dumps found in the known crash directories
+type CoreDumpInfo struct {
+	Count            int
+	LatestTime       time.Time
+	LatestExecutable string // Crashing executable of the newest dump, best effort from the file name
+}
+
+// coreDumpDirs returns the directories where core dumps are written, including the
+// directory of an absolute core_pattern. Piped patterns hand dumps to apport or
+// systemd-coredump, which write to the fixed directories.
+func coreDumpDirs() []string {
+	dirs := []string{"/var/crash", "/var/lib/systemd/coredump"}
+
+	if data, err := os.ReadFile("/proc/sys/kernel/core_pattern"); err == nil {
+		pattern := strings.TrimSpace(string(data))
+		if strings.HasPrefix(pattern, "/") {
+			dir := filepath.Dir(pattern)
+			if dir != "/var/crash" && dir != "/var/lib/systemd/coredump" {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+
+	return dirs
+}
+
+// getCoreDumps counts core dump files and identifies the most recent crashing executable
+func (sc *SystemCollector) getCoreDumps() CoreDumpInfo {
+	info := CoreDumpInfo{}
+
+	for _, dir := range coreDumpDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || !isCoreDumpFile(entry.Name()) {
+				continue
+			}
+			fileInfo, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			info.Count++
+			if fileInfo.ModTime().After(info.LatestTime) {
+				info.LatestTime = fileInfo.ModTime()
+				info.LatestExecutable = coreDumpExecutable(entry.Name())
+			}
+		}
+	}
+
+	return info
+}
+
+// isCoreDumpFile filters out metadata files such as apport's .upload markers
+func isCoreDumpFile(name string) bool {
+	return strings.HasPrefix(name, "core") || strings.HasSuffix(name, ".crash")
+}
+
+// coreDumpExecutable extracts the executable name from known dump file naming schemes
+func coreDumpExecutable(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".crash"):
+		// apport: _usr_bin_foo.1000.crash
+		base := strings.SplitN(name, ".", 2)[0]
+		return strings.ReplaceAll(base, "_", "/")
+	case strings.HasPrefix(name, "core."):
+		// systemd-coredump: core.foo.1000.<boot id>.1234.1700000000000000.zst, core_pattern: core.foo.1234
+		parts := strings.Split(name, ".")
+		if len(parts) > 2 {
+			return parts[1]
+		}
+	}
+	return name
+}
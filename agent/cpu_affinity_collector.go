@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// getOnlineCPUCount returns the number of online CPUs on the host, ignoring affinity.
+// runtime.NumCPU honours the agent's own affinity mask, so it is only the fallback.
+func (sc *SystemCollector) getOnlineCPUCount() int {
+	if data, err := os.ReadFile("/sys/devices/system/cpu/online"); err == nil {
+		if count := countCPUList(strings.TrimSpace(string(data))); count > 0 {
+			return count
+		}
+	}
+	return runtime.NumCPU()
+}
+
+// getEffectiveCPUCount returns how many CPUs the agent may actually run on, taking the
+// smaller of the scheduler affinity mask and the cpuset cgroup's effective CPUs
+func (sc *SystemCollector) getEffectiveCPUCount() int {
+	effective := sc.getOnlineCPUCount()
+
+	// Cpus_allowed_list mirrors sched_getaffinity for the process
+	if file, err := os.Open("/proc/self/status"); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if value, ok := strings.CutPrefix(scanner.Text(), "Cpus_allowed_list:"); ok {
+				if count := countCPUList(strings.TrimSpace(value)); count > 0 && count < effective {
+					effective = count
+				}
+				break
+			}
+		}
+		file.Close()
+	}
+
+	if path := cpusetEffectivePath(); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			if count := countCPUList(strings.TrimSpace(string(data))); count > 0 && count < effective {
+				effective = count
+			}
+		}
+	}
+
+	return effective
+}
+
+// cpusetEffectivePath finds the effective CPU list of the agent's cpuset cgroup,
+// for either cgroup v2 or the v1 cpuset hierarchy
+func cpusetEffectivePath() string {
+	file, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Lines look like "0::/system.slice/agent.service" or "3:cpuset:/docker/abc"
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		if parts[0] == "0" && parts[1] == "" {
+			path := filepath.Join("/sys/fs/cgroup", parts[2], "cpuset.cpus.effective")
+			if pathExists(path) {
+				return path
+			}
+			continue
+		}
+
+		for _, controller := range strings.Split(parts[1], ",") {
+			if controller == "cpuset" {
+				path := filepath.Join("/sys/fs/cgroup/cpuset", parts[2], "cpuset.effective_cpus")
+				if pathExists(path) {
+					return path
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// countCPUList counts the CPUs in a kernel CPU list such as "0-3,8,10-11"
+func countCPUList(list string) int {
+	count := 0
+	for _, part := range strings.Split(list, ",") {
+		if part == "" {
+			continue
+		}
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return 0
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil || last < first {
+				return 0
+			}
+		}
+		count += last - first + 1
+	}
+	return count
+}
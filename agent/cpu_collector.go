@@ -2,11 +2,8 @@
 package agent
 
 import (
-	"bufio"
-	"fmt"
-	"os"
-	"strconv"
-	"strings"
+	"math"
+	"sort"
 	"time"
 )
 
@@ -38,49 +35,61 @@ func (sc *SystemCollector) getCPUUsage() float64 {
 	avgUsage := totalUsage / float64(validSamples)
 	
 	// Round to 2 decimal places
-	return float64(int(avgUsage*100)) / 100
+	return math.Round(avgUsage*100) / 100
 }
 
 // getSingleCPUUsage gets a single CPU usage sample
 func (sc *SystemCollector) getSingleCPUUsage() float64 {
+	prev, curr, ok := sc.sampleCPUStats()
+	if !ok {
+		return 0.0
+	}
+	return sc.calculateCPUPercentage(prev, curr)
+}
+
+// sampleCPUStats returns the previous and current CPU counters to measure usage between,
+// replacing the baseline. ok is false when the counters can't be read or too little time
+// has passed since the last sample.
+func (sc *SystemCollector) sampleCPUStats() (prev, curr CPUStats, ok bool) {
 	currentStats, err := sc.getCPUStats()
 	if err != nil {
-		return 0.0
+		return CPUStats{}, CPUStats{}, false
 	}
 
 	now := time.Now()
 
-	// If this is the first call, initialize and wait for next sample
-	if !sc.initialized || sc.lastCPUStats.Total == 0 {
+	// The baseline may be restored from the state file, so it is read and replaced under deltaMu
+	sc.deltaMu.Lock()
+	first := !sc.initialized || sc.lastCPUStats.Total == 0
+	lastStats, lastTime := sc.lastCPUStats, sc.lastCPUTime
+	if first || now.Sub(lastTime) >= 50*time.Millisecond {
 		sc.lastCPUStats = currentStats
 		sc.lastCPUTime = now
 		sc.initialized = true
-		
+	}
+	sc.deltaMu.Unlock()
+
+	// If this is the first call, initialize and wait for next sample
+	if first {
 		// Wait a bit and take another sample
 		time.Sleep(200 * time.Millisecond)
 		
 		newStats, err := sc.getCPUStats()
 		if err != nil {
-			return 0.0
+			return CPUStats{}, CPUStats{}, false
 		}
 		
-		return sc.calculateCPUPercentage(currentStats, newStats)
+		return currentStats, newStats, true
 	}
 
 	// Calculate time difference
-	timeDiff := now.Sub(sc.lastCPUTime)
+	timeDiff := now.Sub(lastTime)
 	if timeDiff < 50*time.Millisecond {
 		// Too little time has passed, return previous calculation
-		return 0.0
+		return CPUStats{}, CPUStats{}, false
 	}
 
-	cpuUsage := sc.calculateCPUPercentage(sc.lastCPUStats, currentStats)
-	
-	// Update last stats and time
-	sc.lastCPUStats = currentStats
-	sc.lastCPUTime = now
-
-	return cpuUsage
+	return lastStats, currentStats, true
 }
 
 // calculateCPUPercentage calculates CPU usage percentage between two CPU stat snapshots
@@ -115,47 +124,107 @@ func (sc *SystemCollector) calculateCPUPercentage(prev, curr CPUStats) float64 {
 	return cpuUsage
 }
 
-// getCPUStats reads CPU stats from /proc/stat with better error handling
-func (sc *SystemCollector) getCPUStats() (CPUStats, error) {
-	file, err := os.Open("/proc/stat")
-	if err != nil {
-		return CPUStats{}, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "cpu ") {
-			fields := strings.Fields(line)
-			if len(fields) < 8 {
-				continue
-			}
-
-			stats := CPUStats{}
-			stats.User, _ = strconv.ParseUint(fields[1], 10, 64)
-			stats.Nice, _ = strconv.ParseUint(fields[2], 10, 64)
-			stats.System, _ = strconv.ParseUint(fields[3], 10, 64)
-			stats.Idle, _ = strconv.ParseUint(fields[4], 10, 64)
-			stats.IOWait, _ = strconv.ParseUint(fields[5], 10, 64)
-			stats.IRQ, _ = strconv.ParseUint(fields[6], 10, 64)
-			stats.SoftIRQ, _ = strconv.ParseUint(fields[7], 10, 64)
-			if len(fields) > 8 {
-				stats.Steal, _ = strconv.ParseUint(fields[8], 10, 64)
-			}
-			if len(fields) > 9 {
-				stats.Guest, _ = strconv.ParseUint(fields[9], 10, 64)
-			}
-			
-			// Calculate total
-			stats.Total = stats.User + stats.Nice + stats.System + stats.Idle + 
-						  stats.IOWait + stats.IRQ + stats.SoftIRQ + stats.Steal + stats.Guest
-
-			return stats, nil
+// CPUBreakdown splits CPU time into the states the kernel accounts it to, in percent
+type CPUBreakdown struct {
+	User    float64 // Including niced processes
+	System  float64
+	IOWait  float64 // Idle with I/O outstanding
+	IRQ     float64
+	SoftIRQ float64
+	Steal   float64 // Taken by the hypervisor for other VMs
+	Idle    float64
+}
+
+// cpuTimeShare returns the percentage of CPU time between two snapshots spent in the state
+// that component selects, e.g. steal or iowait
+func cpuTimeShare(prev, curr CPUStats, component func(CPUStats) uint64) float64 {
+	// Guest time is already counted in user time, so the parsed Total can't be used here
+	total := func(s CPUStats) uint64 {
+		return s.User + s.Nice + s.System + s.Idle + s.IOWait + s.IRQ + s.SoftIRQ + s.Steal
+	}
+	if total(curr) <= total(prev) || component(curr) < component(prev) {
+		return 0.0
+	}
+
+	share := float64(component(curr)-component(prev)) / float64(total(curr)-total(prev)) * 100.0
+	if share > 100 {
+		return 100.0
+	}
+	return share
+}
+
+// getCPUBreakdown samples the CPU counters the same way as getSingleCPUUsage and splits the
+// time since the previous sample by state
+func (sc *SystemCollector) getCPUBreakdown() CPUBreakdown {
+	prev, curr, ok := sc.sampleCPUStats()
+	if !ok {
+		// Usage was probably sampled just before, leave a measurable gap
+		time.Sleep(100 * time.Millisecond)
+		if prev, curr, ok = sc.sampleCPUStats(); !ok {
+			return CPUBreakdown{}
+		}
+	}
+	return calculateCPUBreakdown(prev, curr)
+}
+
+// calculateCPUBreakdown computes each state's share between two snapshots, rounded to 2 decimals
+func calculateCPUBreakdown(prev, curr CPUStats) CPUBreakdown {
+	share := func(component func(CPUStats) uint64) float64 {
+		return math.Round(cpuTimeShare(prev, curr, component)*100) / 100
+	}
+	return CPUBreakdown{
+		User:    share(func(s CPUStats) uint64 { return s.User + s.Nice }),
+		System:  share(func(s CPUStats) uint64 { return s.System }),
+		IOWait:  share(func(s CPUStats) uint64 { return s.IOWait }),
+		IRQ:     share(func(s CPUStats) uint64 { return s.IRQ }),
+		SoftIRQ: share(func(s CPUStats) uint64 { return s.SoftIRQ }),
+		Steal:   share(func(s CPUStats) uint64 { return s.Steal }),
+		Idle:    share(func(s CPUStats) uint64 { return s.Idle }),
+	}
+}
+
+// getPerCoreCPUUsage returns the usage percentage of each core, ordered by core number
+func (sc *SystemCollector) getPerCoreCPUUsage() []float64 {
+	currentStats, err := sc.getPerCoreCPUStats()
+	if err != nil || len(currentStats) == 0 {
+		return []float64{}
+	}
+
+	// If this is the first call, take a baseline and sample again shortly after
+	if sc.lastPerCoreStats == nil {
+		sc.lastPerCoreStats = currentStats
+		
+		time.Sleep(200 * time.Millisecond)
+		
+		currentStats, err = sc.getPerCoreCPUStats()
+		if err != nil {
+			return []float64{}
 		}
 	}
 
-	return CPUStats{}, fmt.Errorf("cpu stats not found")
+	cores := make([]int, 0, len(currentStats))
+	for core := range currentStats {
+		cores = append(cores, core)
+	}
+	sort.Ints(cores)
+
+	usage := make([]float64, 0, len(cores))
+	for _, core := range cores {
+		prev, ok := sc.lastPerCoreStats[core]
+		if !ok {
+			// Core was hot-plugged since the last sample, no baseline yet
+			usage = append(usage, 0.0)
+			continue
+		}
+		
+		coreUsage := sc.calculateCPUPercentage(prev, currentStats[core])
+		usage = append(usage, math.Round(coreUsage*100)/100)
+	}
+
+	// Replace the whole snapshot so cores that went offline are dropped
+	sc.lastPerCoreStats = currentStats
+
+	return usage
 }
 
 // getTotalCPUTime calculates total CPU time
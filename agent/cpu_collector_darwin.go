@@ -0,0 +1,66 @@
+//go:build darwin && cgo
+
+package agent
+
+/*
+#include <mach/mach.h>
+#include <mach/mach_host.h>
+#include <mach/processor_info.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// machHost is the host port for host_statistics and friends. Every mach_host_self call adds
+// a send right to it, so take one for the process instead of leaking one per call.
+var machHost = C.host_t(C.mach_host_self())
+
+// cpuStatsFromTicks converts Mach CPU tick counters into CPUStats
+func cpuStatsFromTicks(ticks [C.CPU_STATE_MAX]C.natural_t) CPUStats {
+	stats := CPUStats{
+		User:   uint64(ticks[C.CPU_STATE_USER]),
+		Nice:   uint64(ticks[C.CPU_STATE_NICE]),
+		System: uint64(ticks[C.CPU_STATE_SYSTEM]),
+		Idle:   uint64(ticks[C.CPU_STATE_IDLE]),
+	}
+	stats.Total = stats.User + stats.Nice + stats.System + stats.Idle
+	return stats
+}
+
+// getCPUStats reads system-wide CPU ticks from host_statistics
+func (sc *SystemCollector) getCPUStats() (CPUStats, error) {
+	var info C.host_cpu_load_info_data_t
+	count := C.mach_msg_type_number_t(C.HOST_CPU_LOAD_INFO_COUNT)
+
+	ret := C.host_statistics(machHost, C.HOST_CPU_LOAD_INFO, C.host_info_t(unsafe.Pointer(&info)), &count)
+	if ret != C.KERN_SUCCESS {
+		return CPUStats{}, fmt.Errorf("host_statistics failed with %d", ret)
+	}
+
+	return cpuStatsFromTicks(info.cpu_ticks), nil
+}
+
+// getPerCoreCPUStats reads the ticks of each CPU from host_processor_info
+func (sc *SystemCollector) getPerCoreCPUStats() (map[int]CPUStats, error) {
+	var cpuCount C.natural_t
+	var infoArray C.processor_info_array_t
+	var infoCount C.mach_msg_type_number_t
+
+	ret := C.host_processor_info(machHost, C.PROCESSOR_CPU_LOAD_INFO, &cpuCount, &infoArray, &infoCount)
+	if ret != C.KERN_SUCCESS {
+		return nil, fmt.Errorf("host_processor_info failed with %d", ret)
+	}
+	// The kernel allocates the array in our address space
+	defer C.vm_deallocate(C.mach_task_self_, C.vm_address_t(uintptr(unsafe.Pointer(infoArray))), C.vm_size_t(uintptr(infoCount)*unsafe.Sizeof(C.integer_t(0))))
+
+	loads := unsafe.Slice((*C.processor_cpu_load_info_data_t)(unsafe.Pointer(infoArray)), int(cpuCount))
+	perCore := make(map[int]CPUStats, len(loads))
+	for core, load := range loads {
+		perCore[core] = cpuStatsFromTicks(load.cpu_ticks)
+	}
+
+	return perCore, nil
+}
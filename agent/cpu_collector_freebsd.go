@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// cpuStatesPerCPU is CPUSTATES: user, nice, sys, intr and idle ticks
+const cpuStatesPerCPU = 5
+
+// sysctlLongs reads a sysctl holding an array of C longs, such as kern.cp_times
+func sysctlLongs(name string) ([]uint64, error) {
+	buf, err := unix.SysctlRaw(name)
+	if err != nil {
+		return nil, err
+	}
+
+	size := strconv.IntSize / 8
+	values := make([]uint64, 0, len(buf)/size)
+	for i := 0; i+size <= len(buf); i += size {
+		if size == 8 {
+			values = append(values, binary.NativeEndian.Uint64(buf[i:]))
+		} else {
+			values = append(values, uint64(binary.NativeEndian.Uint32(buf[i:])))
+		}
+	}
+	return values, nil
+}
+
+// cpuStatsFromCPTime converts one CPUSTATES group of kern.cp_time(s) into CPUStats
+func cpuStatsFromCPTime(ticks []uint64) CPUStats {
+	stats := CPUStats{
+		User:   ticks[0],
+		Nice:   ticks[1],
+		System: ticks[2],
+		IRQ:    ticks[3],
+		Idle:   ticks[4],
+	}
+	stats.Total = stats.User + stats.Nice + stats.System + stats.IRQ + stats.Idle
+	return stats
+}
+
+// getCPUStats reads system-wide CPU ticks from kern.cp_time
+func (sc *SystemCollector) getCPUStats() (CPUStats, error) {
+	ticks, err := sysctlLongs("kern.cp_time")
+	if err != nil {
+		return CPUStats{}, err
+	}
+	if len(ticks) < cpuStatesPerCPU {
+		return CPUStats{}, fmt.Errorf("kern.cp_time has %d fields, expected %d", len(ticks), cpuStatesPerCPU)
+	}
+
+	return cpuStatsFromCPTime(ticks), nil
+}
+
+// getPerCoreCPUStats reads the ticks of each CPU from kern.cp_times
+func (sc *SystemCollector) getPerCoreCPUStats() (map[int]CPUStats, error) {
+	ticks, err := sysctlLongs("kern.cp_times")
+	if err != nil {
+		return nil, err
+	}
+
+	perCore := make(map[int]CPUStats)
+	for core := 0; (core+1)*cpuStatesPerCPU <= len(ticks); core++ {
+		perCore[core] = cpuStatsFromCPTime(ticks[core*cpuStatesPerCPU:])
+	}
+
+	return perCore, nil
+}
@@ -0,0 +1,91 @@
+//go:build linux
+
+package agent
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// getCPUStats reads CPU stats from /proc/stat with better error handling
+func (sc *SystemCollector) getCPUStats() (CPUStats, error) {
+	file, err := os.Open("/proc/stat")
+	if err != nil {
+		return CPUStats{}, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "cpu ") {
+			fields := strings.Fields(line)
+			if len(fields) < 8 {
+				continue
+			}
+
+			return parseCPUStatsFields(fields), nil
+		}
+	}
+
+	return CPUStats{}, fmt.Errorf("cpu stats not found")
+}
+
+// parseCPUStatsFields converts the fields of a /proc/stat cpu line into CPUStats
+func parseCPUStatsFields(fields []string) CPUStats {
+	stats := CPUStats{}
+	stats.User, _ = strconv.ParseUint(fields[1], 10, 64)
+	stats.Nice, _ = strconv.ParseUint(fields[2], 10, 64)
+	stats.System, _ = strconv.ParseUint(fields[3], 10, 64)
+	stats.Idle, _ = strconv.ParseUint(fields[4], 10, 64)
+	stats.IOWait, _ = strconv.ParseUint(fields[5], 10, 64)
+	stats.IRQ, _ = strconv.ParseUint(fields[6], 10, 64)
+	stats.SoftIRQ, _ = strconv.ParseUint(fields[7], 10, 64)
+	if len(fields) > 8 {
+		stats.Steal, _ = strconv.ParseUint(fields[8], 10, 64)
+	}
+	if len(fields) > 9 {
+		stats.Guest, _ = strconv.ParseUint(fields[9], 10, 64)
+	}
+
+	// Calculate total
+	stats.Total = stats.User + stats.Nice + stats.System + stats.Idle +
+		stats.IOWait + stats.IRQ + stats.SoftIRQ + stats.Steal + stats.Guest
+
+	return stats
+}
+
+// getPerCoreCPUStats reads the cpuN lines from /proc/stat keyed by core number
+func (sc *SystemCollector) getPerCoreCPUStats() (map[int]CPUStats, error) {
+	file, err := os.Open("/proc/stat")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	perCore := make(map[int]CPUStats)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "cpu") || strings.HasPrefix(line, "cpu ") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			continue
+		}
+
+		core, err := strconv.Atoi(strings.TrimPrefix(fields[0], "cpu"))
+		if err != nil {
+			continue
+		}
+
+		perCore[core] = parseCPUStatsFields(fields)
+	}
+
+	return perCore, scanner.Err()
+}
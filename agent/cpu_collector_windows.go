@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// systemProcessorPerformanceClass is SystemProcessorPerformanceInformation for NtQuerySystemInformation
+	systemProcessorPerformanceClass = 8
+	// statusInfoLengthMismatch is the NTSTATUS for a buffer too small for the requested information
+	statusInfoLengthMismatch = 0xC0000004
+	// allProcessorGroups makes GetActiveProcessorCount count every processor group
+	allProcessorGroups = 0xFFFF
+)
+
+// systemProcessorPerformanceInformation mirrors SYSTEM_PROCESSOR_PERFORMANCE_INFORMATION, times in 100ns ticks
+type systemProcessorPerformanceInformation struct {
+	IdleTime       int64
+	KernelTime     int64 // Includes IdleTime
+	UserTime       int64
+	DpcTime        int64
+	InterruptTime  int64
+	InterruptCount uint32
+	_              uint32
+}
+
+// getCPUStats reads system-wide CPU times from GetSystemTimes
+func (sc *SystemCollector) getCPUStats() (CPUStats, error) {
+	var idle, kernel, user syscall.Filetime
+	r, _, err := procGetSystemTimes.Call(
+		uintptr(unsafe.Pointer(&idle)),
+		uintptr(unsafe.Pointer(&kernel)),
+		uintptr(unsafe.Pointer(&user)),
+	)
+	if r == 0 {
+		return CPUStats{}, fmt.Errorf("GetSystemTimes failed: %v", err)
+	}
+
+	// Kernel time includes the idle time, so take it out to get busy kernel time
+	stats := CPUStats{
+		User: filetimeTicks(user),
+		Idle: filetimeTicks(idle),
+	}
+	stats.System = filetimeTicks(kernel) - stats.Idle
+	stats.Total = stats.User + stats.System + stats.Idle
+
+	return stats, nil
+}
+
+// activeProcessorCount returns the logical processors in all processor groups. Unlike
+// runtime.NumCPU it isn't limited by the agent's affinity mask.
+func activeProcessorCount() int {
+	if count, _, _ := procGetActiveProcessorCount.Call(allProcessorGroups); count > 0 {
+		return int(count)
+	}
+	return runtime.NumCPU()
+}
+
+// getPerCoreCPUStats reads the times of each logical processor from NtQuerySystemInformation
+func (sc *SystemCollector) getPerCoreCPUStats() (map[int]CPUStats, error) {
+	infos := make([]systemProcessorPerformanceInformation, activeProcessorCount())
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("no processors reported")
+	}
+
+	var returned uint32
+	query := func() uintptr {
+		status, _, _ := procNtQuerySystemInformation.Call(
+			systemProcessorPerformanceClass,
+			uintptr(unsafe.Pointer(&infos[0])),
+			uintptr(len(infos))*unsafe.Sizeof(infos[0]),
+			uintptr(unsafe.Pointer(&returned)),
+		)
+		return status
+	}
+
+	// Retry once with the length the call asked for if processors came online meanwhile
+	status := query()
+	if status == statusInfoLengthMismatch && uintptr(returned) > uintptr(len(infos))*unsafe.Sizeof(infos[0]) {
+		infos = make([]systemProcessorPerformanceInformation, uintptr(returned)/unsafe.Sizeof(infos[0]))
+		status = query()
+	}
+	if status != 0 {
+		return nil, fmt.Errorf("NtQuerySystemInformation failed with status 0x%x", status)
+	}
+
+	perCore := make(map[int]CPUStats)
+	count := int(uintptr(returned) / unsafe.Sizeof(infos[0]))
+	for core := 0; core < count && core < len(infos); core++ {
+		info := infos[core]
+		stats := CPUStats{
+			User:    uint64(info.UserTime),
+			Idle:    uint64(info.IdleTime),
+			IRQ:     uint64(info.InterruptTime),
+			SoftIRQ: uint64(info.DpcTime),
+		}
+		if busy := info.KernelTime - info.IdleTime - info.InterruptTime - info.DpcTime; busy > 0 {
+			stats.System = uint64(busy)
+		}
+		stats.Total = stats.User + stats.System + stats.Idle + stats.IRQ + stats.SoftIRQ
+		perCore[core] = stats
+	}
+
+	return perCore, nil
+}
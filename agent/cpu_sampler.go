@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// cpuSampleInterval is how often the background sampler measures CPU usage
+const cpuSampleInterval = time.Second
+
+// cpuSampler holds the latest CPU usage measured in the background
+type cpuSampler struct {
+	mu        sync.RWMutex
+	usage     float64
+	perCore   []float64
+	breakdown CPUBreakdown
+	ready     chan struct{} // Closed once the first sample is stored
+}
+
+// StartCPUSampling measures CPU usage every interval until ctx is done, so GetCPUUsage and
+// GetPerCoreCPUUsage return the latest sample instead of sleeping between reads. Call it
+// once, before the collector is shared between goroutines.
+func (sc *SystemCollector) StartCPUSampling(ctx context.Context, interval time.Duration) {
+	if sc.cpuSampler != nil {
+		return
+	}
+	sc.cpuSampler = &cpuSampler{ready: make(chan struct{})}
+	go sc.sampleCPU(ctx, interval)
+}
+
+// sampleCPU owns the previous-sample state in the collector while sampling runs
+func (sc *SystemCollector) sampleCPU(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	first := true
+	for {
+		// The first reads take their own short baseline, later ones measure since the previous tick
+		var usage float64
+		var breakdown CPUBreakdown
+		if prev, curr, ok := sc.sampleCPUStats(); ok {
+			usage = sc.calculateCPUPercentage(prev, curr)
+			breakdown = calculateCPUBreakdown(prev, curr)
+		}
+		perCore := sc.getPerCoreCPUUsage()
+
+		sc.cpuSampler.mu.Lock()
+		sc.cpuSampler.usage = math.Round(usage*100) / 100
+		sc.cpuSampler.perCore = perCore
+		sc.cpuSampler.breakdown = breakdown
+		sc.cpuSampler.mu.Unlock()
+		if first {
+			close(sc.cpuSampler.ready)
+			first = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// latestCPUUsage returns the cached samples, waiting for the first one if sampling just
+// started. ok is false when background sampling isn't running.
+func (sc *SystemCollector) latestCPUUsage() (usage float64, perCore []float64, ok bool) {
+	if sc.cpuSampler == nil {
+		return 0, nil, false
+	}
+	<-sc.cpuSampler.ready
+
+	sc.cpuSampler.mu.RLock()
+	defer sc.cpuSampler.mu.RUnlock()
+	return sc.cpuSampler.usage, append([]float64(nil), sc.cpuSampler.perCore...), true
+}
+
+// latestCPUBreakdown returns the cached breakdown, measured over the same interval as the
+// cached usage. ok is false when background sampling isn't running.
+func (sc *SystemCollector) latestCPUBreakdown() (breakdown CPUBreakdown, ok bool) {
+	if sc.cpuSampler == nil {
+		return CPUBreakdown{}, false
+	}
+	<-sc.cpuSampler.ready
+
+	sc.cpuSampler.mu.RLock()
+	defer sc.cpuSampler.mu.RUnlock()
+	return sc.cpuSampler.breakdown, true
+}
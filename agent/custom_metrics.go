@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"monitoring-agent/config"
+	"monitoring-agent/logging"
+	pbClient "monitoring-agent/pocketbase"
+)
+
+const maxCustomMetricOutput = 4 * 1024 // Bytes of stdout kept from a custom metric command
+
+// runCustomMetrics runs the CUSTOM_METRICS commands in parallel and returns their results in
+// configuration order. Each command gets CUSTOM_METRIC_TIMEOUT, so one slow command delays
+// the cycle by at most that long and never hides the others' results.
+func (a *Agent) runCustomMetrics(ctx context.Context) []pbClient.CustomMetric {
+	commands := a.config().CustomMetrics
+	if len(commands) == 0 {
+		return nil
+	}
+
+	results := make([]pbClient.CustomMetric, len(commands))
+	var wg sync.WaitGroup
+	for i, command := range commands {
+		wg.Add(1)
+		go func(i int, command config.CustomMetricCommand) {
+			defer wg.Done()
+			results[i] = runCustomMetric(ctx, command, a.config().CustomMetricTimeout)
+		}(i, command)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runCustomMetric runs one command without a shell, so its configuration is the complete
+// command line and no output or argument is ever interpreted. Output that parses as a number
+// is reported in Value, anything else in Text. A non-zero exit is recorded but keeps the
+// output, since commands like "systemctl is-active" report their state that way.
+func runCustomMetric(ctx context.Context, command config.CustomMetricCommand, timeout time.Duration) pbClient.CustomMetric {
+	metric := pbClient.CustomMetric{Name: command.Name, Type: "command"}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := strings.Fields(command.Command)
+	stdout := &cappedBuffer{limit: maxCustomMetricOutput}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = stdout
+	// Don't wait on children that inherited stdout after the command itself was killed
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		metric.Error = fmt.Sprintf("timed out after %v", timeout)
+		logging.Warnf("Custom metric %s (%s) %s", command.Name, command.Command, metric.Error)
+		return metric
+	case errors.As(err, &exitErr):
+		metric.Error = fmt.Sprintf("exited with code %d", exitErr.ExitCode())
+	case err != nil:
+		metric.Error = err.Error()
+		logging.Warnf("Custom metric %s (%s) failed: %v", command.Name, command.Command, err)
+		return metric
+	}
+
+	output := strings.TrimSpace(stdout.Buffer.String())
+	if value, err := strconv.ParseFloat(output, 64); err == nil {
+		metric.Value = value
+	} else {
+		metric.Text = output
+	}
+	return metric
+}
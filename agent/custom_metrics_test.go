@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"monitoring-agent/config"
+)
+
+func TestRunCustomMetric(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		script    string // Written to a file and run instead of command, as there is no shell
+		wantValue float64
+		wantText  string
+		wantError string
+	}{
+		{name: "numeric", command: "echo 42.5", wantValue: 42.5},
+		{name: "text", command: "echo active", wantText: "active"},
+		{name: "non-zero exit", script: "#!/bin/sh\necho inactive\nexit 3\n", wantText: "inactive", wantError: "exited with code 3"},
+		{name: "timeout", command: "sleep 5", wantError: "timed out after"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command := config.CustomMetricCommand{Name: "m", Command: tt.command}
+			if tt.script != "" {
+				command.Command = filepath.Join(t.TempDir(), "metric.sh")
+				if err := os.WriteFile(command.Command, []byte(tt.script), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			start := time.Now()
+			metric := runCustomMetric(context.Background(), command, 200*time.Millisecond)
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("runCustomMetric took %v, want the timeout to stop the command", elapsed)
+			}
+
+			if metric.Name != "m" || metric.Type != "command" {
+				t.Errorf("name, type = %q, %q; want m, command", metric.Name, metric.Type)
+			}
+			if metric.Value != tt.wantValue || metric.Text != tt.wantText {
+				t.Errorf("value, text = %v, %q; want %v, %q", metric.Value, metric.Text, tt.wantValue, tt.wantText)
+			}
+			if (tt.wantError == "") != (metric.Error == "") || !strings.Contains(metric.Error, tt.wantError) {
+				t.Errorf("error = %q, want %q", metric.Error, tt.wantError)
+			}
+		})
+	}
+}
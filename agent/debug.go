@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxRecentErrors bounds the ring buffer of errors exposed on /debug
+const maxRecentErrors = 50
+
+// recentError is one collection or PocketBase write failure
+type recentError struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Message string    `json:"message"`
+}
+
+// debugState keeps recent errors and the last successful push per collection
+type debugState struct {
+	mu       sync.Mutex
+	errors   []recentError // Ring buffer, next is the slot overwritten next once full
+	next     int
+	lastPush map[string]time.Time
+}
+
+// recordError adds an error to the ring buffer, overwriting the oldest once full
+func (a *Agent) recordError(source string, err error) {
+	d := &a.debug
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry := recentError{Time: time.Now(), Source: source, Message: err.Error()}
+	if len(d.errors) < maxRecentErrors {
+		d.errors = append(d.errors, entry)
+		return
+	}
+	d.errors[d.next] = entry
+	d.next = (d.next + 1) % maxRecentErrors
+}
+
+// recordPush notes a successful write to a PocketBase collection
+func (a *Agent) recordPush(collection string) {
+	d := &a.debug
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.lastPush == nil {
+		d.lastPush = make(map[string]time.Time)
+	}
+	d.lastPush[collection] = time.Now()
+}
+
+// debugHandler returns recent errors, newest first, and the last successful push per collection
+func (a *Agent) debugHandler(w http.ResponseWriter, r *http.Request) {
+	d := &a.debug
+	d.mu.Lock()
+	errors := make([]recentError, 0, len(d.errors))
+	for i := len(d.errors) - 1; i >= 0; i-- {
+		errors = append(errors, d.errors[(d.next+i)%len(d.errors)])
+	}
+	lastPush := make(map[string]string, len(d.lastPush))
+	for collection, t := range d.lastPush {
+		lastPush[collection] = t.Format(time.RFC3339)
+	}
+	d.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"recent_errors": errors,
+		"last_push":     lastPush,
+	})
+}
@@ -1,26 +1,41 @@
-
 package agent
 
 import (
-	"syscall"
+	"errors"
 )
 
-// getDiskUsage returns disk usage for root filesystem
+// errStaleMount is returned when a filesystem doesn't answer statfs in time (e.g. a dead NFS server)
+var errStaleMount = errors.New("mount is stale: statfs did not respond")
+
+// getDiskUsage returns disk usage for the primary filesystem
 func (sc *SystemCollector) getDiskUsage() (used int64, total int64, percentage float64) {
-	var stat syscall.Statfs_t
-	err := syscall.Statfs("/", &stat)
+	total, free, err := sc.getRootDiskSpace()
+	if errors.Is(err, errStaleMount) {
+		// A stale mount has no meaningful usage, report it through getDiskStatus instead
+		return 0, 0, 0
+	}
 	if err != nil {
 		// Return placeholder values if unable to get real disk stats
 		return 5 * 1024 * 1024 * 1024, 20 * 1024 * 1024 * 1024, 25.0
 	}
 
-	total = int64(stat.Blocks) * int64(stat.Bsize)
-	free := int64(stat.Bavail) * int64(stat.Bsize)
 	used = total - free
-
 	if total > 0 {
 		percentage = float64(used) / float64(total) * 100.0
 	}
 
 	return used, total, percentage
-}
\ No newline at end of file
+}
+
+// getDiskStatus reports whether the primary filesystem is "ok", "stale" or "unavailable"
+func (sc *SystemCollector) getDiskStatus() string {
+	_, _, err := sc.getRootDiskSpace()
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, errStaleMount):
+		return "stale"
+	default:
+		return "unavailable"
+	}
+}
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+)
+
+// fakeStatfs replaces statfs for the duration of the test
+func fakeStatfs(t *testing.T, fake func(path string, stat *syscall.Statfs_t) error) {
+	t.Helper()
+	original := statfs
+	statfs = fake
+	t.Cleanup(func() { statfs = original })
+}
+
+func TestGetInodeUsage(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       uint64
+		free        uint64
+		err         error
+		used, total int64
+		percentage  float64
+	}{
+		{"quarter used", 1000, 750, nil, 250, 1000, 25},
+		{"full", 4096, 0, nil, 4096, 4096, 100},
+		{"dynamic inodes", 0, 0, nil, 0, 0, 0},
+		{"statfs fails", 1000, 750, errors.New("permission denied"), 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			fakeStatfs(t, func(path string, stat *syscall.Statfs_t) error {
+				gotPath = path
+				if tt.err != nil {
+					return tt.err
+				}
+				stat.Files = tt.files
+				stat.Ffree = tt.free
+				return nil
+			})
+
+			sc := NewSystemCollector()
+			sc.SetDiskRootPath("/data")
+			used, total, percentage := sc.getInodeUsage()
+			if used != tt.used || total != tt.total || percentage != tt.percentage {
+				t.Errorf("getInodeUsage() = %d, %d, %v; want %d, %d, %v", used, total, percentage, tt.used, tt.total, tt.percentage)
+			}
+			if gotPath != "/data" {
+				t.Errorf("statfs called on %q, want the DISK_ROOT_PATH /data", gotPath)
+			}
+		})
+	}
+}
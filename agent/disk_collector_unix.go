@@ -0,0 +1,94 @@
+//go:build !windows
+
+package agent
+
+import (
+	"sync"
+	"syscall"
+	"time"
+)
+
+// statfsTimeout is how long a mount may take to answer statfs before it is treated as stale
+const statfsTimeout = 2 * time.Second
+
+// statfs is syscall.Statfs, replaced in tests to fake filesystem statistics
+var statfs = syscall.Statfs
+
+// statfsInFlight holds the paths whose statfs is still blocked, so a hung mount costs at
+// most one stuck goroutine rather than one per cycle
+var statfsInFlight sync.Map
+
+// guardedStatfs runs statfs in a goroutine and gives up after statfsTimeout. A stale NFS
+// mount blocks statfs indefinitely; this returns errStaleMount instead, and only the hung
+// path is affected, so other mounts can still be collected.
+func guardedStatfs(path string) (syscall.Statfs_t, error) {
+	if _, busy := statfsInFlight.LoadOrStore(path, struct{}{}); busy {
+		return syscall.Statfs_t{}, errStaleMount
+	}
+
+	type result struct {
+		stat syscall.Statfs_t
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer statfsInFlight.Delete(path)
+		var stat syscall.Statfs_t
+		err := statfs(path, &stat)
+		done <- result{stat, err}
+	}()
+
+	timer := time.NewTimer(statfsTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.stat, r.err
+	case <-timer.C:
+		return syscall.Statfs_t{}, errStaleMount
+	}
+}
+
+// getDiskRootPath returns the filesystem reported as the primary disk
+func (sc *SystemCollector) getDiskRootPath() string {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.diskRootPath == "" {
+		return "/"
+	}
+	return sc.diskRootPath
+}
+
+// getRootDiskSpace returns the size of the primary filesystem and the bytes available to unprivileged users
+func (sc *SystemCollector) getRootDiskSpace() (total int64, free int64, err error) {
+	stat, err := guardedStatfs(sc.getDiskRootPath())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	total = int64(stat.Blocks) * int64(stat.Bsize)
+	free = int64(stat.Bavail) * int64(stat.Bsize)
+	return total, free, nil
+}
+
+// getInodeUsage returns inode usage for the primary filesystem
+func (sc *SystemCollector) getInodeUsage() (used int64, total int64, percentage float64) {
+	stat, err := guardedStatfs(sc.getDiskRootPath())
+	if err != nil {
+		return 0, 0, 0
+	}
+
+	return inodeUsageFromStatfs(stat)
+}
+
+// inodeUsageFromStatfs computes inode usage from a Statfs result
+func inodeUsageFromStatfs(stat syscall.Statfs_t) (used int64, total int64, percentage float64) {
+	total = int64(stat.Files)
+	used = total - int64(stat.Ffree)
+
+	// Some filesystems (e.g. btrfs) allocate inodes dynamically and report zero
+	if total > 0 {
+		percentage = float64(used) / float64(total) * 100.0
+	}
+
+	return used, total, percentage
+}
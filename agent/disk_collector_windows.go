@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// getDiskRootPath returns the filesystem reported as the primary disk. The Unix
+// default "/" means the system drive.
+func (sc *SystemCollector) getDiskRootPath() string {
+	sc.mu.Lock()
+	path := sc.diskRootPath
+	sc.mu.Unlock()
+	if path != "" && path != "/" {
+		return path
+	}
+
+	drive := os.Getenv("SystemDrive")
+	if drive == "" {
+		drive = "C:"
+	}
+	return drive + `\`
+}
+
+// getRootDiskSpace returns the size of the primary volume and the bytes available to the agent
+func (sc *SystemCollector) getRootDiskSpace() (total int64, free int64, err error) {
+	root, err := syscall.UTF16PtrFromString(sc.getDiskRootPath())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var freeAvailable, totalBytes, totalFree uint64
+	r, _, callErr := procGetDiskFreeSpaceExW.Call(
+		uintptr(unsafe.Pointer(root)),
+		uintptr(unsafe.Pointer(&freeAvailable)),
+		uintptr(unsafe.Pointer(&totalBytes)),
+		uintptr(unsafe.Pointer(&totalFree)),
+	)
+	if r == 0 {
+		return 0, 0, callErr
+	}
+
+	return int64(totalBytes), int64(freeAvailable), nil
+}
+
+// getInodeUsage returns zeros, NTFS has no fixed inode table to run out of
+func (sc *SystemCollector) getInodeUsage() (used int64, total int64, percentage float64) {
+	return 0, 0, 0
+}
@@ -0,0 +1,245 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// dockerAPIClient talks to the Docker Engine API over its unix socket
+type dockerAPIClient struct {
+	socketPath string
+	httpClient *http.Client
+}
+
+// dockerAPIContainer is an entry from GET /containers/json
+type dockerAPIContainer struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	Status  string            `json:"Status"`
+	State   string            `json:"State"`
+	Created int64             `json:"Created"`
+	Labels  map[string]string `json:"Labels"`
+}
+
+// dockerAPIInspect is the subset of GET /containers/{id}/json used by the collector
+type dockerAPIInspect struct {
+	SizeRw       int64 `json:"SizeRw"`
+	SizeRootFs   int64 `json:"SizeRootFs"`
+	RestartCount int   `json:"RestartCount"`
+	State        struct {
+		ExitCode int `json:"ExitCode"`
+	} `json:"State"`
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	HostConfig struct {
+		Memory int64 `json:"Memory"` // 0 when unlimited
+	} `json:"HostConfig"`
+}
+
+// dockerAPIStats is the subset of GET /containers/{id}/stats used by the collector
+type dockerAPIStats struct {
+	CPUStats    dockerAPICPUStats `json:"cpu_stats"`
+	PreCPUStats dockerAPICPUStats `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+}
+
+type dockerAPICPUStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	SystemCPUUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs     uint32 `json:"online_cpus"`
+}
+
+// newDockerAPIClient returns a client for the first socket that answers a ping, or nil.
+// Podman's compat API speaks the same protocol, so its socket can be passed too.
+func newDockerAPIClient(socketPaths []string) *dockerAPIClient {
+	for _, socketPath := range socketPaths {
+		client := &dockerAPIClient{
+			socketPath: socketPath,
+			httpClient: &http.Client{
+				Timeout: 10 * time.Second,
+				Transport: &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						var dialer net.Dialer
+						return dialer.DialContext(ctx, "unix", socketPath)
+					},
+				},
+			},
+		}
+
+		if err := client.ping(context.Background()); err == nil {
+			return client
+		}
+	}
+
+	return nil
+}
+
+// get performs a GET against the Engine API and decodes the JSON response into out.
+// Cancelling ctx aborts the request.
+func (c *dockerAPIClient) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker"+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create docker API request: %v", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("docker API request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("docker API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// ping checks that the daemon is reachable on the socket
+func (c *dockerAPIClient) ping(ctx context.Context) error {
+	return c.get(ctx, "/_ping", nil)
+}
+
+// version returns the Docker server version
+func (c *dockerAPIClient) version(ctx context.Context) (string, error) {
+	var response struct {
+		Version string `json:"Version"`
+	}
+	if err := c.get(ctx, "/version", &response); err != nil {
+		return "", err
+	}
+	return response.Version, nil
+}
+
+// listContainers lists all containers, including stopped ones
+func (c *dockerAPIClient) listContainers(ctx context.Context) ([]DockerStats, error) {
+	var response []dockerAPIContainer
+	if err := c.get(ctx, "/containers/json?all=1", &response); err != nil {
+		return nil, err
+	}
+
+	containers := make([]DockerStats, 0, len(response))
+	for _, item := range response {
+		name := ""
+		if len(item.Names) > 0 {
+			name = strings.TrimPrefix(item.Names[0], "/")
+		}
+
+		created := time.Unix(item.Created, 0)
+		id := item.ID
+		if len(id) > 12 {
+			id = id[:12] // Match the short IDs reported by the CLI
+		}
+
+		containers = append(containers, DockerStats{
+			ID:      id,
+			Name:    name,
+			Status:  item.Status,
+			Uptime:  humanizeAge(time.Since(created)),
+			Created: created,
+			Image:   item.Image,
+			Labels:  item.Labels,
+		})
+	}
+
+	return containers, nil
+}
+
+// containerStats fills CPU, memory and network usage for a running container
+func (c *dockerAPIClient) containerStats(ctx context.Context, containerID string, stats *DockerStats) error {
+	var response dockerAPIStats
+	if err := c.get(ctx, "/containers/"+containerID+"/stats?stream=false", &response); err != nil {
+		return err
+	}
+
+	// CPU percentage is computed the same way as docker stats does
+	cpuDelta := float64(response.CPUStats.CPUUsage.TotalUsage) - float64(response.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(response.CPUStats.SystemCPUUsage) - float64(response.PreCPUStats.SystemCPUUsage)
+	onlineCPUs := float64(response.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(response.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		stats.CPUUsage = math.Round(cpuDelta/systemDelta*onlineCPUs*100*100) / 100
+	}
+
+	// Page cache is excluded from usage like the CLI does (cache on cgroup v1, inactive_file on v2)
+	memUsage := response.MemoryStats.Usage
+	if cache, ok := response.MemoryStats.Stats["cache"]; ok && cache < memUsage {
+		memUsage -= cache
+	} else if inactive, ok := response.MemoryStats.Stats["inactive_file"]; ok && inactive < memUsage {
+		memUsage -= inactive
+	}
+	stats.MemUsage = int64(memUsage)
+	stats.MemTotal = int64(response.MemoryStats.Limit)
+
+	for _, network := range response.Networks {
+		stats.NetworkRxBytes += int64(network.RxBytes)
+		stats.NetworkTxBytes += int64(network.TxBytes)
+	}
+
+	return nil
+}
+
+// containerSize returns the size of a container's writable layer and of its whole
+// filesystem, image layers included
+func (c *dockerAPIClient) containerSize(ctx context.Context, containerID string) (sizeRw, sizeRootFs int64, err error) {
+	var response dockerAPIInspect
+	if err := c.get(ctx, "/containers/"+containerID+"/json?size=1", &response); err != nil {
+		return 0, 0, err
+	}
+	return response.SizeRw, response.SizeRootFs, nil
+}
+
+// inspectContainer returns a container's configuration without computing sizes
+func (c *dockerAPIClient) inspectContainer(ctx context.Context, containerID string) (dockerAPIInspect, error) {
+	var response dockerAPIInspect
+	err := c.get(ctx, "/containers/"+containerID+"/json", &response)
+	return response, err
+}
+
+// humanizeAge formats a duration the way docker ps reports RunningFor, e.g. "3 hours ago"
+func humanizeAge(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return "Less than a second ago"
+	case d < time.Minute:
+		return fmt.Sprintf("%d seconds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%d minutes ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%d hours ago", int(d.Hours()))
+	case d < 14*24*time.Hour:
+		return fmt.Sprintf("%d days ago", int(d.Hours()/24))
+	case d < 60*24*time.Hour:
+		return fmt.Sprintf("%d weeks ago", int(d.Hours()/24/7))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%d months ago", int(d.Hours()/24/30))
+	default:
+		return fmt.Sprintf("%d years ago", int(d.Hours()/24/365))
+	}
+}
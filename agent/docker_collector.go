@@ -2,11 +2,16 @@
 package agent
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"monitoring-agent/logging"
 )
 
 // DockerStats represents Docker container statistics
@@ -15,52 +20,102 @@ type DockerStats struct {
 	Name      string
 	CPUUsage  float64
 	MemUsage  int64
-	MemTotal  int64
-	DiskUsage int64
-	DiskTotal int64
+	StatsAvailable bool // CPU, memory and network usage were measured; they are 0 otherwise
+	MemTotal  int64 // The memory limit, or host memory for containers without one
+	MemLimit  int64 // Configured memory limit, 0 when the container is unlimited
+	DiskUsage int64 // Size of the writable layer, what the container itself has written
+	DiskTotal int64 // Size of the whole filesystem including image layers, 0 when unknown
 	Status    string
 	Uptime    string
 	NetworkRxBytes int64
 	NetworkTxBytes int64
 	NetworkRxSpeed int64
 	NetworkTxSpeed int64
+	Created        time.Time
+	ComposeProject string // Empty when the container isn't part of a compose project
+	ComposeService string
+	RestartCount   int
+	LastExitCode   int // Exit code of the last run, kept while the container is stopped
+	Image          string // Full image reference, including any digest
+	ImageRepo      string
+	ImageTag       string // Empty for images referenced by digest
+	Labels         map[string]string
+	Blkio          BlkioThrottle
 }
 
 // DockerInfo represents general Docker system information
 type DockerInfo struct {
 	Available bool
+	Runtime   string // "Docker" or "Podman"
 	Version   string
 	Containers []DockerStats
+	Summary   DockerSummary
+}
+
+// DockerSummary aggregates container states for the Docker host
+type DockerSummary struct {
+	Total            int
+	Running          int
+	Stopped          int
+	OldestStoppedAge time.Duration // Age of the oldest non-running container
 }
 
-// IsDockerAvailable checks if Docker service is running with enhanced detection
+// IsDockerAvailable checks if a docker-compatible container runtime is running
 func (sc *SystemCollector) IsDockerAvailable() bool {
-	// First check if Docker socket exists
-	if !sc.checkDockerSocket() {
-		return false
-	}
+	return sc.getContainerRuntime() != nil
+}
+
+// getContainerRuntime returns the detected container runtime, or nil when none is usable
+func (sc *SystemCollector) getContainerRuntime() *containerRuntime {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
 	
-	// Try multiple approaches to detect Docker command availability
-	dockerPaths := []string{
-		"/usr/bin/docker",
-		"/usr/local/bin/docker",
-		"/bin/docker",
-		"/usr/sbin/docker",
-		"docker", // fallback to PATH
+	if sc.runtime != nil {
+		return sc.runtime
 	}
 	
-	for _, dockerPath := range dockerPaths {
-		if err := sc.tryDockerCommand(dockerPath); err == nil {
-			return true
+	for _, rt := range runtimeCandidates(sc.containerRuntime) {
+		if rt.RequireSocket && !rt.hasSocket() {
+			continue
+		}
+		
+		// The Engine API answering on the socket is enough, no CLI needed
+		if api := newDockerAPIClient(rt.Sockets); api != nil {
+			sc.runtime = &rt
+			sc.dockerAPI = api
+			return sc.runtime
+		}
+		
+		for _, binaryPath := range rt.Binaries {
+			if err := sc.tryDockerCommand(binaryPath, rt.VersionFormat); err == nil {
+				sc.runtime = &rt
+				return sc.runtime
+			}
 		}
 	}
 	
-	return false
+	return nil
+}
+
+// runtimeBinaries returns the CLI candidates for the detected runtime
+func (sc *SystemCollector) runtimeBinaries() []string {
+	if rt := sc.getContainerRuntime(); rt != nil {
+		return rt.Binaries
+	}
+	return dockerRuntime().Binaries
+}
+
+// runtimeLabel returns the display name of the detected runtime, e.g. "Podman"
+func (sc *SystemCollector) runtimeLabel() string {
+	if rt := sc.getContainerRuntime(); rt != nil {
+		return rt.Label
+	}
+	return "Docker"
 }
 
-// tryDockerCommand attempts to run docker version command with specific binary path
-func (sc *SystemCollector) tryDockerCommand(dockerPath string) error {
-	cmd := exec.Command(dockerPath, "version", "--format", "{{.Server.Version}}")
+// tryDockerCommand attempts to run the runtime's version command with specific binary path
+func (sc *SystemCollector) tryDockerCommand(dockerPath, versionFormat string) error {
+	cmd := exec.Command(dockerPath, "version", "--format", versionFormat)
 	
 	// Set environment variables for systemd service execution
 	cmd.Env = append(os.Environ(),
@@ -71,24 +126,25 @@ func (sc *SystemCollector) tryDockerCommand(dockerPath string) error {
 	return err
 }
 
-// checkDockerSocket checks if Docker socket is accessible
-func (sc *SystemCollector) checkDockerSocket() bool {
-	socketPaths := []string{
-		"/var/run/docker.sock",
-		"/run/docker.sock",
+// getDockerAPI returns the Engine API client, or nil when the socket isn't reachable and the CLI must be used
+func (sc *SystemCollector) getDockerAPI() *dockerAPIClient {
+	rt := sc.getContainerRuntime()
+	if rt == nil {
+		return nil
 	}
 	
-	for _, socketPath := range socketPaths {
-		if _, err := os.Stat(socketPath); err == nil {
-			return true
-		}
-	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
 	
-	return false
+	if sc.dockerAPI == nil {
+		sc.dockerAPI = newDockerAPIClient(rt.Sockets)
+	}
+	return sc.dockerAPI
 }
 
-// GetDockerInfo returns comprehensive Docker information
-func (sc *SystemCollector) GetDockerInfo() DockerInfo {
+// GetDockerInfo returns comprehensive Docker information. Cancelling ctx aborts the
+// API requests and CLI commands still running.
+func (sc *SystemCollector) GetDockerInfo(ctx context.Context) DockerInfo {
 	dockerInfo := DockerInfo{
 		Available: sc.IsDockerAvailable(),
 	}
@@ -96,27 +152,35 @@ func (sc *SystemCollector) GetDockerInfo() DockerInfo {
 	if !dockerInfo.Available {
 		return dockerInfo
 	}
+	dockerInfo.Runtime = sc.runtimeLabel()
 
 	// Get Docker version
-	dockerInfo.Version = sc.getDockerVersion()
+	dockerInfo.Version = sc.getDockerVersion(ctx)
 	
 	// Get container statistics
-	dockerInfo.Containers = sc.getDockerContainers()
+	dockerInfo.Containers = sc.getDockerContainers(ctx)
+	dockerInfo.Summary = sc.summarizeContainers(dockerInfo.Containers)
 
 	return dockerInfo
 }
 
 // getDockerVersion gets Docker version with enhanced path detection and better error handling
-func (sc *SystemCollector) getDockerVersion() string {
-	dockerPaths := []string{
-		"/usr/bin/docker",
-		"/usr/local/bin/docker",
-		"/bin/docker",
-		"docker",
+func (sc *SystemCollector) getDockerVersion(ctx context.Context) string {
+	if api := sc.getDockerAPI(); api != nil {
+		if version, err := api.version(ctx); err == nil {
+			return version
+		}
+	}
+	
+	dockerPaths := sc.runtimeBinaries()
+	
+	versionFormat := dockerRuntime().VersionFormat
+	if rt := sc.getContainerRuntime(); rt != nil {
+		versionFormat = rt.VersionFormat
 	}
 	
 	for _, dockerPath := range dockerPaths {
-		cmd := exec.Command(dockerPath, "version", "--format", "{{.Server.Version}}")
+		cmd := exec.CommandContext(ctx, dockerPath, "version", "--format", versionFormat)
 		cmd.Env = append(os.Environ(),
 			"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
 		)
@@ -131,24 +195,61 @@ func (sc *SystemCollector) getDockerVersion() string {
 	return "permission_denied"
 }
 
-// getDockerContainers gets statistics for all running containers with improved error handling
-func (sc *SystemCollector) getDockerContainers() []DockerStats {
-	var containers []DockerStats
+// GetDockerSummary returns container counts without collecting per-container stats
+func (sc *SystemCollector) GetDockerSummary(ctx context.Context) DockerSummary {
+	containers, err := sc.listDockerContainers(ctx)
+	if err != nil {
+		return DockerSummary{}
+	}
+	return sc.summarizeContainers(sc.filterContainers(containers))
+}
+
+// summarizeContainers aggregates the running and stopped container sets
+func (sc *SystemCollector) summarizeContainers(containers []DockerStats) DockerSummary {
+	summary := DockerSummary{Total: len(containers)}
+	now := time.Now()
+	
+	for _, container := range containers {
+		if isContainerRunning(container.Status) {
+			summary.Running++
+			continue
+		}
+		
+		summary.Stopped++
+		if !container.Created.IsZero() {
+			if age := now.Sub(container.Created); age > summary.OldestStoppedAge {
+				summary.OldestStoppedAge = age
+			}
+		}
+	}
 	
-	dockerPaths := []string{
-		"/usr/bin/docker",
-		"/usr/local/bin/docker",
-		"/bin/docker",
-		"docker",
+	return summary
+}
+
+// isContainerRunning reports whether a docker ps status string describes a running container
+func isContainerRunning(status string) bool {
+	return strings.Contains(strings.ToLower(status), "up")
+}
+
+// listDockerContainers lists all containers, including stopped ones, without collecting stats
+func (sc *SystemCollector) listDockerContainers(ctx context.Context) ([]DockerStats, error) {
+	if api := sc.getDockerAPI(); api != nil {
+		if containers, err := api.listContainers(ctx); err == nil {
+			return containers, nil
+		}
 	}
 	
+	var containers []DockerStats
+	
+	dockerPaths := sc.runtimeBinaries()
+	
 	var cmd *exec.Cmd
 	var output []byte
 	var err error
 	
 	// Try different Docker binary paths to list containers
 	for _, dockerPath := range dockerPaths {
-		cmd = exec.Command(dockerPath, "ps", "--all", "--format", "{{.ID}}\t{{.Names}}\t{{.Status}}\t{{.RunningFor}}")
+		cmd = exec.CommandContext(ctx, dockerPath, "ps", "--all", "--format", "{{.ID}}\t{{.Names}}\t{{.Status}}\t{{.RunningFor}}\t{{.CreatedAt}}\t{{.Image}}\t{{.Labels}}")
 		cmd.Env = append(os.Environ(),
 			"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
 		)
@@ -160,7 +261,7 @@ func (sc *SystemCollector) getDockerContainers() []DockerStats {
 	}
 	
 	if err != nil {
-		return containers
+		return containers, err
 	}
 
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
@@ -175,16 +276,112 @@ func (sc *SystemCollector) getDockerContainers() []DockerStats {
 			continue
 		}
 
-		containerID := strings.TrimSpace(parts[0])
-		containerName := strings.TrimSpace(parts[1])
-		status := strings.TrimSpace(parts[2])
-		uptime := ""
+		container := DockerStats{
+			ID:     strings.TrimSpace(parts[0]),
+			Name:   strings.TrimSpace(parts[1]),
+			Status: strings.TrimSpace(parts[2]),
+		}
 		if len(parts) > 3 {
-			uptime = strings.TrimSpace(parts[3])
+			container.Uptime = strings.TrimSpace(parts[3])
+		}
+		if len(parts) > 4 {
+			container.Created = parseDockerCreatedAt(strings.TrimSpace(parts[4]))
+		}
+		if len(parts) > 5 {
+			container.Image = strings.TrimSpace(parts[5])
+		}
+		if len(parts) > 6 {
+			container.Labels = parseDockerLabels(strings.TrimSpace(parts[6]))
+		}
+
+		containers = append(containers, container)
+	}
+
+	return containers, nil
+}
+
+// parseDockerCreatedAt parses docker ps CreatedAt values like "2024-01-02 15:04:05 +0000 UTC"
+func parseDockerCreatedAt(createdAt string) time.Time {
+	created, err := time.Parse("2006-01-02 15:04:05 -0700 MST", createdAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return created
+}
+
+// splitImageReference splits an image like "registry:5000/app:1.2" into repository and tag.
+// Digest references keep no tag and bare image IDs can't be split.
+func splitImageReference(image string) (repo, tag string) {
+	if image == "" || strings.HasPrefix(image, "sha256:") {
+		return "", ""
+	}
+	if at := strings.Index(image, "@"); at >= 0 {
+		return image[:at], ""
+	}
+	
+	// A colon after the last slash separates the tag, earlier ones belong to a registry port
+	if colon := strings.LastIndex(image, ":"); colon > strings.LastIndex(image, "/") {
+		return image[:colon], image[colon+1:]
+	}
+	return image, "latest"
+}
+
+// getDockerContainers gets statistics for the monitored containers using a bounded worker pool
+func (sc *SystemCollector) getDockerContainers(ctx context.Context) []DockerStats {
+	var containers []DockerStats
+	
+	listed, err := sc.listDockerContainers(ctx)
+	if err != nil {
+		return containers
+	}
+	// Filter before collecting stats, which is the expensive part
+	listed = sc.filterContainers(listed)
+	sc.pruneContainerNetwork(listed)
+
+	sc.mu.Lock()
+	workers := sc.dockerStatsConcurrency
+	sc.mu.Unlock()
+	if workers <= 0 {
+		workers = 4
+	}
+	if workers > len(listed) {
+		workers = len(listed)
+	}
+
+	// Each worker writes only its own slot, keeping the result in listing order
+	results := make([]DockerStats, len(listed))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				container := listed[i]
+				stats := sc.getContainerStats(ctx, container.ID, container.Name, container.Status, container.Uptime)
+				stats.Created = container.Created
+				stats.Image = container.Image
+				stats.ImageRepo, stats.ImageTag = splitImageReference(container.Image)
+				stats.Labels = container.Labels
+				results[i] = stats
+			}
+		}()
+	}
+	
+	// Stop handing out containers once ctx is cancelled; those left out are dropped below
+dispatch:
+	for i := range listed {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
 		}
+	}
+	close(jobs)
+	wg.Wait()
 
-		// Get detailed stats for this container
-		stats := sc.getContainerStats(containerID, containerName, status, uptime)
+	for _, stats := range results {
 		if stats.ID != "" {
 			containers = append(containers, stats)
 		}
@@ -194,30 +391,37 @@ func (sc *SystemCollector) getDockerContainers() []DockerStats {
 }
 
 // getContainerStats gets detailed statistics for a specific container with better error handling
-func (sc *SystemCollector) getContainerStats(containerID, containerName, status, uptime string) DockerStats {
+func (sc *SystemCollector) getContainerStats(ctx context.Context, containerID, containerName, status, uptime string) DockerStats {
 	stats := DockerStats{
 		ID:     containerID,
 		Name:   containerName,
 		Status: status,
 		Uptime: uptime,
 	}
+	sc.inspectContainerDetails(ctx, containerID, &stats)
 
-	// Skip stats collection for stopped containers
-	if !strings.Contains(strings.ToLower(status), "up") {
-		stats.CPUUsage = 0.0
-		stats.MemUsage = 0
-		stats.MemTotal = 1024 * 1024 * 1024 // 1GB default
-		stats.DiskUsage = 0
-		stats.DiskTotal = 10 * 1024 * 1024 * 1024 // 10GB default
+	// The writable layer outlives the container's process, so stopped containers report it too
+	stats.DiskUsage, stats.DiskTotal = sc.getContainerDiskSize(ctx, containerID)
+
+	// Stopped containers have no usage to collect
+	if !isContainerRunning(status) {
 		return stats
 	}
 
-	dockerPaths := []string{
-		"/usr/bin/docker",
-		"/usr/local/bin/docker",
-		"/bin/docker",
-		"docker",
+	// Block I/O throttling comes straight from the container's cgroup
+	stats.Blkio, _ = sc.getContainerBlkioThrottle(containerID)
+
+	// Prefer the Engine API, it avoids spawning a docker process per container
+	if api := sc.getDockerAPI(); api != nil {
+		if err := api.containerStats(ctx, containerID, &stats); err == nil {
+			stats.StatsAvailable = true
+			sc.applyContainerMemoryLimit(containerID, &stats)
+			sc.setContainerNetworkSpeed(&stats)
+			return stats
+		}
 	}
+
+	dockerPaths := sc.runtimeBinaries()
 	
 	var cmd *exec.Cmd
 	var output []byte
@@ -225,8 +429,8 @@ func (sc *SystemCollector) getContainerStats(containerID, containerName, status,
 	
 	// Try different Docker binary paths for stats command
 	for _, dockerPath := range dockerPaths {
-		cmd = exec.Command(dockerPath, "stats", "--no-stream", "--format", 
-			"{{.CPUPerc}}\t{{.MemUsage}}\t{{.NetIO}}\t{{.BlockIO}}", containerID)
+		cmd = exec.CommandContext(ctx, dockerPath, "stats", "--no-stream", "--format", 
+			"{{.CPUPerc}}\t{{.MemUsage}}\t{{.NetIO}}", containerID)
 		cmd.Env = append(os.Environ(),
 			"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
 		)
@@ -238,12 +442,7 @@ func (sc *SystemCollector) getContainerStats(containerID, containerName, status,
 	}
 	
 	if err != nil {
-		// Set default values when stats collection fails
-		stats.CPUUsage = 0.0
-		stats.MemUsage = 512 * 1024 * 1024 // 512MB default
-		stats.MemTotal = 2 * 1024 * 1024 * 1024 // 2GB default
-		stats.DiskUsage = 1024 * 1024 * 1024 // 1GB default
-		stats.DiskTotal = 10 * 1024 * 1024 * 1024 // 10GB default
+		logging.Debugf("Stats unavailable for container %s: %v", containerName, err)
 		return stats
 	}
 
@@ -255,53 +454,108 @@ func (sc *SystemCollector) getContainerStats(containerID, containerName, status,
 	// Parse the stats line
 	fields := strings.Split(statsLine, "\t")
 	
-	if len(fields) >= 4 {
-		// Parse CPU usage (remove % sign)
-		cpuStr := strings.TrimSuffix(strings.TrimSpace(fields[0]), "%")
-		if cpuUsage, err := strconv.ParseFloat(cpuStr, 64); err == nil {
-			stats.CPUUsage = cpuUsage
-		}
+	if len(fields) < 3 {
+		logging.Debugf("Stats unavailable for container %s: unexpected output %q", containerName, statsLine)
+		return stats
+	}
+	stats.StatsAvailable = true
 
-		// Parse memory usage (format: "used / total")
-		memUsage := strings.TrimSpace(fields[1])
-		stats.MemUsage, stats.MemTotal = sc.parseMemoryUsage(memUsage)
+	// Parse CPU usage (remove % sign)
+	cpuStr := strings.TrimSuffix(strings.TrimSpace(fields[0]), "%")
+	if cpuUsage, err := strconv.ParseFloat(cpuStr, 64); err == nil {
+		stats.CPUUsage = cpuUsage
+	}
 
-		// Parse network I/O (format: "rx / tx")
-		netIO := strings.TrimSpace(fields[2])
-		stats.NetworkRxBytes, stats.NetworkTxBytes = sc.parseNetworkIO(netIO)
+	// Parse memory usage (format: "used / total")
+	memUsage := strings.TrimSpace(fields[1])
+	stats.MemUsage, stats.MemTotal = sc.parseMemoryUsage(memUsage)
+	sc.applyContainerMemoryLimit(containerID, &stats)
 
-		// Parse block I/O for disk usage (format: "read / write")
-		blockIO := strings.TrimSpace(fields[3])
-		diskRead, diskWrite := sc.parseBlockIO(blockIO)
-		stats.DiskUsage = diskRead + diskWrite
-		stats.DiskTotal = sc.getContainerDiskTotal(containerID)
-	}
+	// Parse network I/O (format: "rx / tx")
+	netIO := strings.TrimSpace(fields[2])
+	stats.NetworkRxBytes, stats.NetworkTxBytes = sc.parseNetworkIO(netIO)
 
-	// Calculate network speeds (simplified - bytes per second estimate)
-	stats.NetworkRxSpeed = stats.NetworkRxBytes / 3600 // Rough hourly average
-	stats.NetworkTxSpeed = stats.NetworkTxBytes / 3600 // Rough hourly average
+	sc.setContainerNetworkSpeed(&stats)
 
 	return stats
 }
 
+// containerNetworkSample is a container's network counters at one collection
+type containerNetworkSample struct {
+	rxBytes int64
+	txBytes int64
+	time    time.Time
+}
+
+// setContainerNetworkSpeed computes the container's network rates from its counters at the
+// previous collection. The first sample and counters that went backwards, e.g. after the
+// container restarted, report 0.
+func (sc *SystemCollector) setContainerNetworkSpeed(stats *DockerStats) {
+	sc.deltaMu.Lock()
+	defer sc.deltaMu.Unlock()
+
+	now := timeNow()
+	if last, ok := sc.lastContainerNetwork[stats.ID]; ok {
+		elapsed := now.Sub(last.time).Seconds()
+		if elapsed > 0 && stats.NetworkRxBytes >= last.rxBytes && stats.NetworkTxBytes >= last.txBytes {
+			stats.NetworkRxSpeed = int64(float64(stats.NetworkRxBytes-last.rxBytes) / elapsed)
+			stats.NetworkTxSpeed = int64(float64(stats.NetworkTxBytes-last.txBytes) / elapsed)
+		}
+	}
+
+	if sc.lastContainerNetwork == nil {
+		sc.lastContainerNetwork = make(map[string]containerNetworkSample)
+	}
+	sc.lastContainerNetwork[stats.ID] = containerNetworkSample{
+		rxBytes: stats.NetworkRxBytes,
+		txBytes: stats.NetworkTxBytes,
+		time:    now,
+	}
+}
+
+// pruneContainerNetwork forgets the network samples of containers that are no longer listed
+func (sc *SystemCollector) pruneContainerNetwork(listed []DockerStats) {
+	current := make(map[string]bool, len(listed))
+	for _, container := range listed {
+		current[container.ID] = true
+	}
+
+	sc.deltaMu.Lock()
+	defer sc.deltaMu.Unlock()
+	for id := range sc.lastContainerNetwork {
+		if !current[id] {
+			delete(sc.lastContainerNetwork, id)
+		}
+	}
+}
+
+// applyContainerMemoryLimit reports memory usage against the container's own limit, taken
+// from cgroup v2 memory.max or else the configured HostConfig.Memory. On cgroup v2 hosts
+// docker stats often gives the host's memory as the total even for limited containers.
+// Unlimited containers keep the host's memory as their total.
+func (sc *SystemCollector) applyContainerMemoryLimit(containerID string, stats *DockerStats) {
+	if limit, ok := getContainerMemoryMax(containerID); ok {
+		stats.MemLimit = limit
+	}
+	if stats.MemLimit > 0 {
+		stats.MemTotal = stats.MemLimit
+		return
+	}
+	if memInfo, err := sc.getMemInfo(); err == nil && memInfo["MemTotal"] > 0 {
+		stats.MemTotal = memInfo["MemTotal"]
+	}
+}
+
 // parseMemoryUsage parses Docker memory usage string like "1.5GiB / 8GiB"
 func (sc *SystemCollector) parseMemoryUsage(memUsage string) (used int64, total int64) {
 	parts := strings.Split(memUsage, " / ")
 	if len(parts) != 2 {
-		return 512 * 1024 * 1024, 2 * 1024 * 1024 * 1024 // Default 512MB / 2GB
+		return 0, 0
 	}
 
 	used = sc.parseDataSize(strings.TrimSpace(parts[0]))
 	total = sc.parseDataSize(strings.TrimSpace(parts[1]))
 	
-	// Ensure we have reasonable values
-	if used == 0 {
-		used = 512 * 1024 * 1024 // 512MB default
-	}
-	if total == 0 {
-		total = 2 * 1024 * 1024 * 1024 // 2GB default
-	}
-	
 	return used, total
 }
 
@@ -318,19 +572,6 @@ func (sc *SystemCollector) parseNetworkIO(netIO string) (rxBytes int64, txBytes
 	return rxBytes, txBytes
 }
 
-// parseBlockIO parses block I/O string like "1.2MB / 3.4MB"
-func (sc *SystemCollector) parseBlockIO(blockIO string) (readBytes int64, writeBytes int64) {
-	parts := strings.Split(blockIO, " / ")
-	if len(parts) != 2 {
-		return 0, 0
-	}
-
-	readBytes = sc.parseDataSize(strings.TrimSpace(parts[0]))
-	writeBytes = sc.parseDataSize(strings.TrimSpace(parts[1]))
-	
-	return readBytes, writeBytes
-}
-
 // parseDataSize converts data size string to bytes (handles kB, MB, GB, KiB, MiB, GiB)
 func (sc *SystemCollector) parseDataSize(sizeStr string) int64 {
 	if sizeStr == "" || sizeStr == "0B" || sizeStr == "0" {
@@ -382,34 +623,91 @@ func (sc *SystemCollector) parseDataSize(sizeStr string) int64 {
 	return result
 }
 
-// getContainerDiskTotal gets container disk total using docker system df
-func (sc *SystemCollector) getContainerDiskTotal(containerID string) int64 {
-	dockerPaths := []string{
-		"/usr/bin/docker",
-		"/usr/local/bin/docker",
-		"/bin/docker",
-		"docker",
+// getContainerDiskSize returns the size of a container's writable layer (SizeRw) and of its
+// whole filesystem (SizeRootFs) using the Engine API or inspect --size. Both are 0 when the
+// runtime doesn't report them.
+func (sc *SystemCollector) getContainerDiskSize(ctx context.Context, containerID string) (sizeRw, sizeRootFs int64) {
+	if api := sc.getDockerAPI(); api != nil {
+		if sizeRw, sizeRootFs, err := api.containerSize(ctx, containerID); err == nil {
+			return sizeRw, sizeRootFs
+		}
 	}
 	
-	// Try docker inspect first
-	for _, dockerPath := range dockerPaths {
-		cmd := exec.Command(dockerPath, "inspect", "--format", "{{.SizeRootFs}}", containerID)
+	for _, dockerPath := range sc.runtimeBinaries() {
+		cmd := exec.CommandContext(ctx, dockerPath, "inspect", "--size", "--format", "{{.SizeRw}} {{.SizeRootFs}}", containerID)
 		cmd.Env = append(os.Environ(),
 			"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
 		)
 		
 		output, err := cmd.Output()
+		if err != nil {
+			continue
+		}
+		
+		// Sizes the runtime couldn't compute print as "<no value>" and stay 0
+		fields := strings.Fields(string(output))
+		if len(fields) == 2 {
+			sizeRw, _ = strconv.ParseInt(fields[0], 10, 64)
+			sizeRootFs, _ = strconv.ParseInt(fields[1], 10, 64)
+		}
+		return sizeRw, sizeRootFs
+	}
+	
+	return 0, 0
+}
+
+// inspectContainerDetails fills compose labels, restart count and last exit code from docker inspect.
+// Labels are left empty when unset and nothing is filled when inspect fails.
+func (sc *SystemCollector) inspectContainerDetails(ctx context.Context, containerID string, stats *DockerStats) {
+	if api := sc.getDockerAPI(); api != nil {
+		if inspect, err := api.inspectContainer(ctx, containerID); err == nil {
+			stats.ComposeProject = inspect.Config.Labels["com.docker.compose.project"]
+			stats.ComposeService = inspect.Config.Labels["com.docker.compose.service"]
+			stats.RestartCount = inspect.RestartCount
+			stats.LastExitCode = inspect.State.ExitCode
+			stats.MemLimit = inspect.HostConfig.Memory
+			return
+		}
+	}
+	
+	output, err := sc.inspectContainerCLI(ctx, containerID, `{{.RestartCount}}|{{.State.ExitCode}}|{{.HostConfig.Memory}}|{{index .Config.Labels "com.docker.compose.project"}}|{{index .Config.Labels "com.docker.compose.service"}}`)
+	if err != nil {
+		return
+	}
+	
+	parts := strings.Split(output, "|")
+	if len(parts) != 5 {
+		return
+	}
+	stats.RestartCount, _ = strconv.Atoi(parts[0])
+	stats.LastExitCode, _ = strconv.Atoi(parts[1])
+	stats.MemLimit, _ = strconv.ParseInt(parts[2], 10, 64)
+	
+	// index on a missing label prints "<no value>"
+	for i, part := range parts[3:] {
+		if part == "<no value>" {
+			parts[3+i] = ""
+		}
+	}
+	stats.ComposeProject, stats.ComposeService = parts[3], parts[4]
+}
+
+// inspectContainerCLI runs docker inspect with a Go template format and returns the trimmed output
+func (sc *SystemCollector) inspectContainerCLI(ctx context.Context, containerID, format string) (string, error) {
+	var output []byte
+	var err error
+	
+	for _, dockerPath := range sc.runtimeBinaries() {
+		cmd := exec.CommandContext(ctx, dockerPath, "inspect", "--format", format, containerID)
+		cmd.Env = append(os.Environ(),
+			"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		)
+		
+		output, err = cmd.Output()
 		if err == nil {
-			sizeStr := strings.TrimSpace(string(output))
-			if size, err := strconv.ParseInt(sizeStr, 10, 64); err == nil && size > 0 {
-				// Add some buffer for container filesystem
-				totalSize := size + (2 * 1024 * 1024 * 1024) // Add 2GB buffer
-				return totalSize
-			}
+			return strings.TrimSpace(string(output)), nil
 		}
 	}
 	
-	// Fallback: return default size
-	defaultSize := int64(10 * 1024 * 1024 * 1024) // Default 10GB
-	return defaultSize
-}
\ No newline at end of file
+	return "", err
+}
@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContainerNetworkSpeedFromTwoSamples(t *testing.T) {
+	advance := fakeClock(t)
+	sc := NewSystemCollector()
+
+	first := DockerStats{ID: "web", NetworkRxBytes: 10000, NetworkTxBytes: 5000}
+	sc.setContainerNetworkSpeed(&first)
+	if first.NetworkRxSpeed != 0 || first.NetworkTxSpeed != 0 {
+		t.Errorf("first sample speeds = %d, %d; want 0 without a previous sample", first.NetworkRxSpeed, first.NetworkTxSpeed)
+	}
+
+	advance(30 * time.Second)
+	second := DockerStats{ID: "web", NetworkRxBytes: 10000 + 30*400, NetworkTxBytes: 5000 + 30*100}
+	sc.setContainerNetworkSpeed(&second)
+	if second.NetworkRxSpeed != 400 || second.NetworkTxSpeed != 100 {
+		t.Errorf("speeds = %d, %d bytes/s; want 400, 100", second.NetworkRxSpeed, second.NetworkTxSpeed)
+	}
+
+	// A restarted container starts its counters over
+	advance(30 * time.Second)
+	restarted := DockerStats{ID: "web", NetworkRxBytes: 200, NetworkTxBytes: 100}
+	sc.setContainerNetworkSpeed(&restarted)
+	if restarted.NetworkRxSpeed != 0 || restarted.NetworkTxSpeed != 0 {
+		t.Errorf("speeds after a counter reset = %d, %d; want 0", restarted.NetworkRxSpeed, restarted.NetworkTxSpeed)
+	}
+
+	sc.pruneContainerNetwork([]DockerStats{{ID: "db"}})
+	if _, ok := sc.lastContainerNetwork["web"]; ok {
+		t.Error("sample of a container no longer listed was kept")
+	}
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"regexp"
+	"strings"
+)
+
+// containerFilter selects the containers that are monitored. The zero value keeps all.
+type containerFilter struct {
+	include    *regexp.Regexp // Nil matches every name
+	exclude    *regexp.Regexp // Nil excludes no name
+	labelKey   string         // Empty matches every container
+	labelValue string
+	anyValue   bool // Only the label's presence is required
+}
+
+// newContainerFilter builds a filter from DOCKER_INCLUDE_PATTERN, DOCKER_EXCLUDE_PATTERN and
+// DOCKER_LABEL_FILTER. Patterns that don't compile are dropped.
+func newContainerFilter(include, exclude, label string) containerFilter {
+	var filter containerFilter
+	if include != "" {
+		filter.include, _ = regexp.Compile(include)
+	}
+	if exclude != "" {
+		filter.exclude, _ = regexp.Compile(exclude)
+	}
+
+	key, value, hasValue := strings.Cut(strings.TrimSpace(label), "=")
+	filter.labelKey = strings.TrimSpace(key)
+	filter.labelValue = strings.TrimSpace(value)
+	filter.anyValue = !hasValue
+	return filter
+}
+
+// matches reports whether a container with this name and these labels is monitored
+func (f containerFilter) matches(name string, labels map[string]string) bool {
+	if f.include != nil && !f.include.MatchString(name) {
+		return false
+	}
+	if f.exclude != nil && f.exclude.MatchString(name) {
+		return false
+	}
+	if f.labelKey != "" {
+		value, ok := labels[f.labelKey]
+		if !ok || (!f.anyValue && value != f.labelValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterContainers drops the containers the configured filter doesn't select
+func (sc *SystemCollector) filterContainers(containers []DockerStats) []DockerStats {
+	sc.mu.Lock()
+	filter := sc.containerFilter
+	sc.mu.Unlock()
+
+	filtered := containers[:0]
+	for _, container := range containers {
+		if filter.matches(container.Name, container.Labels) {
+			filtered = append(filtered, container)
+		}
+	}
+	return filtered
+}
+
+// parseDockerLabels parses the docker ps {{.Labels}} output, comma-separated key=value pairs.
+// Values containing commas can't be told apart from the next pair and are cut short.
+func parseDockerLabels(labels string) map[string]string {
+	if labels == "" {
+		return nil
+	}
+
+	parsed := make(map[string]string)
+	for _, pair := range strings.Split(labels, ",") {
+		key, value, _ := strings.Cut(pair, "=")
+		if key = strings.TrimSpace(key); key != "" {
+			parsed[key] = value
+		}
+	}
+	return parsed
+}
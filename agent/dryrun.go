@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"monitoring-agent/config"
+	pbClient "monitoring-agent/pocketbase"
+)
+
+// RunDryRun collects metrics once and writes the server and server_metrics records the
+// agent would send as indented JSON. Nothing is registered or pushed, alerts aren't sent
+// and no state is persisted.
+func RunDryRun(cfg *config.Config, w io.Writer) error {
+	a := &Agent{
+		dryRun:        true,
+		lastCoreDumps: -1,
+		collector:     NewSystemCollector(),
+	}
+	a.liveConfig.Store(cfg)
+	a.configureCollector()
+	a.selfCPU.ticks, _, _ = readSelfProcStat()
+	a.selfCPU.time = time.Now()
+
+	// Stand-in for the PocketBase record; Docker is enabled so container data is collected
+	record := &pbClient.ServerRecord{
+		Docker:        pbClient.FlexibleBool{Value: true},
+		CheckInterval: pbClient.FlexibleInt{Value: int(cfg.CheckInterval.Seconds())},
+	}
+	server := a.gatherServerMetrics(context.Background(), record, false)
+	if server.ServerToken != "" {
+		server.ServerToken = "<redacted>"
+	}
+	metrics := a.gatherDetailedServerMetrics(context.Background())
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(map[string]interface{}{
+		"server":         server,
+		"server_metrics": metrics,
+	})
+}
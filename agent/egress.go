@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"monitoring-agent/logging"
+)
+
+// egressState is the outbound byte accumulation persisted across agent restarts
+type egressState struct {
+	PeriodStart time.Time `json:"period_start"`
+	Bytes       uint64    `json:"bytes"`        // Outbound bytes accumulated in the current period
+	LastCounter uint64    `json:"last_counter"` // Interface TX total seen on the previous cycle
+}
+
+// egressPeriodStart returns the start of the billing period containing now,
+// which begins at midnight on resetDay of each month
+func egressPeriodStart(now time.Time, resetDay int) time.Time {
+	if resetDay < 1 || resetDay > 28 {
+		resetDay = 1
+	}
+	start := time.Date(now.Year(), now.Month(), resetDay, 0, 0, 0, 0, now.Location())
+	if now.Before(start) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start
+}
+
+// updateEgressUsage adds the TX bytes sent since the previous cycle to the current period
+// and persists the total. txCounter is the host's cumulative TX byte count.
+func (a *Agent) updateEgressUsage(txCounter uint64, now time.Time) egressState {
+	statePath := filepath.Join(a.config().StateDir, "egress_usage.json")
+
+	if a.egress == nil {
+		a.egress = &egressState{}
+		if data, err := os.ReadFile(statePath); err == nil {
+			if err := json.Unmarshal(data, a.egress); err != nil {
+				logging.Warnf("Ignoring unreadable egress state %s: %v", statePath, err)
+				a.egress = &egressState{}
+			}
+		}
+	}
+	state := a.egress
+
+	// The first observation only establishes a baseline; counters restart from zero after a reboot
+	switch {
+	case state.LastCounter == 0:
+	case txCounter >= state.LastCounter:
+		state.Bytes += txCounter - state.LastCounter
+	default:
+		state.Bytes += txCounter
+	}
+	state.LastCounter = txCounter
+
+	periodStart := egressPeriodStart(now, a.config().EgressResetDay)
+	if !state.PeriodStart.Equal(periodStart) {
+		if !state.PeriodStart.IsZero() {
+			logging.Infof("Egress period rolled over, %d bytes sent since %s", state.Bytes, state.PeriodStart.Format("2006-01-02"))
+		}
+		state.PeriodStart = periodStart
+		state.Bytes = 0
+	}
+
+	if a.dryRun {
+		return *state
+	}
+	if data, err := json.Marshal(state); err == nil {
+		if err := os.MkdirAll(a.config().StateDir, 0755); err != nil {
+			logging.Warnf("Could not create state directory %s: %v", a.config().StateDir, err)
+		} else if err := os.WriteFile(statePath, data, 0644); err != nil {
+			logging.Warnf("Could not persist egress usage: %v", err)
+		}
+	}
+
+	return *state
+}
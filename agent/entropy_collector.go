@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EntropyInfo describes the kernel entropy pool and the hardware RNG backing it
+type EntropyInfo struct {
+	Available    int64  // Bits currently in the pool
+	PoolSize     int64  // Pool capacity in bits
+	HWRNGSource  string // Current hardware RNG, empty when none is bound
+	Virtualized  bool   // Host runs under a hypervisor
+	SoftwareOnly bool   // No hardware RNG feeds the pool
+}
+
+// getEntropyInfo reads the entropy pool and hardware RNG state from /proc and /sys
+func (sc *SystemCollector) getEntropyInfo() EntropyInfo {
+	info := EntropyInfo{
+		Available:   readIntFile("/proc/sys/kernel/random/entropy_avail"),
+		PoolSize:    readIntFile("/proc/sys/kernel/random/poolsize"),
+		Virtualized: sc.isVirtualized(),
+	}
+
+	// rng_current reports "none" when no hardware source is bound
+	if data, err := os.ReadFile("/sys/class/misc/hw_random/rng_current"); err == nil {
+		source := strings.TrimSpace(string(data))
+		if source != "" && source != "none" {
+			info.HWRNGSource = source
+		}
+	}
+	info.SoftwareOnly = info.HWRNGSource == ""
+
+	return info
+}
+
+// isVirtualized checks the CPU flags for the hypervisor bit
+func (sc *SystemCollector) isVirtualized() bool {
+	data, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "flags") {
+			for _, flag := range strings.Fields(line) {
+				if flag == "hypervisor" {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	return false
+}
+
+// readIntFile reads a single integer value from a file, returning 0 on failure
+func readIntFile(path string) int64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	value, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
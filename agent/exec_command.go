@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"monitoring-agent/logging"
+	pbClient "monitoring-agent/pocketbase"
+)
+
+const (
+	execCommandTimeout = 60 * time.Second
+	maxExecOutput      = 64 * 1024 // Bytes of stdout and of stderr kept for the command record
+)
+
+// runExec runs the "command" parameter if it exactly matches a REMOTE_EXEC_ALLOWLIST entry.
+// The command line is split on whitespace and run without a shell, so an allowed entry
+// can't be extended with arguments, pipes or substitutions. Rejections are reported in the
+// result rather than as an error so the command isn't retried.
+func (a *Agent) runExec(parameters map[string]string) *pbClient.CommandResult {
+	args := strings.Fields(parameters["command"])
+	commandLine := strings.Join(args, " ")
+	if len(args) == 0 || !a.execAllowed(commandLine) {
+		logging.Warnf("Rejected remote exec of %q: not in REMOTE_EXEC_ALLOWLIST", commandLine)
+		return &pbClient.CommandResult{Result: "rejected: command is not in REMOTE_EXEC_ALLOWLIST"}
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, execCommandTimeout)
+	defer cancel()
+
+	stdout := &cappedBuffer{limit: maxExecOutput}
+	stderr := &cappedBuffer{limit: maxExecOutput}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Don't wait on children that inherited stdout after the command itself was killed
+	cmd.WaitDelay = time.Second
+
+	logging.Infof("Running remote exec: %s", commandLine)
+	err := cmd.Run()
+
+	exitCode := 0
+	result := &pbClient.CommandResult{Stdout: stdout.String(), Stderr: stderr.String(), ExitCode: &exitCode}
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		exitCode = -1
+		result.Result = fmt.Sprintf("timed out after %v", execCommandTimeout)
+	case errors.As(err, &exitErr):
+		exitCode = exitErr.ExitCode()
+		result.Result = fmt.Sprintf("exited with code %d", exitCode)
+	case err != nil:
+		exitCode = -1
+		result.Result = err.Error()
+	default:
+		result.Result = "exited with code 0"
+	}
+
+	logging.Infof("Remote exec %q %s", commandLine, result.Result)
+	return result
+}
+
+// execAllowed reports whether commandLine is on the allowlist, which is empty by default
+func (a *Agent) execAllowed(commandLine string) bool {
+	for _, allowed := range a.config().RemoteExecAllowlist {
+		if strings.Join(strings.Fields(allowed), " ") == commandLine {
+			return true
+		}
+	}
+	return false
+}
+
+// cappedBuffer keeps the first limit bytes written to it and drops the rest
+type cappedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+func (b *cappedBuffer) String() string {
+	if b.truncated {
+		return b.Buffer.String() + "\n[output truncated]"
+	}
+	return b.Buffer.String()
+}
@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"monitoring-agent/config"
+)
+
+func TestRunExecDoesNotWaitOnOrphanedChildren(t *testing.T) {
+	// The background sleep inherits stdout and outlives the killed script
+	script := filepath.Join(t.TempDir(), "spawn.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsleep 10 &\nsleep 10\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	a := newTestAgent(&config.Config{RemoteExecAllowlist: []string{script}})
+	ctx, cancel := context.WithCancel(context.Background())
+	a.ctx = ctx
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	result := a.runExec(map[string]string{"command": script})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("runExec returned after %v, want it not to wait for the orphaned sleep", elapsed)
+	}
+	if result.ExitCode == nil || *result.ExitCode == 0 {
+		t.Errorf("result = %+v, want the killed command's failure", result)
+	}
+}
@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"context"
+
+	"monitoring-agent/logging"
+	pbClient "monitoring-agent/pocketbase"
+)
+
+// extraServer is a further AGENT_ID entry, e.g. a VM or endpoint behind this agent. It is
+// registered and reported under its own server record but shares this host's metrics.
+type extraServer struct {
+	agentID string
+	record  *pbClient.ServerRecord // Nil until registration succeeds, guarded by Agent.extraMutex
+}
+
+// initializeExtraServers registers the AGENT_ID entries after the first. Entries that
+// fail to register are retried on every push.
+func (a *Agent) initializeExtraServers() {
+	var servers []*extraServer
+	for _, agentID := range a.config().AgentIDs {
+		if agentID == a.config().AgentID {
+			continue
+		}
+
+		server := &extraServer{agentID: agentID}
+		servers = append(servers, server)
+
+		// Additional servers are named after their ID, rename them from the dashboard
+		record, err := a.findOrCreateServerRecord(agentID, agentID)
+		if err != nil {
+			logging.Errorf("Failed to initialize server record for %s: %v", agentID, err)
+			continue
+		}
+		server.record = record
+		if record.Status == "paused" {
+			logging.Infof("Server %s is currently paused", agentID)
+		}
+	}
+
+	a.extraMutex.Lock()
+	a.extraServers = servers
+	a.extraMutex.Unlock()
+}
+
+// extraServerRecords returns a snapshot of the extra servers and their records, so callers
+// don't hold extraMutex across PocketBase requests
+func (a *Agent) extraServerRecords() []extraServer {
+	a.extraMutex.Lock()
+	defer a.extraMutex.Unlock()
+
+	servers := make([]extraServer, len(a.extraServers))
+	for i, server := range a.extraServers {
+		servers[i] = *server
+	}
+	return servers
+}
+
+// setExtraServerRecord stores the latest record fetched for an extra server
+func (a *Agent) setExtraServerRecord(agentID string, record *pbClient.ServerRecord) {
+	a.extraMutex.Lock()
+	defer a.extraMutex.Unlock()
+
+	for _, server := range a.extraServers {
+		if server.agentID == agentID {
+			server.record = record
+		}
+	}
+}
+
+// hasActiveExtraServers reports whether any extra server still receives metrics, i.e. is
+// not paused or hasn't registered yet
+func (a *Agent) hasActiveExtraServers() bool {
+	for _, server := range a.extraServerRecords() {
+		if server.record == nil || server.record.Status != "paused" {
+			return true
+		}
+	}
+	return false
+}
+
+// pollExtraServers refreshes the records of the registered extra servers, so pausing or
+// resuming one from the dashboard takes effect. It runs on the status ticker along with
+// checkServerStatus instead of before every push.
+func (a *Agent) pollExtraServers() {
+	for _, server := range a.extraServerRecords() {
+		if server.record == nil {
+			continue // Registered by the next push
+		}
+
+		current, err := a.pocketBase.GetServerByID(a.ctx, server.agentID)
+		if err != nil {
+			logging.Errorf("Failed to fetch server status for %s: %v", server.agentID, err)
+			continue
+		}
+		a.setExtraServerRecord(server.agentID, current)
+
+		// Polled often while the primary server is paused, so only log the transition
+		wasPaused, isPaused := server.record.Status == "paused", current.Status == "paused"
+		if isPaused && !wasPaused {
+			logging.Infof("Server %s is paused, skipping it", server.agentID)
+		} else if wasPaused && !isPaused {
+			logging.Infof("Server %s monitoring resumed", server.agentID)
+		}
+	}
+}
+
+// pushExtraServers writes the cycle's server and detailed metrics to the record of every
+// extra server that isn't paused. It returns the collections that failed.
+func (a *Agent) pushExtraServers(ctx context.Context, serverMetrics pbClient.ServerRecord, detailedMetrics pbClient.ServerMetricsRecord) []string {
+	var failed []string
+	for _, server := range a.extraServerRecords() {
+		if server.record == nil {
+			record, err := a.findOrCreateServerRecord(server.agentID, server.agentID)
+			if err != nil {
+				logging.Errorf("Failed to initialize server record for %s: %v", server.agentID, err)
+				a.recordError("servers", err)
+				failed = append(failed, "servers ("+server.agentID+")")
+				continue
+			}
+			a.setExtraServerRecord(server.agentID, record)
+			server.record = record
+		}
+
+		if server.record.Status == "paused" {
+			logging.Debugf("Server %s is paused, skipping it", server.agentID)
+			continue
+		}
+
+		record := serverMetrics
+		record.ID = server.record.ID
+		record.ServerID = server.agentID
+		record.Name = server.record.Name
+		record.Docker = server.record.Docker
+		record.CheckInterval = server.record.CheckInterval
+		if err := a.pocketBase.UpdateServerStatus(ctx, server.record.ID, record); err != nil {
+			logging.Errorf("Failed to update server record for %s: %v", server.agentID, err)
+			a.recordError("servers", err)
+			failed = append(failed, "servers ("+server.agentID+")")
+		}
+
+		metrics := detailedMetrics
+		metrics.ServerID = server.agentID
+		if err := a.sendDetailedServerMetrics(ctx, metrics); err != nil {
+			logging.Errorf("Failed to send detailed server metrics for %s: %v", server.agentID, err)
+			a.recordError("server_metrics", err)
+			failed = append(failed, "server_metrics ("+server.agentID+")")
+		}
+	}
+	return failed
+}
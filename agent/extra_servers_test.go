@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"monitoring-agent/config"
+	pbClient "monitoring-agent/pocketbase"
+)
+
+// fakeServers is a PocketBase servers collection whose records' status the test changes
+type fakeServers struct {
+	mu      sync.Mutex
+	status  map[string]string // Status per server_id
+	gets    int
+	patched []string // Record IDs updated with metrics
+}
+
+func (f *fakeServers) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/collections/servers/"):
+		f.gets++
+		var items []pbClient.ServerRecord
+		for id, status := range f.status {
+			if strings.Contains(r.URL.Query().Get("filter"), "'"+id+"'") {
+				items = append(items, pbClient.ServerRecord{ID: "rec-" + id, ServerID: id, Status: status})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	case r.Method == http.MethodPatch:
+		f.patched = append(f.patched, strings.TrimPrefix(r.URL.Path, "/api/collections/servers/records/"))
+		w.Write([]byte("{}"))
+	default:
+		w.Write([]byte("{}"))
+	}
+}
+
+func (f *fakeServers) setStatus(id, status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status[id] = status
+}
+
+// requests returns the GETs and record updates seen since the last call
+func (f *fakeServers) requests() (int, []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	gets, patched := f.gets, f.patched
+	f.gets, f.patched = 0, nil
+	return gets, patched
+}
+
+func TestExtraServersPausedFromStatusPoll(t *testing.T) {
+	fake := &fakeServers{status: map[string]string{"vm-1": "up", "vm-2": "paused"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	pb, err := pbClient.NewPocketBaseClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestAgent(&config.Config{AgentID: "host", AgentIDs: []string{"host", "vm-1", "vm-2"}})
+	a.pocketBase = pb
+	a.initializeExtraServers()
+	fake.requests()
+
+	push := func() []string {
+		if failed := a.pushExtraServers(a.ctx, pbClient.ServerRecord{}, pbClient.ServerMetricsRecord{}); len(failed) > 0 {
+			t.Fatalf("pushExtraServers failed for %v", failed)
+		}
+		gets, patched := fake.requests()
+		if gets != 0 {
+			t.Errorf("push fetched server records %d times, want the status poll to do that", gets)
+		}
+		return patched
+	}
+
+	if patched := push(); len(patched) != 1 || patched[0] != "rec-vm-1" {
+		t.Errorf("updated records %v, want only rec-vm-1 while vm-2 is paused", patched)
+	}
+	if !a.hasActiveExtraServers() {
+		t.Error("hasActiveExtraServers = false with vm-1 running")
+	}
+
+	// Pausing only takes effect with the next status poll
+	fake.setStatus("vm-1", "paused")
+	fake.setStatus("vm-2", "up")
+	a.pollExtraServers()
+	if gets, _ := fake.requests(); gets != 2 {
+		t.Errorf("status poll fetched %d server records, want 2", gets)
+	}
+	if patched := push(); len(patched) != 1 || patched[0] != "rec-vm-2" {
+		t.Errorf("updated records %v, want only rec-vm-2 after the poll", patched)
+	}
+
+	fake.setStatus("vm-2", "paused")
+	a.pollExtraServers()
+	fake.requests()
+	if a.hasActiveExtraServers() {
+		t.Error("hasActiveExtraServers = true with every extra server paused")
+	}
+}
@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// getFileHandleUsage reads system-wide open file handles and the limit from /proc/sys/fs/file-nr
+func (sc *SystemCollector) getFileHandleUsage() (used int64, max int64, percentage float64) {
+	data, err := os.ReadFile("/proc/sys/fs/file-nr")
+	if err != nil {
+		return 0, 0, 0
+	}
+
+	// Format: allocated, allocated-but-unused, maximum
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return 0, 0, 0
+	}
+
+	allocated, err1 := strconv.ParseInt(fields[0], 10, 64)
+	unused, err2 := strconv.ParseInt(fields[1], 10, 64)
+	max, err3 := strconv.ParseInt(fields[2], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, 0, 0
+	}
+
+	used = allocated - unused
+	if max > 0 {
+		percentage = float64(used) / float64(max) * 100.0
+	}
+
+	return used, max, percentage
+}
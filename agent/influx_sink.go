@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitoring-agent/config"
+	"monitoring-agent/logging"
+)
+
+// InfluxSink writes summary metrics to an InfluxDB v2 bucket as line protocol when
+// TRANSPORT=influxdb, so the agent can feed Influx+Grafana without PocketBase.
+type InfluxSink struct {
+	ctx        context.Context
+	httpClient *http.Client
+	collector  *SystemCollector // Memory and disk totals aren't part of SystemMetrics
+	writeURL   string
+	token      string
+	tags       string // Pre-escaped ",agent_id=...,host=..." shared by every point
+}
+
+var _ metricsSink = (*InfluxSink)(nil)
+
+// NewInfluxSink builds the sink for INFLUXDB_URL, writing to INFLUXDB_BUCKET in INFLUXDB_ORG
+func NewInfluxSink(ctx context.Context, cfg *config.Config, collector *SystemCollector, httpClient *http.Client) (*InfluxSink, error) {
+	base, err := url.Parse(strings.TrimSuffix(cfg.InfluxDBURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INFLUXDB_URL: %v", err)
+	}
+
+	query := url.Values{}
+	query.Set("bucket", cfg.InfluxDBBucket)
+	if cfg.InfluxDBOrg != "" {
+		query.Set("org", cfg.InfluxDBOrg)
+	}
+	query.Set("precision", "ns")
+	base.Path += "/api/v2/write"
+	base.RawQuery = query.Encode()
+
+	return &InfluxSink{
+		ctx:        ctx,
+		httpClient: httpClient,
+		collector:  collector,
+		writeURL:   base.String(),
+		token:      cfg.InfluxDBToken,
+		tags:       ",agent_id=" + escapeInfluxTag(cfg.AgentID) + ",host=" + escapeInfluxTag(cfg.Hostname),
+	}, nil
+}
+
+// SendMetrics writes one cpu, mem, disk, net and system point for the sample
+func (s *InfluxSink) SendMetrics(metrics SystemMetrics) error {
+	req, err := http.NewRequestWithContext(s.ctx, "POST", s.writeURL, strings.NewReader(s.lineProtocol(metrics)))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		// InfluxDB explains rejected writes in a JSON body
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("InfluxDB returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	logging.Debugf("Successfully wrote metrics to InfluxDB at %s", metrics.Timestamp.Format(time.RFC3339))
+	return nil
+}
+
+// lineProtocol renders the sample as InfluxDB line protocol, one measurement per line
+func (s *InfluxSink) lineProtocol(metrics SystemMetrics) string {
+	memUsed, memTotal, memPercent := s.collector.GetMemoryUsage()
+	diskUsed, diskTotal, diskPercent := s.collector.GetDiskUsage()
+	net := metrics.NetworkStats
+	timestamp := strconv.FormatInt(metrics.Timestamp.UnixNano(), 10)
+
+	var b strings.Builder
+	writePoint := func(measurement string, fields ...string) {
+		b.WriteString(measurement)
+		b.WriteString(s.tags)
+		b.WriteByte(' ')
+		b.WriteString(strings.Join(fields, ","))
+		b.WriteByte(' ')
+		b.WriteString(timestamp)
+		b.WriteByte('\n')
+	}
+
+	writePoint("cpu", floatField("usage_percent", metrics.CPUUsage))
+	writePoint("mem", intField("used_bytes", memUsed), intField("total_bytes", memTotal), floatField("used_percent", memPercent))
+	writePoint("disk", intField("used_bytes", diskUsed), intField("total_bytes", diskTotal), floatField("used_percent", diskPercent))
+	writePoint("net",
+		intField("bytes_sent", int64(net.BytesSent)),
+		intField("bytes_recv", int64(net.BytesReceived)),
+		intField("packets_sent", int64(net.PacketsSent)),
+		intField("packets_recv", int64(net.PacketsReceived)),
+		intField("rx_bytes_per_sec", int64(net.RxSpeed)),
+		intField("tx_bytes_per_sec", int64(net.TxSpeed)),
+	)
+	writePoint("system", intField("uptime_seconds", metrics.Uptime))
+
+	return b.String()
+}
+
+// escapeInfluxTag escapes the characters line protocol treats specially in tag keys and values
+func escapeInfluxTag(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `).Replace(value)
+}
+
+func floatField(name string, value float64) string {
+	return name + "=" + strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func intField(name string, value int64) string {
+	return name + "=" + strconv.FormatInt(value, 10) + "i"
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LinkState describes the operational state and negotiated speed of a network interface
+type LinkState struct {
+	Interface string
+	OperState string // up, down, dormant, unknown...
+	SpeedMbps int64  // Negotiated speed, 0 when unknown or the link is down
+}
+
+// getLinkStates reads operstate and speed for physical and bonded interfaces from /sys/class/net
+func (sc *SystemCollector) getLinkStates() []LinkState {
+	links := []LinkState{}
+
+	entries, err := os.ReadDir("/sys/class/net")
+	if err != nil {
+		return links
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		ifacePath := filepath.Join("/sys/class/net", name)
+
+		// Only physical NICs (backed by a device) and bonds have a meaningful link speed
+		if !pathExists(filepath.Join(ifacePath, "device")) && !pathExists(filepath.Join(ifacePath, "bonding")) {
+			continue
+		}
+
+		link := LinkState{
+			Interface: name,
+			OperState: "unknown",
+		}
+		if data, err := os.ReadFile(filepath.Join(ifacePath, "operstate")); err == nil {
+			link.OperState = strings.TrimSpace(string(data))
+		}
+
+		// Reading speed fails or returns -1 while the link is down
+		if speed := readIntFile(filepath.Join(ifacePath, "speed")); speed > 0 {
+			link.SpeedMbps = speed
+		}
+
+		links = append(links, link)
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].Interface < links[j].Interface
+	})
+
+	return links
+}
+
+// pathExists reports whether a file or directory exists
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// getLoadAverage reads the 1, 5 and 15 minute load averages from /proc/loadavg
+func (sc *SystemCollector) getLoadAverage() (float64, float64, float64) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, 0, 0
+	}
+
+	return parseLoadAverage(string(data))
+}
+
+// parseLoadAverage parses the contents of /proc/loadavg like "0.52 0.58 0.59 1/467 12345"
+func parseLoadAverage(data string) (float64, float64, float64) {
+	fields := strings.Fields(data)
+	if len(fields) < 3 {
+		return 0, 0, 0
+	}
+
+	load1, err1 := strconv.ParseFloat(fields[0], 64)
+	load5, err2 := strconv.ParseFloat(fields[1], 64)
+	load15, err3 := strconv.ParseFloat(fields[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, 0, 0
+	}
+
+	return load1, load5, load15
+}
+
+// getTaskCounts returns the number of processes, counted from the numeric /proc entries,
+// and the number of threads from the "running/total" field of /proc/loadavg
+func (sc *SystemCollector) getTaskCounts() (processes int, threads int) {
+	if entries, err := os.ReadDir("/proc"); err == nil {
+		for _, entry := range entries {
+			if _, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+				processes++
+			}
+		}
+	}
+
+	if data, err := os.ReadFile("/proc/loadavg"); err == nil {
+		if fields := strings.Fields(string(data)); len(fields) >= 4 {
+			if _, total, ok := strings.Cut(fields[3], "/"); ok {
+				threads, _ = strconv.Atoi(total)
+			}
+		}
+	}
+
+	return processes, threads
+}
@@ -0,0 +1,29 @@
+package agent
+
+import "testing"
+
+func TestParseLoadAverage(t *testing.T) {
+	tests := []struct {
+		name                 string
+		data                 string
+		load1, load5, load15 float64
+	}{
+		{"typical", "0.52 0.58 0.59 1/467 12345\n", 0.52, 0.58, 0.59},
+		{"busy host", "12.00 8.25 4.10 9/2048 999999", 12, 8.25, 4.1},
+		{"idle", "0.00 0.00 0.00 1/80 42", 0, 0, 0},
+		{"only load fields", "1.5 2.5 3.5", 1.5, 2.5, 3.5},
+		{"too few fields", "0.52 0.58", 0, 0, 0},
+		{"empty", "", 0, 0, 0},
+		{"not a number", "0.52 abc 0.59 1/467 12345", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			load1, load5, load15 := parseLoadAverage(tt.data)
+			if load1 != tt.load1 || load5 != tt.load5 || load15 != tt.load15 {
+				t.Errorf("parseLoadAverage(%q) = %v, %v, %v; want %v, %v, %v",
+					tt.data, load1, load5, load15, tt.load1, tt.load5, tt.load15)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// getTimezone returns the host's configured IANA timezone with its current UTC offset,
+// e.g. "Europe/Berlin (UTC+02:00)"
+func (sc *SystemCollector) getTimezone() string {
+	name := ""
+
+	// TZ overrides the system zone for the agent process
+	if tz := os.Getenv("TZ"); tz != "" {
+		name = strings.TrimPrefix(tz, ":")
+	} else if data, err := os.ReadFile("/etc/timezone"); err == nil {
+		name = strings.TrimSpace(string(data))
+	} else if target, err := filepath.EvalSymlinks("/etc/localtime"); err == nil {
+		if idx := strings.Index(target, "zoneinfo/"); idx >= 0 {
+			name = target[idx+len("zoneinfo/"):]
+		}
+	}
+
+	zoneAbbrev, _ := time.Now().Zone()
+	if name == "" {
+		name = zoneAbbrev
+	}
+
+	return name + " (UTC" + time.Now().Format("-07:00") + ")"
+}
+
+// getLocale returns the agent process's effective locale following POSIX precedence,
+// "C" when nothing is set
+func (sc *SystemCollector) getLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return "C"
+}
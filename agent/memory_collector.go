@@ -2,11 +2,7 @@
 package agent
 
 import (
-	"bufio"
-	"os"
 	"runtime"
-	"strconv"
-	"strings"
 )
 
 // getMemoryUsage returns memory usage in bytes and percentage
@@ -29,29 +25,46 @@ func (sc *SystemCollector) getMemoryUsage() (used int64, total int64, percentage
 	return used, total, percentage
 }
 
-// getMemInfo reads memory information from /proc/meminfo
-func (sc *SystemCollector) getMemInfo() (map[string]int64, error) {
-	file, err := os.Open("/proc/meminfo")
+// getSwapUsage returns swap usage in bytes and percentage
+func (sc *SystemCollector) getSwapUsage() (used int64, total int64, percentage float64) {
+	memInfo, err := sc.getMemInfo()
 	if err != nil {
-		return nil, err
+		return 0, 0, 0
 	}
-	defer file.Close()
 
-	memInfo := make(map[string]int64)
-	scanner := bufio.NewScanner(file)
-	
-	for scanner.Scan() {
-		line := scanner.Text()
-		fields := strings.Fields(line)
-		if len(fields) >= 2 {
-			key := strings.TrimSuffix(fields[0], ":")
-			value, err := strconv.ParseInt(fields[1], 10, 64)
-			if err == nil {
-				// Convert from KB to bytes
-				memInfo[key] = value * 1024
-			}
-		}
+	total = memInfo["SwapTotal"]
+	if total == 0 {
+		// Swap is disabled
+		return 0, 0, 0
 	}
 
-	return memInfo, scanner.Err()
-}
\ No newline at end of file
+	used = total - memInfo["SwapFree"]
+	percentage = float64(used) / float64(total) * 100.0
+
+	return used, total, percentage
+}
+
+// HugePagesInfo describes huge page allocation from /proc/meminfo
+type HugePagesInfo struct {
+	Total    int64 // Pages
+	Free     int64 // Pages
+	Reserved int64 // Pages
+	Surplus  int64 // Pages
+	PageSize int64 // Bytes
+}
+
+// getHugePages returns huge page usage, all zeros when none are configured
+func (sc *SystemCollector) getHugePages() HugePagesInfo {
+	memInfo, err := sc.getMemInfo()
+	if err != nil || memInfo["HugePages_Total"] == 0 {
+		return HugePagesInfo{}
+	}
+
+	return HugePagesInfo{
+		Total:    memInfo["HugePages_Total"],
+		Free:     memInfo["HugePages_Free"],
+		Reserved: memInfo["HugePages_Rsvd"],
+		Surplus:  memInfo["HugePages_Surp"],
+		PageSize: memInfo["Hugepagesize"],
+	}
+}
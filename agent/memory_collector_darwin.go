@@ -0,0 +1,54 @@
+//go:build darwin && cgo
+
+package agent
+
+/*
+#include <mach/mach.h>
+#include <mach/mach_host.h>
+*/
+import "C"
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// getMemInfo reads memory information from host_statistics64 and sysctl, keyed like /proc/meminfo
+func (sc *SystemCollector) getMemInfo() (map[string]int64, error) {
+	total, err := unix.SysctlUint64("hw.memsize")
+	if err != nil {
+		return nil, err
+	}
+	pageSize, err := sysctlUint64("hw.pagesize")
+	if err != nil {
+		return nil, err
+	}
+
+	var vmStat C.vm_statistics64_data_t
+	count := C.mach_msg_type_number_t(C.HOST_VM_INFO64_COUNT)
+	ret := C.host_statistics64(machHost, C.HOST_VM_INFO64, C.host_info64_t(unsafe.Pointer(&vmStat)), &count)
+	if ret != C.KERN_SUCCESS {
+		return nil, fmt.Errorf("host_statistics64 failed with %d", ret)
+	}
+
+	free := uint64(vmStat.free_count) * pageSize
+	// Inactive pages hold reclaimable file cache, like Linux's MemAvailable counts it
+	available := free + uint64(vmStat.inactive_count)*pageSize
+
+	memInfo := map[string]int64{
+		"MemTotal":     int64(total),
+		"MemFree":      int64(free),
+		"MemAvailable": int64(available),
+	}
+
+	// vm.swapusage is a struct xsw_usage: total, avail and used as 64-bit byte counts
+	if swap, err := unix.SysctlRaw("vm.swapusage"); err == nil && len(swap) >= 16 {
+		memInfo["SwapTotal"] = int64(binary.NativeEndian.Uint64(swap[0:8]))
+		memInfo["SwapFree"] = int64(binary.NativeEndian.Uint64(swap[8:16]))
+	}
+
+	return memInfo, nil
+}
@@ -0,0 +1,27 @@
+package agent
+
+// getMemInfo reads memory information from the vm.stats sysctls, keyed like /proc/meminfo
+func (sc *SystemCollector) getMemInfo() (map[string]int64, error) {
+	pageSize, err := sysctlUint64("hw.pagesize")
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make(map[string]uint64)
+	for _, name := range []string{"v_page_count", "v_free_count", "v_inactive_count"} {
+		count, err := sysctlUint64("vm.stats.vm." + name)
+		if err != nil {
+			return nil, err
+		}
+		pages[name] = count
+	}
+
+	// Inactive pages hold reclaimable file cache, like Linux's MemAvailable counts it
+	memInfo := map[string]int64{
+		"MemTotal":     int64(pages["v_page_count"] * pageSize),
+		"MemFree":      int64(pages["v_free_count"] * pageSize),
+		"MemAvailable": int64((pages["v_free_count"] + pages["v_inactive_count"]) * pageSize),
+	}
+
+	return memInfo, nil
+}
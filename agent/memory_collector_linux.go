@@ -0,0 +1,40 @@
+//go:build linux
+
+package agent
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// getMemInfo reads memory information from /proc/meminfo
+func (sc *SystemCollector) getMemInfo() (map[string]int64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	memInfo := make(map[string]int64)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) >= 2 {
+			key := strings.TrimSuffix(fields[0], ":")
+			value, err := strconv.ParseInt(fields[1], 10, 64)
+			if err == nil {
+				// Convert from KB to bytes; unitless entries like HugePages_Total are counts
+				if len(fields) >= 3 && fields[2] == "kB" {
+					value *= 1024
+				}
+				memInfo[key] = value
+			}
+		}
+	}
+
+	return memInfo, scanner.Err()
+}
@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// memoryStatusEx mirrors MEMORYSTATUSEX
+type memoryStatusEx struct {
+	Length               uint32
+	MemoryLoad           uint32
+	TotalPhys            uint64
+	AvailPhys            uint64
+	TotalPageFile        uint64
+	AvailPageFile        uint64
+	TotalVirtual         uint64
+	AvailVirtual         uint64
+	AvailExtendedVirtual uint64
+}
+
+// getMemInfo reads memory information from GlobalMemoryStatusEx, keyed like /proc/meminfo
+func (sc *SystemCollector) getMemInfo() (map[string]int64, error) {
+	status := memoryStatusEx{}
+	status.Length = uint32(unsafe.Sizeof(status))
+
+	r, _, err := procGlobalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&status)))
+	if r == 0 {
+		return nil, fmt.Errorf("GlobalMemoryStatusEx failed: %v", err)
+	}
+
+	memInfo := map[string]int64{
+		"MemTotal":     int64(status.TotalPhys),
+		"MemAvailable": int64(status.AvailPhys),
+		"MemFree":      int64(status.AvailPhys),
+	}
+
+	// The commit limit is physical memory plus the page files, so the difference is the page file size
+	if status.TotalPageFile > status.TotalPhys {
+		memInfo["SwapTotal"] = int64(status.TotalPageFile - status.TotalPhys)
+		if status.AvailPageFile > status.AvailPhys {
+			memInfo["SwapFree"] = int64(status.AvailPageFile - status.AvailPhys)
+		}
+	}
+
+	return memInfo, nil
+}
@@ -10,6 +10,12 @@ import (
 	"time"
 )
 
+// procNetDev is the interface counter file, replaced in tests with recorded samples
+var procNetDev = "/proc/net/dev"
+
+// timeNow timestamps network samples, replaced in tests to control the interval between them
+var timeNow = time.Now
+
 // getNetworkStats returns real network statistics for the main physical interface
 func (sc *SystemCollector) getNetworkStats() NetworkStats {
 	currentStats, err := sc.getNetworkInfo()
@@ -23,7 +29,10 @@ func (sc *SystemCollector) getNetworkStats() NetworkStats {
 		}
 	}
 
-	now := time.Now()
+	sc.deltaMu.Lock()
+	defer sc.deltaMu.Unlock()
+
+	now := timeNow()
 	
 	// Calculate speed if we have previous data
 	var rxSpeed, txSpeed uint64
@@ -51,13 +60,20 @@ func (sc *SystemCollector) getNetworkStats() NetworkStats {
 	return NetworkStats{
 		BytesReceived:   currentStats.BytesReceived,
 		BytesSent:       currentStats.BytesSent,
-		PacketsReceived: rxSpeed, // Use calculated RX speed
-		PacketsSent:     txSpeed, // Use calculated TX speed
+		PacketsReceived: currentStats.PacketsReceived,
+		PacketsSent:     currentStats.PacketsSent,
+		RxSpeed:         rxSpeed,
+		TxSpeed:         txSpeed,
 	}
 }
 
 // getMainNetworkInterface identifies the main physical network interface
 func (sc *SystemCollector) getMainNetworkInterface() string {
+	// REPORT_INTERFACE overrides detection
+	if iface := sc.getReportInterface(); iface != "" {
+		return iface
+	}
+	
 	// Get default route interface
 	if iface := sc.getDefaultRouteInterface(); iface != "" {
 		return iface
@@ -75,7 +91,7 @@ func (sc *SystemCollector) getMainNetworkInterface() string {
 	for _, priority := range priorities {
 		for _, iface := range interfaces {
 			// Skip loopback, virtual, and down interfaces
-			if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
+			if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 || isVirtualInterface(iface.Name) {
 				continue
 			}
 			
@@ -89,9 +105,9 @@ func (sc *SystemCollector) getMainNetworkInterface() string {
 		}
 	}
 	
-	// Last resort: return first active non-loopback interface
+	// Last resort: return first active non-loopback, non-virtual interface
 	for _, iface := range interfaces {
-		if iface.Flags&net.FlagLoopback == 0 && iface.Flags&net.FlagUp != 0 {
+		if iface.Flags&net.FlagLoopback == 0 && iface.Flags&net.FlagUp != 0 && !isVirtualInterface(iface.Name) {
 			if sc.hasValidIPAddress(iface) {
 				return iface.Name
 			}
@@ -101,6 +117,61 @@ func (sc *SystemCollector) getMainNetworkInterface() string {
 	return ""
 }
 
+// getReportInterface returns the interface pinned by REPORT_INTERFACE, "" when unset
+func (sc *SystemCollector) getReportInterface() string {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.reportInterface
+}
+
+// addressInterfaces returns the interfaces to take the reported IP from, in order of
+// preference: only the REPORT_INTERFACE one when set, otherwise the default-route interface
+// first
+func (sc *SystemCollector) addressInterfaces() []net.Interface {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	
+	if pinned := sc.getReportInterface(); pinned != "" {
+		return orderAddressInterfaces(interfaces, pinned, true)
+	}
+	return orderAddressInterfaces(interfaces, sc.getDefaultRouteInterface(), false)
+}
+
+// orderAddressInterfaces drops loopback and down interfaces and moves preferred to the
+// front. With pinned set only preferred is kept; otherwise virtual interfaces are dropped
+// too, unless preferred is one of them (e.g. a VPN tunnel carrying the default route).
+func orderAddressInterfaces(interfaces []net.Interface, preferred string, pinned bool) []net.Interface {
+	var candidates []net.Interface
+	for _, iface := range interfaces {
+		// Skip loopback and down interfaces
+		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		if iface.Name == preferred {
+			candidates = append([]net.Interface{iface}, candidates...)
+		} else if !pinned && !isVirtualInterface(iface.Name) {
+			candidates = append(candidates, iface)
+		}
+	}
+	return candidates
+}
+
+// virtualInterfacePrefixes name container bridges, veth pairs, overlay networks and
+// tunnels, whose addresses are useless for reaching the host
+var virtualInterfacePrefixes = []string{"docker", "br-", "veth", "virbr", "cni", "flannel", "tun", "tap"}
+
+// isVirtualInterface reports whether name looks like a virtual interface
+func isVirtualInterface(name string) bool {
+	for _, prefix := range virtualInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // getDefaultRouteInterface gets the interface used for the default route
 func (sc *SystemCollector) getDefaultRouteInterface() string {
 	file, err := os.Open("/proc/net/route")
@@ -151,7 +222,7 @@ func (sc *SystemCollector) hasValidIPAddress(iface net.Interface) bool {
 
 // getNetworkInfo reads network statistics from /proc/net/dev for the main interface only
 func (sc *SystemCollector) getNetworkInfo() (NetworkStats, error) {
-	file, err := os.Open("/proc/net/dev")
+	file, err := os.Open(procNetDev)
 	if err != nil {
 		return NetworkStats{}, err
 	}
@@ -207,7 +278,7 @@ func (sc *SystemCollector) getNetworkInfo() (NetworkStats, error) {
 
 // getNetworkInfoAllInterfaces is the fallback method that aggregates all interfaces
 func (sc *SystemCollector) getNetworkInfoAllInterfaces() (NetworkStats, error) {
-	file, err := os.Open("/proc/net/dev")
+	file, err := os.Open(procNetDev)
 	if err != nil {
 		return NetworkStats{}, err
 	}
@@ -254,4 +325,98 @@ func (sc *SystemCollector) getNetworkInfoAllInterfaces() (NetworkStats, error) {
 		PacketsReceived: totalRxPackets,
 		PacketsSent:     totalTxPackets,
 	}, scanner.Err()
-}
\ No newline at end of file
+}
+// getAllInterfaceStats returns traffic counters and speeds for every non-loopback interface
+func (sc *SystemCollector) getAllInterfaceStats() map[string]NetworkStats {
+	result := make(map[string]NetworkStats)
+
+	currentStats, err := sc.readNetDev()
+	if err != nil {
+		return result
+	}
+
+	sc.deltaMu.Lock()
+	defer sc.deltaMu.Unlock()
+
+	now := timeNow()
+	timeDiff := 0.0
+	if !sc.lastInterfaceTime.IsZero() {
+		timeDiff = now.Sub(sc.lastInterfaceTime).Seconds()
+	}
+
+	for name, current := range currentStats {
+		// Calculate speed if we have previous data for this interface
+		var rxSpeed, txSpeed uint64
+		if last, ok := sc.lastInterfaceStats[name]; ok && timeDiff > 0 &&
+			current.BytesReceived >= last.BytesReceived && current.BytesSent >= last.BytesSent {
+			rxSpeed = uint64(float64(current.BytesReceived-last.BytesReceived) / timeDiff)
+			txSpeed = uint64(float64(current.BytesSent-last.BytesSent) / timeDiff)
+		}
+
+		result[name] = NetworkStats{
+			BytesReceived:   current.BytesReceived,
+			BytesSent:       current.BytesSent,
+			PacketsReceived: current.PacketsReceived,
+			PacketsSent:     current.PacketsSent,
+			RxSpeed:         rxSpeed,
+			TxSpeed:         txSpeed,
+		}
+	}
+
+	// Replace the snapshot so removed interfaces are dropped
+	sc.lastInterfaceStats = currentStats
+	sc.lastInterfaceTime = now
+
+	return result
+}
+
+// readNetDev reads raw counters for every non-loopback interface from /proc/net/dev
+func (sc *SystemCollector) readNetDev() (map[string]NetworkStats, error) {
+	file, err := os.Open(procNetDev)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stats := make(map[string]NetworkStats)
+	scanner := bufio.NewScanner(file)
+	
+	// Skip header lines
+	scanner.Scan()
+	scanner.Scan()
+	
+	for scanner.Scan() {
+		// Interface names are followed by a colon that may touch the first counter
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		
+		interfaceName := strings.TrimSpace(parts[0])
+		if interfaceName == "lo" {
+			continue
+		}
+		
+		fields := strings.Fields(parts[1])
+		if len(fields) < 10 {
+			continue
+		}
+		
+		// Parse RX bytes (field 0), RX packets (field 1), TX bytes (field 8), TX packets (field 9)
+		rxBytes, err1 := strconv.ParseUint(fields[0], 10, 64)
+		rxPackets, err2 := strconv.ParseUint(fields[1], 10, 64)
+		txBytes, err3 := strconv.ParseUint(fields[8], 10, 64)
+		txPackets, err4 := strconv.ParseUint(fields[9], 10, 64)
+		
+		if err1 == nil && err2 == nil && err3 == nil && err4 == nil {
+			stats[interfaceName] = NetworkStats{
+				BytesReceived:   rxBytes,
+				BytesSent:       txBytes,
+				PacketsReceived: rxPackets,
+				PacketsSent:     txPackets,
+			}
+		}
+	}
+
+	return stats, scanner.Err()
+}
@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeNetDev replaces procNetDev with a snapshot holding lo and eth0 counters
+func writeNetDev(t *testing.T, rxBytes, txBytes uint64) {
+	t.Helper()
+
+	data := "Inter-|   Receive                                                |  Transmit\n" +
+		" face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed\n" +
+		"    lo: 5000 50 0 0 0 0 0 0 5000 50 0 0 0 0 0 0\n" +
+		fmt.Sprintf("  eth0: %d 100 0 0 0 0 0 0 %d 80 0 0 0 0 0 0\n", rxBytes, txBytes)
+
+	path := filepath.Join(t.TempDir(), "dev")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	original := procNetDev
+	procNetDev = path
+	t.Cleanup(func() { procNetDev = original })
+}
+
+// fakeClock makes timeNow return a time the test advances by hand
+func fakeClock(t *testing.T) func(time.Duration) {
+	t.Helper()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	original := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = original })
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestNetworkSpeedFromTwoSamples(t *testing.T) {
+	advance := fakeClock(t)
+	sc := NewSystemCollector()
+	sc.SetReportInterface("eth0")
+
+	writeNetDev(t, 1000000, 500000)
+	first := sc.getNetworkStats()
+	if first.RxSpeed != 0 || first.TxSpeed != 0 {
+		t.Errorf("first sample speeds = %d, %d; want 0 without a previous sample", first.RxSpeed, first.TxSpeed)
+	}
+	if first.BytesReceived != 1000000 || first.PacketsReceived != 100 {
+		t.Errorf("first sample = %d bytes, %d packets received; want eth0's 1000000, 100", first.BytesReceived, first.PacketsReceived)
+	}
+
+	advance(10 * time.Second)
+	writeNetDev(t, 1000000+10*125000, 500000+10*2500)
+	second := sc.getNetworkStats()
+
+	if second.RxSpeed != 125000 {
+		t.Errorf("RxSpeed = %d bytes/s, want 125000", second.RxSpeed)
+	}
+	if second.TxSpeed != 2500 {
+		t.Errorf("TxSpeed = %d bytes/s, want 2500", second.TxSpeed)
+	}
+	if second.PacketsSent != 80 {
+		t.Errorf("PacketsSent = %d, want 80 (packet counts must not be mixed into speeds)", second.PacketsSent)
+	}
+}
+
+func TestAllInterfaceSpeedsFromTwoSamples(t *testing.T) {
+	advance := fakeClock(t)
+	sc := NewSystemCollector()
+
+	writeNetDev(t, 2000, 4000)
+	sc.getAllInterfaceStats()
+	advance(2 * time.Second)
+
+	writeNetDev(t, 2000+2*300, 4000+2*700)
+	stats := sc.getAllInterfaceStats()
+
+	eth0, ok := stats["eth0"]
+	if !ok {
+		t.Fatalf("no eth0 in %v", stats)
+	}
+	if eth0.RxSpeed != 300 || eth0.TxSpeed != 700 {
+		t.Errorf("eth0 speeds = %d, %d bytes/s; want 300, 700", eth0.RxSpeed, eth0.TxSpeed)
+	}
+	if _, ok := stats["lo"]; ok {
+		t.Error("loopback interface reported")
+	}
+}
+
+func TestOrderAddressInterfaces(t *testing.T) {
+	up := net.FlagUp | net.FlagBroadcast
+	interfaces := []net.Interface{
+		{Index: 1, Name: "lo", Flags: net.FlagUp | net.FlagLoopback},
+		{Index: 2, Name: "docker0", Flags: up},
+		{Index: 3, Name: "br-3f2a9c", Flags: up},
+		{Index: 4, Name: "veth12ab", Flags: up},
+		{Index: 5, Name: "eth0", Flags: up},
+		{Index: 6, Name: "eth1", Flags: net.FlagBroadcast}, // Down
+		{Index: 7, Name: "tun0", Flags: net.FlagUp | net.FlagPointToPoint},
+	}
+
+	tests := []struct {
+		name      string
+		preferred string
+		pinned    bool
+		want      []string
+	}{
+		{"bridges listed before eth0", "", false, []string{"eth0"}},
+		{"default route on eth0", "eth0", false, []string{"eth0"}},
+		{"default route over a VPN tunnel", "tun0", false, []string{"tun0", "eth0"}},
+		{"default route on a down interface", "eth1", false, []string{"eth0"}},
+		{"pinned to eth0", "eth0", true, []string{"eth0"}},
+		{"pinned to the docker bridge", "docker0", true, []string{"docker0"}},
+		{"pinned to a missing interface", "wlan0", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, iface := range orderAddressInterfaces(interfaces, tt.preferred, tt.pinned) {
+				got = append(got, iface.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderAddressInterfaces(%q, pinned=%v) = %v, want %v", tt.preferred, tt.pinned, got, tt.want)
+			}
+		})
+	}
+}
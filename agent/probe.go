@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"monitoring-agent/logging"
+	pbClient "monitoring-agent/pocketbase"
+)
+
+const (
+	defaultProbeTimeout = 10 * time.Second
+	maxProbeTimeout     = 60 * time.Second
+)
+
+// isProbeCommand reports whether a remote command runs a health probe
+func isProbeCommand(command string) bool {
+	return command == "check_http" || command == "check_tcp"
+}
+
+// runProbe performs a check_http or check_tcp command and records the outcome in the
+// probe_results collection. Malformed parameters are recorded as a failed probe, so only a
+// failure to save the result is returned.
+func (a *Agent) runProbe(command string, parameters map[string]string) error {
+	result := pbClient.ProbeResultRecord{
+		ServerID:  a.config().AgentID,
+		Type:      command,
+		Timestamp: time.Now(),
+	}
+
+	timeout, err := parseProbeTimeout(parameters["timeout"])
+	if err == nil {
+		switch command {
+		case "check_http":
+			result.Target = parameters["url"]
+			err = a.probeHTTP(&result, timeout)
+		case "check_tcp":
+			result.Target = parameters["address"]
+			err = a.probeTCP(&result, timeout)
+		}
+	}
+	if err != nil {
+		result.Up = false
+		result.Error = err.Error()
+	}
+
+	if result.Up {
+		logging.Infof("Probe %s %s is up (%.1fms)", command, result.Target, result.LatencyMs)
+	} else {
+		logging.Infof("Probe %s %s is down: %s", command, result.Target, result.Error)
+	}
+	return a.saveProbeResult(result)
+}
+
+// recordFailedProbe saves a probe that couldn't run at all, e.g. with unparseable parameters
+func (a *Agent) recordFailedProbe(command string, err error) error {
+	logging.Warnf("Probe %s failed: %v", command, err)
+	return a.saveProbeResult(pbClient.ProbeResultRecord{
+		ServerID:  a.config().AgentID,
+		Type:      command,
+		Error:     err.Error(),
+		Timestamp: time.Now(),
+	})
+}
+
+func (a *Agent) saveProbeResult(result pbClient.ProbeResultRecord) error {
+	if a.pocketBase == nil {
+		return fmt.Errorf("no PocketBase client available")
+	}
+	return a.pocketBase.SaveProbeResult(a.ctx, result)
+}
+
+// probeHTTP requests the URL with GET, treating any status below 400 as up
+func (a *Agent) probeHTTP(result *pbClient.ProbeResultRecord, timeout time.Duration) error {
+	target, err := url.Parse(result.Target)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("parameter url must be an http or https URL (got %q)", result.Target)
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	result.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	result.Up = true
+	return nil
+}
+
+// probeTCP opens and closes a connection to host:port
+func (a *Agent) probeTCP(result *pbClient.ProbeResultRecord, timeout time.Duration) error {
+	if _, _, err := net.SplitHostPort(result.Target); err != nil {
+		return fmt.Errorf("parameter address must be host:port (got %q)", result.Target)
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", result.Target)
+	if err != nil {
+		return err
+	}
+	conn.Close()
+
+	result.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	result.Up = true
+	return nil
+}
+
+// parseProbeTimeout accepts a duration ("5s") or plain seconds, defaulting to 10s and capped at 60s
+func parseProbeTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultProbeTimeout, nil
+	}
+
+	timeout, err := parseDurationParam(value)
+	if err != nil {
+		return 0, fmt.Errorf("parameter timeout %v", err)
+	}
+	if timeout > maxProbeTimeout {
+		timeout = maxProbeTimeout
+	}
+	return timeout, nil
+}
@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MonitoredProcess describes a running process matched by name
+type MonitoredProcess struct {
+	Name    string // Configured name the process matched
+	PID     int
+	Threads int
+}
+
+// getMonitoredProcesses finds processes whose name or command line matches one of names
+func (sc *SystemCollector) getMonitoredProcesses(names []string) []MonitoredProcess {
+	processes := []MonitoredProcess{}
+	if len(names) == 0 {
+		return processes
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return processes
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		// The process may exit between listing and reading, so errors just skip it
+		status, err := readProcStatus(pid)
+		if err != nil {
+			continue
+		}
+
+		cmdline, _ := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		commandLine := strings.ReplaceAll(string(cmdline), "\x00", " ")
+
+		for _, name := range names {
+			if status["Name"] != name && !strings.Contains(commandLine, name) {
+				continue
+			}
+
+			threads, _ := strconv.Atoi(status["Threads"])
+			processes = append(processes, MonitoredProcess{
+				Name:    name,
+				PID:     pid,
+				Threads: threads,
+			})
+			break
+		}
+	}
+
+	return processes
+}
+
+// readProcStatus parses /proc/[pid]/status into a key/value map
+func readProcStatus(pid int) (map[string]string, error) {
+	file, err := os.Open(filepath.Join("/proc", strconv.Itoa(pid), "status"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	status := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) == 2 {
+			status[parts[0]] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	return status, scanner.Err()
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitoring-agent/logging"
+	pbClient "monitoring-agent/pocketbase"
+)
+
+// detectReboot compares the host boot time with the one persisted by the previous run
+// and emits a reboot event when it changed
+func (a *Agent) detectReboot() {
+	bootTime := a.collector.GetBootTime()
+	if bootTime.IsZero() {
+		return
+	}
+
+	statePath := filepath.Join(a.config().StateDir, "last_boot_time")
+
+	previousBoot := time.Time{}
+	if data, err := os.ReadFile(statePath); err == nil {
+		if seconds, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err == nil {
+			previousBoot = time.Unix(seconds, 0)
+		}
+	}
+
+	if err := os.MkdirAll(a.config().StateDir, 0755); err != nil {
+		logging.Warnf("Could not create state directory %s: %v", a.config().StateDir, err)
+		return
+	}
+	if err := os.WriteFile(statePath, []byte(strconv.FormatInt(bootTime.Unix(), 10)), 0644); err != nil {
+		logging.Warnf("Could not persist boot time: %v", err)
+	}
+
+	// Allow a little slack for boot time derived from uptime
+	if previousBoot.IsZero() || bootTime.Sub(previousBoot).Abs() < time.Minute {
+		return
+	}
+
+	message := fmt.Sprintf("Host rebooted at %s (previous boot %s)", bootTime.Format(time.RFC3339), previousBoot.Format(time.RFC3339))
+	logging.Infof("Reboot detected: %s", message)
+
+	if a.pocketBase != nil {
+		event := pbClient.EventRecord{
+			ServerID:  a.config().AgentID,
+			Type:      "reboot",
+			Message:   message,
+			Timestamp: bootTime,
+		}
+		if err := a.pocketBase.SaveEvent(a.ctx, event); err != nil {
+			logging.Warnf("Failed to save reboot event (this is optional): %v", err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"time"
+
+	"monitoring-agent/logging"
+)
+
+const (
+	registrationBackoffBase = time.Second
+	maxRegistrationBackoff  = time.Minute
+)
+
+// registerWithRetry initializes the server record, retrying with backoff until
+// REGISTRATION_TIMEOUT has passed so a PocketBase restart doesn't stop the agent from starting
+func (a *Agent) registerWithRetry() error {
+	deadline := time.Now().Add(a.config().RegistrationTimeout)
+	backoff := registrationBackoffBase
+
+	for {
+		err := a.initializeServerRecord()
+		if err == nil {
+			a.markRegistered()
+			return nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return err
+		}
+
+		logging.Warnf("Server registration failed: %v, retrying in %v", err, backoff)
+		select {
+		case <-a.ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = nextRegistrationBackoff(backoff)
+	}
+}
+
+// retryRegistration makes a registration attempt from the collection loop once the backoff
+// since the last failed one has passed, and reports whether the server is now registered
+func (a *Agent) retryRegistration() bool {
+	if time.Now().Before(a.nextRegistration) {
+		return false
+	}
+
+	if err := a.initializeServerRecord(); err != nil {
+		a.registrationBackoff = nextRegistrationBackoff(a.registrationBackoff)
+		a.nextRegistration = time.Now().Add(a.registrationBackoff)
+		logging.Warnf("Server registration failed: %v, retrying in %v", err, a.registrationBackoff)
+		a.recordError("servers", err)
+		return false
+	}
+
+	a.markRegistered()
+	logging.Infof("Server %s registered, reporting resumed", a.config().AgentID)
+	return true
+}
+
+func (a *Agent) markRegistered() {
+	a.readyMutex.Lock()
+	a.registered = true
+	a.readyMutex.Unlock()
+}
+
+// nextRegistrationBackoff doubles backoff, starting at 1s and capped at a minute
+func nextRegistrationBackoff(backoff time.Duration) time.Duration {
+	if backoff <= 0 {
+		return registrationBackoffBase
+	}
+	if backoff *= 2; backoff > maxRegistrationBackoff {
+		return maxRegistrationBackoff
+	}
+	return backoff
+}
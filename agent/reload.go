@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"monitoring-agent/config"
+	"monitoring-agent/logging"
+)
+
+// Reload hands a freshly loaded configuration to the collection loop, which applies the
+// hot-reloadable fields before its next cycle. Everything else (PocketBase connection,
+// agent ID, server identity, health server, StatsD listener, state directory, remote
+// control) keeps its startup value until the agent is restarted.
+func (a *Agent) Reload(cfg *config.Config) {
+	select {
+	case a.reload <- cfg:
+	default:
+		logging.Warnf("Configuration reload already pending, ignoring")
+	}
+}
+
+// applyReload copies the hot-reloadable fields of cfg into a copy of the running
+// configuration and swaps it in. The command and HTTP goroutines read the configuration
+// concurrently, so the one in use is never modified in place.
+func (a *Agent) applyReload(cfg *config.Config) {
+	current := *a.config()
+
+	// Logging
+	current.LogLevel = cfg.LogLevel
+	if level, ok := logging.ParseLevel(cfg.LogLevel); ok {
+		logging.SetLevel(level)
+	}
+
+	// Intervals
+	current.CheckInterval = cfg.CheckInterval
+	current.MinCheckInterval = cfg.MinCheckInterval
+	current.MaxCheckInterval = cfg.MaxCheckInterval
+	current.StatusPollInterval = cfg.StatusPollInterval
+	current.PausedPollInterval = cfg.PausedPollInterval
+	current.CollectionBudgetPercent = cfg.CollectionBudgetPercent
+	current.CollectionTimeout = cfg.CollectionTimeout
+	current.CheckIntervalJitter = cfg.CheckIntervalJitter
+
+	// Output units
+	current.MemoryUnit = cfg.MemoryUnit
+	current.DiskUnit = cfg.DiskUnit
+	current.NetworkUnit = cfg.NetworkUnit
+
+	// Disk collection
+	current.DiskRootPath = cfg.DiskRootPath
+	current.ReportInterface = cfg.ReportInterface
+
+	// Docker collection
+	current.ContainerRuntime = cfg.ContainerRuntime
+	current.DockerStatsConcurrency = cfg.DockerStatsConcurrency
+	current.DockerIncludePattern = cfg.DockerIncludePattern
+	current.DockerExcludePattern = cfg.DockerExcludePattern
+	current.DockerLabelFilter = cfg.DockerLabelFilter
+
+	// Optional collectors and their thresholds
+	current.EntropyMonitoringEnabled = cfg.EntropyMonitoringEnabled
+	current.MonitoredProcesses = cfg.MonitoredProcesses
+	current.ProcessThreadThreshold = cfg.ProcessThreadThreshold
+	current.LinkMonitoringEnabled = cfg.LinkMonitoringEnabled
+	current.MinLinkSpeedMbps = cfg.MinLinkSpeedMbps
+	current.ConntrackWarnPercent = cfg.ConntrackWarnPercent
+	current.FileHandleWarnPercent = cfg.FileHandleWarnPercent
+	current.SynRecvWarnThreshold = cfg.SynRecvWarnThreshold
+	current.HugePagesMonitoringEnabled = cfg.HugePagesMonitoringEnabled
+	current.FragmentationMonitoringEnabled = cfg.FragmentationMonitoringEnabled
+	current.FragmentationHighOrder = cfg.FragmentationHighOrder
+	current.FragmentationWarnPercent = cfg.FragmentationWarnPercent
+	current.SystemdTimerMonitoringEnabled = cfg.SystemdTimerMonitoringEnabled
+	current.SystemdTimerGrace = cfg.SystemdTimerGrace
+	current.StorageArrayMonitoringEnabled = cfg.StorageArrayMonitoringEnabled
+	current.ScrubMaxAge = cfg.ScrubMaxAge
+	current.CoreDumpMonitoringEnabled = cfg.CoreDumpMonitoringEnabled
+	current.KernelTaintMonitoringEnabled = cfg.KernelTaintMonitoringEnabled
+	current.EgressQuotaEnabled = cfg.EgressQuotaEnabled
+	current.EgressQuotaGB = cfg.EgressQuotaGB
+	current.EgressResetDay = cfg.EgressResetDay
+	current.CustomMetrics = cfg.CustomMetrics
+	current.CustomMetricTimeout = cfg.CustomMetricTimeout
+
+	// Alerting
+	current.AlertWebhookURL = cfg.AlertWebhookURL
+	current.AlertWebhookType = cfg.AlertWebhookType
+	current.AlertCPUPercent = cfg.AlertCPUPercent
+	current.AlertMemoryPercent = cfg.AlertMemoryPercent
+	current.AlertDiskPercent = cfg.AlertDiskPercent
+	current.AlertDuration = cfg.AlertDuration
+
+	a.liveConfig.Store(&current)
+	a.configureCollector()
+
+	logging.Infof("Configuration reloaded (check interval %v)", current.CheckInterval)
+}
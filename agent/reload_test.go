@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"monitoring-agent/config"
+)
+
+func TestApplyReloadReplacesConfigForConcurrentReaders(t *testing.T) {
+	original := &config.Config{AgentID: "agent-1", CheckInterval: 30 * time.Second, LogLevel: "info"}
+	a := newTestAgent(original)
+
+	// The command and HTTP goroutines keep reading while the collection loop reloads
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					_ = a.config().CheckInterval
+					_ = a.config().AgentID
+				}
+			}
+		}()
+	}
+
+	for i := 1; i <= 100; i++ {
+		a.applyReload(&config.Config{CheckInterval: time.Duration(i) * time.Second, LogLevel: "info"})
+		a.applyRuntimeUpdate(runtimeUpdate{checkInterval: time.Duration(i) * time.Minute})
+	}
+	close(stop)
+	wg.Wait()
+
+	if original.CheckInterval != 30*time.Second {
+		t.Errorf("reload modified the configuration in use to %v", original.CheckInterval)
+	}
+	if got := a.config(); got.CheckInterval != 100*time.Minute || got.AgentID != "agent-1" {
+		t.Errorf("config after reloads = interval %v, agent %q; want 100m0s, agent-1", got.CheckInterval, got.AgentID)
+	}
+}
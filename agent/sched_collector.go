@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedStat holds run-queue wait time and timeslices summed over all CPUs
+type schedStat struct {
+	WaitNanos  uint64 // Time runnable tasks spent waiting for a CPU
+	Timeslices uint64 // Number of timeslices run
+}
+
+// SchedulingLatency describes how long runnable tasks wait for a CPU
+type SchedulingLatency struct {
+	AvgDelayMs     float64 // Average run-queue wait per timeslice, from /proc/schedstat
+	CPUPressure10  float64 // PSI cpu "some" avg10: percent of time at least one task waited for a CPU
+	HasSchedstat   bool
+	HasCPUPressure bool
+}
+
+// getSchedulingLatency samples /proc/schedstat and PSI, using whichever the kernel provides
+func (sc *SystemCollector) getSchedulingLatency() SchedulingLatency {
+	latency := SchedulingLatency{}
+
+	if current, err := readSchedStat(); err == nil {
+		sc.deltaMu.Lock()
+		defer sc.deltaMu.Unlock()
+
+		// If this is the first call, take a baseline and sample again shortly after
+		if !sc.schedStatInitialized {
+			sc.lastSchedStat = current
+			sc.schedStatInitialized = true
+
+			time.Sleep(200 * time.Millisecond)
+
+			current, err = readSchedStat()
+		}
+
+		if err == nil {
+			latency.HasSchedstat = true
+			if current.Timeslices > sc.lastSchedStat.Timeslices {
+				wait := current.WaitNanos - sc.lastSchedStat.WaitNanos
+				slices := current.Timeslices - sc.lastSchedStat.Timeslices
+				latency.AvgDelayMs = float64(wait) / float64(slices) / 1e6
+			}
+			sc.lastSchedStat = current
+		}
+	}
+
+	if some10, err := readCPUPressure(); err == nil {
+		latency.HasCPUPressure = true
+		latency.CPUPressure10 = some10
+	}
+
+	return latency
+}
+
+// readSchedStat sums the per-CPU lines of /proc/schedstat, where the 8th and 9th values
+// after the cpu name are run-queue wait time in nanoseconds and timeslices run
+func readSchedStat() (schedStat, error) {
+	file, err := os.Open("/proc/schedstat")
+	if err != nil {
+		return schedStat{}, err
+	}
+	defer file.Close()
+
+	stat := schedStat{}
+	found := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+
+		wait, err1 := strconv.ParseUint(fields[8], 10, 64)
+		slices, err2 := strconv.ParseUint(fields[9], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		stat.WaitNanos += wait
+		stat.Timeslices += slices
+		found = true
+	}
+
+	if !found {
+		return stat, fmt.Errorf("no cpu lines in /proc/schedstat")
+	}
+	return stat, nil
+}
+
+// readCPUPressure returns the avg10 value of the "some" line in /proc/pressure/cpu
+func readCPUPressure() (float64, error) {
+	data, err := os.ReadFile("/proc/pressure/cpu")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "some" {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if value, ok := strings.CutPrefix(field, "avg10="); ok {
+				return strconv.ParseFloat(value, 64)
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("no some avg10 in /proc/pressure/cpu")
+}
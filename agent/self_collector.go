@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SelfUsage is the agent process's own resource usage
+type SelfUsage struct {
+	CPUPercent   float64 `json:"cpu_percent"` // Share of one core since the previous sample
+	RSSBytes     int64   `json:"rss_bytes"`
+	PeakRSSBytes int64   `json:"peak_rss_bytes"`
+	Threads      int     `json:"threads"`
+	Goroutines   int     `json:"goroutines"`
+}
+
+// selfCPUSample is the agent's CPU time at a point in time, for computing usage between samples
+type selfCPUSample struct {
+	mu    sync.Mutex
+	ticks uint64
+	time  time.Time
+}
+
+// readSelfProcStat returns utime+stime in clock ticks and the thread count from /proc/self/stat
+func readSelfProcStat() (ticks uint64, threads int, err error) {
+	data, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fields, err := parseProcStat(string(data))
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(fields) < 18 {
+		return 0, 0, fmt.Errorf("unexpected /proc/self/stat format")
+	}
+
+	// num_threads (field 20) is at 17
+	utime, err1 := strconv.ParseUint(fields[11], 10, 64)
+	stime, err2 := strconv.ParseUint(fields[12], 10, 64)
+	threads, err3 := strconv.Atoi(fields[17])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, 0, fmt.Errorf("unexpected /proc/self/stat format")
+	}
+
+	return utime + stime, threads, nil
+}
+
+// getSelfUsage reports the agent's RSS from /proc/self/status and its CPU usage since the
+// previous call. Without procfs only the goroutine count is available.
+func (a *Agent) getSelfUsage() SelfUsage {
+	usage := SelfUsage{Goroutines: runtime.NumGoroutine()}
+
+	ticks, threads, err := readSelfProcStat()
+	if err != nil {
+		return usage
+	}
+	usage.Threads = threads
+
+	now := time.Now()
+	a.selfCPU.mu.Lock()
+	if elapsed := now.Sub(a.selfCPU.time).Seconds(); !a.selfCPU.time.IsZero() && elapsed > 0 && ticks >= a.selfCPU.ticks {
+		usage.CPUPercent = float64(ticks-a.selfCPU.ticks) / clockTicksPerSecond / elapsed * 100
+	}
+	a.selfCPU.ticks, a.selfCPU.time = ticks, now
+	a.selfCPU.mu.Unlock()
+
+	if status, err := readProcStatus(os.Getpid()); err == nil {
+		if rss, err := strconv.ParseInt(strings.TrimSuffix(status["VmRSS"], " kB"), 10, 64); err == nil {
+			usage.RSSBytes = rss * 1024
+		}
+		if hwm, err := strconv.ParseInt(strings.TrimSuffix(status["VmHWM"], " kB"), 10, 64); err == nil {
+			usage.PeakRSSBytes = hwm * 1024
+		}
+	}
+
+	return usage
+}
@@ -0,0 +1,224 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"monitoring-agent/config"
+)
+
+// selfTestResult is the outcome of running a single collector
+type selfTestResult struct {
+	Output   string
+	Err      error
+	Fallback string // Why the collector returned placeholder values instead of real data
+}
+
+// selfTestCheck runs one collector and inspects its data source for fallbacks
+type selfTestCheck struct {
+	Name string
+	Run  func(sc *SystemCollector) selfTestResult
+}
+
+// RunSelfTest runs every collector once, printing output, timing and status for each.
+// It returns false when any collector failed or fell back to placeholder values.
+// cfg may be nil when the configuration couldn't be loaded.
+func RunSelfTest(cfg *config.Config, w io.Writer) bool {
+	collector := NewSystemCollector()
+	var monitoredProcesses []string
+	if cfg != nil {
+		collector.SetContainerRuntime(cfg.ContainerRuntime)
+		collector.SetDockerStatsConcurrency(cfg.DockerStatsConcurrency)
+		collector.SetContainerFilter(cfg.DockerIncludePattern, cfg.DockerExcludePattern, cfg.DockerLabelFilter)
+		collector.SetDiskRootPath(cfg.DiskRootPath)
+		collector.SetReportInterface(cfg.ReportInterface)
+		monitoredProcesses = cfg.MonitoredProcesses
+	}
+
+	checks := []selfTestCheck{
+		{"system_info", func(sc *SystemCollector) selfTestResult {
+			info := sc.GetSystemInfo()
+			return selfTestResult{Output: fmt.Sprintf("%s %s, kernel %s, %s (%d cores), IP %s", info.OSName, info.OSVersion, info.KernelVersion, info.CPUModel, info.CPUCores, formatIPAddresses(info))}
+		}},
+		{"cpu", func(sc *SystemCollector) selfTestResult {
+			if _, err := sc.getCPUStats(); err != nil {
+				return selfTestResult{Err: err}
+			}
+			return selfTestResult{Output: fmt.Sprintf("%.2f%%", sc.GetCPUUsage())}
+		}},
+		{"cpu_per_core", func(sc *SystemCollector) selfTestResult {
+			if _, err := sc.getPerCoreCPUStats(); err != nil {
+				return selfTestResult{Err: err}
+			}
+			return selfTestResult{Output: fmt.Sprintf("%v", sc.GetPerCoreCPUUsage())}
+		}},
+		{"cpu_cores", func(sc *SystemCollector) selfTestResult {
+			return selfTestResult{Output: fmt.Sprintf("%d online, %d usable after affinity/cpuset", sc.GetOnlineCPUCount(), sc.GetEffectiveCPUCount())}
+		}},
+		{"sched_latency", func(sc *SystemCollector) selfTestResult {
+			latency := sc.GetSchedulingLatency()
+			result := selfTestResult{Output: fmt.Sprintf("delay %.3fms (schedstat: %t), cpu pressure %.2f%% (psi: %t)", latency.AvgDelayMs, latency.HasSchedstat, latency.CPUPressure10, latency.HasCPUPressure)}
+			if !latency.HasSchedstat && !latency.HasCPUPressure {
+				result.Err = fmt.Errorf("neither /proc/schedstat nor /proc/pressure/cpu is available")
+			}
+			return result
+		}},
+		{"thermal", func(sc *SystemCollector) selfTestResult {
+			return selfTestResult{Output: fmt.Sprintf("%+v", sc.GetThermalZones())}
+		}},
+		{"load", func(sc *SystemCollector) selfTestResult {
+			load1, load5, load15 := sc.GetLoadAverage()
+			return selfTestResult{Output: fmt.Sprintf("%.2f %.2f %.2f", load1, load5, load15)}
+		}},
+		{"memory", func(sc *SystemCollector) selfTestResult {
+			result := selfTestResult{}
+			if _, err := sc.getMemInfo(); err != nil {
+				result.Fallback = fmt.Sprintf("/proc/meminfo unreadable (%v), reporting Go runtime memory", err)
+			}
+			used, total, percentage := sc.GetMemoryUsage()
+			result.Output = fmt.Sprintf("%d / %d bytes (%.1f%%)", used, total, percentage)
+			return result
+		}},
+		{"swap", func(sc *SystemCollector) selfTestResult {
+			used, total, percentage := sc.GetSwapUsage()
+			return selfTestResult{Output: fmt.Sprintf("%d / %d bytes (%.1f%%), %d devices", used, total, percentage, len(sc.GetSwapDevices()))}
+		}},
+		{"hugepages", func(sc *SystemCollector) selfTestResult {
+			info := sc.GetHugePages()
+			return selfTestResult{Output: fmt.Sprintf("%+v", info)}
+		}},
+		{"buddyinfo", func(sc *SystemCollector) selfTestResult {
+			info, err := sc.GetBuddyInfo(4)
+			return selfTestResult{Output: fmt.Sprintf("free blocks %v, %.1f%% in order-4+", info.FreeBlocks, info.HighOrderFreePercent), Err: err}
+		}},
+		{"disk", func(sc *SystemCollector) selfTestResult {
+			result := selfTestResult{}
+			if _, _, err := sc.getRootDiskSpace(); errors.Is(err, errStaleMount) {
+				result.Err = fmt.Errorf("%s: %v", sc.getDiskRootPath(), err)
+			} else if err != nil {
+				result.Fallback = fmt.Sprintf("stat %s failed (%v), reporting placeholder 5GB/20GB", sc.getDiskRootPath(), err)
+			}
+			used, total, percentage := sc.GetDiskUsage()
+			inodeUsed, inodeTotal, _ := sc.GetInodeUsage()
+			result.Output = fmt.Sprintf("%d / %d bytes (%.1f%%), inodes %d / %d", used, total, percentage, inodeUsed, inodeTotal)
+			return result
+		}},
+		{"network", func(sc *SystemCollector) selfTestResult {
+			result := selfTestResult{}
+			if _, err := sc.getNetworkInfo(); err != nil {
+				result.Fallback = fmt.Sprintf("interface counters unavailable (%v), reporting zeros", err)
+			}
+			stats := sc.GetNetworkStats()
+			result.Output = fmt.Sprintf("rx %d bytes, tx %d bytes, %d interfaces", stats.BytesReceived, stats.BytesSent, len(sc.GetAllInterfaceStats()))
+			return result
+		}},
+		{"uptime", func(sc *SystemCollector) selfTestResult {
+			result := selfTestResult{}
+			if _, err := sc.getUptime(); err != nil {
+				result.Fallback = fmt.Sprintf("/proc/uptime unreadable (%v), reporting placeholder 24h", err)
+			}
+			result.Output = fmt.Sprintf("%ds, booted %s", sc.GetSystemUptime(), formatOptionalTime(sc.GetBootTime()))
+			return result
+		}},
+		{"time_locale", func(sc *SystemCollector) selfTestResult {
+			return selfTestResult{Output: fmt.Sprintf("timezone %s, locale %s", sc.GetTimezone(), sc.GetLocale())}
+		}},
+		{"file_handles", func(sc *SystemCollector) selfTestResult {
+			used, max, percentage := sc.GetFileHandleUsage()
+			return selfTestResult{Output: fmt.Sprintf("%d / %d (%.1f%%)", used, max, percentage)}
+		}},
+		{"task_counts", func(sc *SystemCollector) selfTestResult {
+			processes, threads := sc.GetTaskCounts()
+			return selfTestResult{Output: fmt.Sprintf("%d processes, %d threads", processes, threads)}
+		}},
+		{"tcp_states", func(sc *SystemCollector) selfTestResult {
+			return selfTestResult{Output: fmt.Sprintf("%v", sc.GetTCPStateCounts())}
+		}},
+		{"conntrack", func(sc *SystemCollector) selfTestResult {
+			count, max, percentage := sc.GetConntrackUsage()
+			return selfTestResult{Output: fmt.Sprintf("%d / %d (%.1f%%)", count, max, percentage)}
+		}},
+		{"links", func(sc *SystemCollector) selfTestResult {
+			return selfTestResult{Output: fmt.Sprintf("%+v", sc.GetLinkStates())}
+		}},
+		{"entropy", func(sc *SystemCollector) selfTestResult {
+			return selfTestResult{Output: fmt.Sprintf("%+v", sc.GetEntropyInfo())}
+		}},
+		{"processes", func(sc *SystemCollector) selfTestResult {
+			return selfTestResult{Output: fmt.Sprintf("%+v", sc.GetMonitoredProcesses(monitoredProcesses))}
+		}},
+		{"top_processes", func(sc *SystemCollector) selfTestResult {
+			return selfTestResult{Output: fmt.Sprintf("%+v", sc.GetTopProcesses(3, "cpu"))}
+		}},
+		{"systemd_timers", func(sc *SystemCollector) selfTestResult {
+			timers, err := sc.GetSystemdTimers(context.Background())
+			return selfTestResult{Output: fmt.Sprintf("%d timers", len(timers)), Err: err}
+		}},
+		{"kernel_taint", func(sc *SystemCollector) selfTestResult {
+			taint, flags := sc.GetKernelTaint()
+			return selfTestResult{Output: fmt.Sprintf("%d %v", taint, flags)}
+		}},
+		{"core_dumps", func(sc *SystemCollector) selfTestResult {
+			info := sc.GetCoreDumps()
+			return selfTestResult{Output: fmt.Sprintf("%d dumps, latest %q at %s", info.Count, info.LatestExecutable, formatOptionalTime(info.LatestTime))}
+		}},
+		{"storage_arrays", func(sc *SystemCollector) selfTestResult {
+			return selfTestResult{Output: fmt.Sprintf("%+v", sc.GetStorageArrays(context.Background()))}
+		}},
+		{"docker", func(sc *SystemCollector) selfTestResult {
+			if !sc.IsDockerAvailable() {
+				return selfTestResult{Output: "no container runtime available"}
+			}
+
+			info := sc.GetDockerInfo(context.Background())
+			result := selfTestResult{Output: fmt.Sprintf("%s %s, %d containers (%d running)", info.Runtime, info.Version, info.Summary.Total, info.Summary.Running)}
+			if info.Version == "permission_denied" {
+				result.Fallback = "version query failed, check socket permissions"
+			}
+
+			for _, container := range info.Containers {
+				if isContainerRunning(container.Status) && !container.StatsAvailable {
+					result.Fallback = fmt.Sprintf("stats unavailable for running container %s", container.Name)
+					break
+				}
+			}
+			return result
+		}},
+	}
+
+	allPassed := true
+	for _, check := range checks {
+		start := time.Now()
+		result := check.Run(collector)
+		elapsed := time.Since(start).Round(time.Millisecond)
+
+		status := "OK"
+		switch {
+		case result.Err != nil:
+			status = "FAILED"
+			allPassed = false
+		case result.Fallback != "":
+			status = "FALLBACK"
+			allPassed = false
+		}
+
+		fmt.Fprintf(w, "[%-8s] %-15s %8s  %s\n", status, check.Name, elapsed, result.Output)
+		if result.Err != nil {
+			fmt.Fprintf(w, "           error: %v\n", result.Err)
+		}
+		if result.Fallback != "" {
+			fmt.Fprintf(w, "           fallback: %s\n", result.Fallback)
+		}
+	}
+
+	if allPassed {
+		fmt.Fprintln(w, "All collectors returned real data")
+	} else {
+		fmt.Fprintln(w, "Some collectors failed or returned placeholder values")
+	}
+
+	return allPassed
+}
@@ -2,16 +2,24 @@
 package agent
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"math"
+	"net/http"
 	"runtime"
+	"sort"
+	"strings"
 	"time"
 
+	"monitoring-agent/logging"
 	pbClient "monitoring-agent/pocketbase"
 )
 
-func (a *Agent) gatherServerMetrics() pbClient.ServerRecord {
-	collector := NewSystemCollector()
+// gatherServerMetrics builds the servers record as an update of server. shedOptional skips
+// the Docker summary, which lists every container, when the previous cycle overran its budget.
+func (a *Agent) gatherServerMetrics(ctx context.Context, server *pbClient.ServerRecord, shedOptional bool) pbClient.ServerRecord {
+	collector := a.collector
 	
 	// Get comprehensive system information
 	sysInfo := collector.GetSystemInfo()
@@ -22,102 +30,453 @@ func (a *Agent) gatherServerMetrics() pbClient.ServerRecord {
 	// Get real disk data
 	diskUsed, diskTotal, _ := collector.GetDiskUsage()
 	
-	// Get real CPU usage with improved accuracy
+	// CPU usage comes from the agent's background sampler instead of sampling again here
 	cpuUsage := collector.GetCPUUsage()
 	
 	// Check Docker availability - but don't override PocketBase setting
 	dockerAvailable := collector.IsDockerAvailable()
 	
+	// Summarize stopped containers so broken cleanup can be alerted on
+	var dockerSummary DockerSummary
+	if dockerAvailable && server.Docker.Value && !shedOptional {
+		dockerSummary = collector.GetDockerSummary(ctx)
+	}
+	
 	// Format comprehensive system info
-	systemInfoString := fmt.Sprintf("%s %s | %s | Kernel: %s | CPU: %s (%d cores) | RAM: %.1f GB | Go %s | IP: %s | Docker: %t", 
-		sysInfo.OSName, 
-		sysInfo.OSVersion,
-		sysInfo.Architecture,
-		sysInfo.KernelVersion,
-		sysInfo.CPUModel,
-		sysInfo.CPUCores,
-		float64(sysInfo.TotalRAM)/1024/1024/1024,
-		sysInfo.GoVersion,
-		sysInfo.IPAddress,
-		dockerAvailable,
-	)
-	
-	return pbClient.ServerRecord{
-		ID:             a.serverRecord.ID, // Use existing record ID
-		ServerID:       a.config.AgentID,
-		Name:           a.config.ServerName,
+	sysInfo.DockerAvailable = dockerAvailable
+	systemInfoString := sysInfo.Format()
+	
+	record := pbClient.ServerRecord{
+		ID:             server.ID, // Use existing record ID
+		ServerID:       a.config().AgentID,
+		Name:           a.config().ServerName,
 		Hostname:       sysInfo.Hostname, // Use real hostname
 		IPAddress:      sysInfo.IPAddress, // Use real IP address
+		IPv6Address:    sysInfo.IPv6Address,
 		OSType:         sysInfo.OSType,    // Use real OS type
 		Status:         "up",
 		Uptime:         a.getUptimeString(),
 		RAMTotal:       ramTotal,
 		RAMUsed:        ramUsed,
-		CPUCores:       runtime.NumCPU(),
+		CPUCores:       collector.GetOnlineCPUCount(),
+		CPUCoresEffective: collector.GetEffectiveCPUCount(),
 		CPUUsage:       cpuUsage,
 		DiskTotal:      diskTotal,
 		DiskUsed:       diskUsed,
 		LastChecked:    pbClient.FlexibleTime{Time: time.Now()},
-		ServerToken:    a.config.ServerToken,
+		ServerToken:    a.config().ServerToken,
 		Connection:     "connected",
+		BootTime:       pbClient.FlexibleTime{Time: collector.GetBootTime()},
+		Timezone:       collector.GetTimezone(),
+		Locale:         collector.GetLocale(),
 		SystemInfo:     systemInfoString, // Comprehensive system info
+		OSName:         sysInfo.OSName,
+		OSVersion:      sysInfo.OSVersion,
+		KernelVersion:  sysInfo.KernelVersion,
+		Architecture:   sysInfo.Architecture,
+		CPUModel:       sysInfo.CPUModel,
+		GoVersion:      sysInfo.GoVersion,
 		// Preserve the Docker setting from PocketBase - don't override it
-		Docker:         a.serverRecord.Docker,
+		Docker:         server.Docker,
+		DockerStopped:  dockerSummary.Stopped,
+		DockerOldestStoppedAge: int64(dockerSummary.OldestStoppedAge.Seconds()),
 		Timestamp:      time.Now().Format(time.RFC3339),
 		// Preserve the existing check_interval from the server record instead of overwriting it
-		CheckInterval:  a.serverRecord.CheckInterval,
+		CheckInterval:  server.CheckInterval,
+	}
+	
+	// A machine check or oops leaves the kernel tainted even after the host recovers
+	if a.config().KernelTaintMonitoringEnabled {
+		taint, taintFlags := collector.GetKernelTaint()
+		record.KernelTaint = taint
+		record.KernelTaintFlags = strings.Join(taintFlags, ",")
 	}
+	
+	return record
 }
 
-func (a *Agent) gatherDetailedServerMetrics() pbClient.ServerMetricsRecord {
-	collector := NewSystemCollector()
+func (a *Agent) gatherDetailedServerMetrics(ctx context.Context) pbClient.ServerMetricsRecord {
+	collector := a.collector
 	
 	// Get real memory data
 	ramUsed, ramTotal, ramPercentage := collector.GetMemoryUsage()
 	ramFree := ramTotal - ramUsed
 	
-	// Get accurate CPU data with improved calculation
+	// Get swap usage, reported separately from RAM
+	swapUsed, swapTotal, swapPercentage := collector.GetSwapUsage()
+	swapFree := swapTotal - swapUsed
+	
+	// Get per-device swap usage
+	var swapDevices []pbClient.SwapDeviceMetrics
+	for _, device := range collector.GetSwapDevices() {
+		swapDevices = append(swapDevices, pbClient.SwapDeviceMetrics{
+			Device:   device.Device,
+			Type:     device.Type,
+			Size:     device.Size,
+			Used:     device.Used,
+			Priority: device.Priority,
+		})
+	}
+	
+	// Get CPU data from the agent's background sampler
 	cpuUsage := collector.GetCPUUsage()
 	cpuFree := 100.0 - cpuUsage
+	cpuBreakdown := collector.GetCPUBreakdown()
+	cpuPerCore := collector.GetPerCoreCPUUsage()
+	
+	// Get load averages
+	load1, load5, load15 := collector.GetLoadAverage()
+	
+	// Scheduling latency shows CPU contention that utilization alone hides
+	schedLatency := collector.GetSchedulingLatency()
 	
 	// Get real disk data
 	diskUsed, diskTotal, diskPercentage := collector.GetDiskUsage()
 	diskFree := diskTotal - diskUsed
+	inodeUsed, inodeTotal, inodePercentage := collector.GetInodeUsage()
+	diskStatus := collector.GetDiskStatus()
+	if diskStatus == "stale" {
+		logging.Warnf("Disk %s is not responding (stale mount), skipping its usage", collector.getDiskRootPath())
+	}
 	
 	// Get real network data
 	networkStats := collector.GetNetworkStats()
 	
+	// Get per-interface network data, sorted for stable records
+	interfaceStats := collector.GetAllInterfaceStats()
+	interfaceNames := make([]string, 0, len(interfaceStats))
+	for name := range interfaceStats {
+		interfaceNames = append(interfaceNames, name)
+	}
+	sort.Strings(interfaceNames)
+	
+	networkUnit := a.config().NetworkUnit
+	var networkInterfaces []pbClient.InterfaceMetrics
+	for _, name := range interfaceNames {
+		stats := interfaceStats[name]
+		networkInterfaces = append(networkInterfaces, pbClient.InterfaceMetrics{
+			Interface: name,
+			RxBytes:   int64(stats.BytesReceived),
+			TxBytes:   int64(stats.BytesSent),
+			RxSpeed:   int64(stats.RxSpeed),
+			TxSpeed:   int64(stats.TxSpeed),
+			Rx:        formatNetworkSize(int64(stats.BytesReceived), networkUnit, ""),
+			Tx:        formatNetworkSize(int64(stats.BytesSent), networkUnit, ""),
+			RxRate:    formatNetworkSize(int64(stats.RxSpeed), networkUnit, "/s"),
+			TxRate:    formatNetworkSize(int64(stats.TxSpeed), networkUnit, "/s"),
+		})
+	}
+	
 	// Format values with units and proper precision
-	ramTotalStr := fmt.Sprintf("%.2f GB", float64(ramTotal)/1024/1024/1024)
-	ramUsedStr := fmt.Sprintf("%.2f GB (%.1f%%)", float64(ramUsed)/1024/1024/1024, ramPercentage)
-	ramFreeStr := fmt.Sprintf("%.2f GB", float64(ramFree)/1024/1024/1024)
+	memoryUnit := a.config().MemoryUnit
+	ramTotalStr := formatSize(ramTotal, memoryUnit)
+	ramUsedStr := fmt.Sprintf("%s (%.1f%%)", formatSize(ramUsed, memoryUnit), ramPercentage)
+	ramFreeStr := formatSize(ramFree, memoryUnit)
 	
-	cpuCoresStr := fmt.Sprintf("%d", runtime.NumCPU())
+	swapTotalStr := formatSize(swapTotal, memoryUnit)
+	swapUsedStr := fmt.Sprintf("%s (%.1f%%)", formatSize(swapUsed, memoryUnit), swapPercentage)
+	swapFreeStr := formatSize(swapFree, memoryUnit)
+	
+	cpuCoresStr := fmt.Sprintf("%d", collector.GetOnlineCPUCount())
 	cpuUsageStr := fmt.Sprintf("%.2f%%", cpuUsage)
 	cpuFreeStr := fmt.Sprintf("%.2f%%", cpuFree)
 	
-	diskTotalStr := fmt.Sprintf("%.2f GB", float64(diskTotal)/1024/1024/1024)
-	diskUsedStr := fmt.Sprintf("%.2f GB (%.1f%%)", float64(diskUsed)/1024/1024/1024, diskPercentage)
-	diskFreeStr := fmt.Sprintf("%.2f GB", float64(diskFree)/1024/1024/1024)
+	diskTotalStr := formatSize(diskTotal, a.config().DiskUnit)
+	diskUsedStr := fmt.Sprintf("%s (%.1f%%)", formatSize(diskUsed, a.config().DiskUnit), diskPercentage)
+	diskFreeStr := formatSize(diskFree, a.config().DiskUnit)
 	
-	return pbClient.ServerMetricsRecord{
-		ServerID:        a.config.AgentID,
+	// The strings above are for display; the numeric fields below carry raw bytes and
+	// percentages so the records can be charted and aggregated
+	record := pbClient.ServerMetricsRecord{
+		ServerID:        a.config().AgentID,
 		Timestamp:       time.Now(),
+		SchemaVersion:   pbClient.SchemaVersion,
 		RAMTotal:        ramTotalStr,
 		RAMUsed:         ramUsedStr,
 		RAMFree:         ramFreeStr,
+		RAMTotalBytes:   ramTotal,
+		RAMUsedBytes:    ramUsed,
+		RAMFreeBytes:    ramFree,
+		RAMUsedPercent:  math.Round(ramPercentage*10) / 10,
+		SwapTotal:       swapTotalStr,
+		SwapUsed:        swapUsedStr,
+		SwapFree:        swapFreeStr,
+		SwapTotalBytes:  swapTotal,
+		SwapUsedBytes:   swapUsed,
+		SwapFreeBytes:   swapFree,
+		SwapUsedPercent: math.Round(swapPercentage*10) / 10,
+		SwapDevices:     swapDevices,
 		CPUCores:        cpuCoresStr,
+		CPUCoreCount:    collector.GetOnlineCPUCount(),
+		CPUCoresEffective: collector.GetEffectiveCPUCount(),
 		CPUUsage:        cpuUsageStr,
 		CPUFree:         cpuFreeStr,
+		CPUUsagePercent: math.Round(cpuUsage*100) / 100,
+		CPUFreePercent:  math.Round(cpuFree*100) / 100,
+		CPUStealPercent:  cpuBreakdown.Steal,
+		CPUIOWaitPercent: cpuBreakdown.IOWait,
+		CPUBreakdown: &pbClient.CPUBreakdownMetrics{
+			User:    cpuBreakdown.User,
+			System:  cpuBreakdown.System,
+			IOWait:  cpuBreakdown.IOWait,
+			IRQ:     cpuBreakdown.IRQ,
+			SoftIRQ: cpuBreakdown.SoftIRQ,
+			Steal:   cpuBreakdown.Steal,
+			Idle:    cpuBreakdown.Idle,
+		},
+		CPUPerCore:      cpuPerCore,
+		Load1:           load1,
+		Load5:           load5,
+		Load15:          load15,
+		SchedDelayMs:    math.Round(schedLatency.AvgDelayMs*1000) / 1000,
+		CPUPressureSome: schedLatency.CPUPressure10,
 		DiskTotal:       diskTotalStr,
 		DiskUsed:        diskUsedStr,
 		DiskFree:        diskFreeStr,
+		DiskTotalBytes:  diskTotal,
+		DiskUsedBytes:   diskUsed,
+		DiskFreeBytes:   diskFree,
+		DiskUsedPercent: math.Round(diskPercentage*10) / 10,
+		DiskStatus:      diskStatus,
+		InodeTotal:      inodeTotal,
+		InodeUsed:       inodeUsed,
+		InodePercentage: math.Round(inodePercentage*10) / 10,
 		Status:          "healthy",
 		NetworkRxBytes:  int64(networkStats.BytesReceived),
 		NetworkTxBytes:  int64(networkStats.BytesSent),
-		NetworkRxSpeed:  int64(networkStats.PacketsReceived), // Now contains RX speed (bytes/sec)
-		NetworkTxSpeed:  int64(networkStats.PacketsSent),     // Now contains TX speed (bytes/sec)
+		NetworkRxSpeed:  int64(networkStats.RxSpeed),
+		NetworkTxSpeed:  int64(networkStats.TxSpeed),
+		NetworkRx:       formatNetworkSize(int64(networkStats.BytesReceived), networkUnit, ""),
+		NetworkTx:       formatNetworkSize(int64(networkStats.BytesSent), networkUnit, ""),
+		NetworkRxRate:   formatNetworkSize(int64(networkStats.RxSpeed), networkUnit, "/s"),
+		NetworkTxRate:   formatNetworkSize(int64(networkStats.TxSpeed), networkUnit, "/s"),
+		NetworkInterfaces: networkInterfaces,
+	}
+	
+	// System-wide file handles; exhaustion breaks unrelated services with "too many open files"
+	fdUsed, fdMax, fdPercentage := collector.GetFileHandleUsage()
+	record.FDUsed = fdUsed
+	record.FDMax = fdMax
+	record.ProcessCount, record.ThreadCount = collector.GetTaskCounts()
+	record.FDAlert = a.config().FileHandleWarnPercent > 0 && fdMax > 0 && fdPercentage >= float64(a.config().FileHandleWarnPercent)
+	if record.FDAlert {
+		logging.Warnf("System file handles are %.1f%% used (%d/%d)", fdPercentage, fdUsed, fdMax)
+	}
+	
+	// A surge of half-open connections points at a SYN flood or a broken client
+	record.TCPSynRecv = collector.GetTCPStateCounts()["SYN_RECV"]
+	record.TCPSynRecvAlert = a.config().SynRecvWarnThreshold > 0 && record.TCPSynRecv >= a.config().SynRecvWarnThreshold
+	if record.TCPSynRecvAlert {
+		logging.Warnf("%d TCP sockets in SYN_RECV, possible SYN flood", record.TCPSynRecv)
+	}
+	
+	// Conntrack is only present when the netfilter module is loaded
+	if count, max, percentage := collector.GetConntrackUsage(); max > 0 {
+		record.ConntrackCount = count
+		record.ConntrackMax = max
+		record.ConntrackPercentage = math.Round(percentage*10) / 10
+		record.ConntrackAlert = a.config().ConntrackWarnPercent > 0 && percentage >= float64(a.config().ConntrackWarnPercent)
+		if record.ConntrackAlert {
+			logging.Warnf("Conntrack table is %.1f%% full (%d/%d), new connections may be dropped", percentage, count, max)
+		}
+	}
+	
+	// First samples after startup have no prior snapshot, mark them so alerting can ignore them
+	if a.inWarmup() {
+		record.Status = "initializing"
+	}
+	
+	// Optional collectors
+	if a.config().EntropyMonitoringEnabled {
+		entropy := collector.GetEntropyInfo()
+		record.EntropyAvailable = entropy.Available
+		record.HWRNGSource = entropy.HWRNGSource
+		record.EntropySoftwareOnly = entropy.SoftwareOnly
+		if entropy.SoftwareOnly && entropy.Virtualized && !a.entropyWarned {
+			a.entropyWarned = true
+			logging.Warnf("VM has no hardware RNG source, relying solely on software entropy (%d bits available)", entropy.Available)
+		}
+	}
+	
+	// The busiest processes explain spikes without logging in
+	for _, process := range collector.GetTopProcesses(5, "cpu") {
+		record.TopProcesses = append(record.TopProcesses, pbClient.TopProcessMetrics{
+			PID:        process.PID,
+			Name:       process.Name,
+			CPUPercent: process.CPUPercent,
+			RSSBytes:   process.RSSBytes,
+		})
+	}
+	
+	// The agent's own footprint, for budgeting it across a fleet and catching goroutine leaks
+	self := a.getSelfUsage()
+	record.Agent = &pbClient.AgentMetrics{
+		CPUPercent:   self.CPUPercent,
+		RSSBytes:     self.RSSBytes,
+		PeakRSSBytes: self.PeakRSSBytes,
+		Threads:      self.Threads,
+		Goroutines:   self.Goroutines,
+	}
+	
+	// Thermal zones are absent on most VMs, leaving both fields empty
+	for _, zone := range collector.GetThermalZones() {
+		record.ThermalZones = append(record.ThermalZones, pbClient.ThermalZoneMetrics{
+			Zone:        zone.Zone,
+			Label:       zone.Label,
+			TempCelsius: zone.TempCelsius,
+		})
+		if zone.TempCelsius > record.CPUTemp {
+			record.CPUTemp = zone.TempCelsius
+		}
+	}
+	
+	if a.config().HugePagesMonitoringEnabled {
+		hugePages := collector.GetHugePages()
+		record.HugePagesTotal = hugePages.Total
+		record.HugePagesFree = hugePages.Free
+		record.HugePagesReserved = hugePages.Reserved
+		record.HugePageSize = hugePages.PageSize
+	}
+	
+	if a.config().FragmentationMonitoringEnabled {
+		buddyInfo, err := collector.GetBuddyInfo(a.config().FragmentationHighOrder)
+		if err != nil {
+			logging.Errorf("Failed to read buddy allocator info: %v", err)
+		} else {
+			record.BuddyFreeBlocks = buddyInfo.FreeBlocks
+			record.HighOrderFreePercent = math.Round(buddyInfo.HighOrderFreePercent*10) / 10
+			record.FragmentationAlert = buddyInfo.HighOrderFreePercent < float64(a.config().FragmentationWarnPercent)
+			if record.FragmentationAlert {
+				logging.Warnf("Memory is fragmented, only %.1f%% of free memory is in order-%d or larger blocks", buddyInfo.HighOrderFreePercent, a.config().FragmentationHighOrder)
+			}
+		}
+	}
+	
+	if len(a.config().MonitoredProcesses) > 0 {
+		for _, process := range collector.GetMonitoredProcesses(a.config().MonitoredProcesses) {
+			threadAlert := a.config().ProcessThreadThreshold > 0 && process.Threads > a.config().ProcessThreadThreshold
+			if threadAlert {
+				logging.Warnf("Process %s (PID %d) has %d threads, exceeding threshold of %d", process.Name, process.PID, process.Threads, a.config().ProcessThreadThreshold)
+			}
+			
+			record.MonitoredProcesses = append(record.MonitoredProcesses, pbClient.ProcessMetrics{
+				Name:        process.Name,
+				PID:         process.PID,
+				Threads:     process.Threads,
+				ThreadAlert: threadAlert,
+			})
+		}
+	}
+	
+	if a.config().LinkMonitoringEnabled {
+		for _, link := range collector.GetLinkStates() {
+			lowSpeed := a.config().MinLinkSpeedMbps > 0 && link.OperState == "up" && link.SpeedMbps > 0 && link.SpeedMbps < a.config().MinLinkSpeedMbps
+			if lowSpeed {
+				logging.Warnf("Interface %s negotiated %d Mbps, below expected %d Mbps", link.Interface, link.SpeedMbps, a.config().MinLinkSpeedMbps)
+			}
+			
+			record.NetworkLinks = append(record.NetworkLinks, pbClient.LinkMetrics{
+				Interface: link.Interface,
+				OperState: link.OperState,
+				SpeedMbps: link.SpeedMbps,
+				LowSpeed:  lowSpeed,
+			})
+		}
+	}
+	
+	if a.config().SystemdTimerMonitoringEnabled {
+		timers, err := collector.GetSystemdTimers(ctx)
+		if err != nil {
+			logging.Errorf("Failed to query systemd timers: %v", err)
+		}
+		
+		now := time.Now()
+		for _, timer := range timers {
+			if !timer.Overdue(now, a.config().SystemdTimerGrace) {
+				continue
+			}
+			
+			logging.Warnf("Systemd timer %s is overdue (state: %s, last trigger: %s)", timer.Unit, timer.ActiveState, formatOptionalTime(timer.LastTrigger))
+			record.OverdueTimers = append(record.OverdueTimers, pbClient.TimerMetrics{
+				Unit:        timer.Unit,
+				ActiveState: timer.ActiveState,
+				LastTrigger: formatOptionalTime(timer.LastTrigger),
+				NextElapse:  formatOptionalTime(timer.NextElapse),
+			})
+		}
+	}
+	
+	if a.config().StorageArrayMonitoringEnabled {
+		now := time.Now()
+		for _, array := range collector.GetStorageArrays(ctx) {
+			overdue := array.ScrubOverdue(now, a.config().ScrubMaxAge)
+			if overdue && array.NeverScrubbed {
+				logging.Warnf("%s array %s has never been scrubbed", array.Type, array.Name)
+			} else if overdue {
+				logging.Warnf("%s array %s has not been scrubbed since %s", array.Type, array.Name, formatOptionalTime(array.LastScrub))
+			}
+			if array.Operation != "none" {
+				logging.Debugf("%s array %s: %s %.1f%% complete", array.Type, array.Name, array.Operation, array.Progress)
+			}
+			
+			record.StorageArrays = append(record.StorageArrays, pbClient.StorageArrayMetrics{
+				Name:                array.Name,
+				Type:                array.Type,
+				Operation:           array.Operation,
+				Progress:            array.Progress,
+				EstimatedCompletion: formatOptionalTime(array.EstimatedCompletion),
+				LastScrub:           formatOptionalTime(array.LastScrub),
+				ScrubOverdue:        overdue,
+			})
+		}
 	}
+	
+	if a.config().CoreDumpMonitoringEnabled {
+		coreDumps := collector.GetCoreDumps()
+		record.CoreDumpsTotal = coreDumps.Count
+		record.LastCrashExecutable = coreDumps.LatestExecutable
+		
+		// The first cycle only establishes a baseline
+		if a.lastCoreDumps >= 0 && coreDumps.Count > a.lastCoreDumps {
+			record.CoreDumpsNew = coreDumps.Count - a.lastCoreDumps
+			logging.Warnf("%d new core dump(s), most recent from %s", record.CoreDumpsNew, coreDumps.LatestExecutable)
+		}
+		a.lastCoreDumps = coreDumps.Count
+	}
+	
+	if a.config().EgressQuotaEnabled {
+		egress := a.updateEgressUsage(networkStats.BytesSent, time.Now())
+		record.EgressBytes = int64(egress.Bytes)
+		record.EgressPeriodStart = egress.PeriodStart.Format(time.RFC3339)
+		if a.config().EgressQuotaGB > 0 {
+			quotaBytes := float64(a.config().EgressQuotaGB * unitSizes["GB"])
+			record.EgressQuotaPercent = float64(egress.Bytes) / quotaBytes * 100
+			record.EgressQuotaExceeded = record.EgressQuotaPercent >= 100
+		}
+	}
+	
+	if !a.dryRun {
+		a.evaluateAlerts(collector.GetRealHostname(), map[string]float64{
+			"cpu":    cpuUsage,
+			"memory": ramPercentage,
+			"disk":   diskPercentage,
+		}, time.Now())
+	}
+	
+	if a.statsd != nil {
+		record.CustomMetrics = a.statsd.flush()
+	}
+	record.CustomMetrics = append(record.CustomMetrics, a.runCustomMetrics(ctx)...)
+	
+	return record
+}
+
+// formatOptionalTime formats t as RFC3339, or returns an empty string for the zero time
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
 }
 
 func (a *Agent) sendServerMetrics(serverMetrics pbClient.ServerRecord) error {
@@ -125,20 +484,81 @@ func (a *Agent) sendServerMetrics(serverMetrics pbClient.ServerRecord) error {
 		return fmt.Errorf("no PocketBase client available")
 	}
 	
-	return a.pocketBase.SaveServerMetrics(serverMetrics)
+	return a.pocketBase.SaveServerMetrics(a.ctx, serverMetrics)
 }
 
-func (a *Agent) sendDetailedServerMetrics(metrics pbClient.ServerMetricsRecord) error {
+// sendDetailedServerMetrics sends the record together with any that failed to send earlier,
+// using a single batch request when there is a backlog. When the batch API is disabled or a
+// batch is rejected, the records are sent one by one instead.
+func (a *Agent) sendDetailedServerMetrics(ctx context.Context, metrics pbClient.ServerMetricsRecord) error {
 	if a.pocketBase == nil {
 		return fmt.Errorf("no PocketBase client available")
 	}
 	
-	return a.pocketBase.SaveServerMetricsRecord(metrics)
+	a.queueDetailedServerMetrics(metrics)
+	
+	if len(a.pendingMetrics) > 1 && !a.batchDisabled {
+		logging.Debugf("Sending %d server metrics records in a batch", len(a.pendingMetrics))
+		saved, err := a.pocketBase.SaveServerMetricsRecordsBatch(ctx, a.pendingMetrics)
+		
+		// Only keep the records PocketBase hasn't accepted
+		a.pendingMetrics = a.pendingMetrics[saved:]
+		
+		var statusErr *pbClient.StatusError
+		fallback := errors.As(err, &statusErr) && (statusErr.Rejected() || statusErr.StatusCode == http.StatusForbidden)
+		if !fallback {
+			if len(a.pendingMetrics) == 0 {
+				a.pendingMetrics = nil
+			}
+			return err
+		}
+		
+		// PocketBase disables /api/batch by default. A rejected batch is rolled back as a
+		// whole, so sending the records one by one finds the record that was refused.
+		if statusErr.StatusCode == http.StatusForbidden || statusErr.StatusCode == http.StatusNotFound {
+			logging.Warnf("PocketBase batch API is disabled (status %d), sending server metrics records one by one", statusErr.StatusCode)
+			a.batchDisabled = true
+		}
+	}
+	
+	return a.sendPendingMetricsOneByOne(ctx)
+}
+
+// sendPendingMetricsOneByOne sends the queued records in order, stopping at the first that
+// fails. Records PocketBase rejects are dropped, since resending them would block the rest
+// of the queue forever.
+func (a *Agent) sendPendingMetricsOneByOne(ctx context.Context) error {
+	var rejected error
+	for len(a.pendingMetrics) > 0 {
+		err := a.pocketBase.SaveServerMetricsRecord(ctx, a.pendingMetrics[0])
+		
+		var statusErr *pbClient.StatusError
+		if errors.As(err, &statusErr) && statusErr.Rejected() {
+			logging.Warnf("Dropping server metrics record for %s rejected by PocketBase: %v", a.pendingMetrics[0].ServerID, err)
+			rejected = err
+		} else if err != nil {
+			return err
+		}
+		a.pendingMetrics = a.pendingMetrics[1:]
+	}
+	
+	a.pendingMetrics = nil
+	return rejected
+}
+
+// queueDetailedServerMetrics adds the record to the unsent backlog, dropping the oldest
+// records beyond maxPendingMetrics
+func (a *Agent) queueDetailedServerMetrics(metrics pbClient.ServerMetricsRecord) {
+	a.pendingMetrics = append(a.pendingMetrics, metrics)
+	if len(a.pendingMetrics) > maxPendingMetrics {
+		dropped := len(a.pendingMetrics) - maxPendingMetrics
+		a.pendingMetrics = a.pendingMetrics[dropped:]
+		logging.Warnf("Dropped %d oldest unsent server metrics records", dropped)
+	}
 }
 
 func (a *Agent) getUptimeString() string {
-	collector := NewSystemCollector()
-	uptimeSeconds := collector.GetSystemUptime()
+	uptimeSeconds := a.collector.GetSystemUptime()
 	
 	days := uptimeSeconds / 86400
 	hours := (uptimeSeconds % 86400) / 3600
@@ -147,37 +567,41 @@ func (a *Agent) getUptimeString() string {
 	return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
 }
 
-func (a *Agent) gatherDockerContainers() []pbClient.DockerRecord {
-	var dockerRecords []pbClient.DockerRecord
-	
+// gatherDockerInfo collects the containers for the cycle's Docker records and metrics. It
+// returns an unavailable DockerInfo when Docker monitoring is disabled in PocketBase or
+// Docker isn't running.
+func (a *Agent) gatherDockerInfo(ctx context.Context, server *pbClient.ServerRecord) DockerInfo {
 	// Check if Docker monitoring is enabled in PocketBase AND Docker is available
-	if !a.serverRecord.Docker.Value {
-		log.Printf("Docker monitoring is disabled in PocketBase")
-		return dockerRecords // Return empty slice if Docker is disabled in PocketBase
+	if !server.Docker.Value {
+		logging.Debugf("Docker monitoring is disabled in PocketBase")
+		return DockerInfo{}
 	}
 	
-	collector := NewSystemCollector()
-	
 	// Check if Docker is actually available on the system
-	if !collector.IsDockerAvailable() {
-		log.Printf("Docker is not available on system, but monitoring is enabled in PocketBase")
-		return dockerRecords
+	if !a.collector.IsDockerAvailable() {
+		logging.Infof("Docker is not available on system, but monitoring is enabled in PocketBase")
+		return DockerInfo{}
 	}
 	
-	dockerInfo := collector.GetDockerInfo()
-	
+	dockerInfo := a.collector.GetDockerInfo(ctx)
 	if !dockerInfo.Available {
-		log.Printf("Docker info indicates Docker is not available")
-		return dockerRecords
+		logging.Debugf("Docker info indicates Docker is not available")
+	} else if len(dockerInfo.Containers) == 0 {
+		logging.Debugf("No Docker containers found")
+	} else {
+		logging.Debugf("Found %d Docker containers, collecting data", len(dockerInfo.Containers))
 	}
+	return dockerInfo
+}
+
+func (a *Agent) gatherDockerContainers(dockerInfo DockerInfo) []pbClient.DockerRecord {
+	var dockerRecords []pbClient.DockerRecord
 	
-	if len(dockerInfo.Containers) == 0 {
-		log.Printf("No Docker containers found")
+	if !dockerInfo.Available || len(dockerInfo.Containers) == 0 {
 		return dockerRecords
 	}
 	
-	log.Printf("Found %d Docker containers, collecting data", len(dockerInfo.Containers))
-	sysInfo := collector.GetSystemInfo()
+	sysInfo := a.collector.GetSystemInfo()
 	
 	for _, container := range dockerInfo.Containers {
 		dockerRecord := pbClient.DockerRecord{
@@ -185,7 +609,7 @@ func (a *Agent) gatherDockerContainers() []pbClient.DockerRecord {
 			Name:           container.Name,
 			Hostname:       sysInfo.Hostname,
 			IPAddress:      sysInfo.IPAddress,
-			OSTemplate:     fmt.Sprintf("Docker/%s", dockerInfo.Version),
+			OSTemplate:     fmt.Sprintf("%s/%s", dockerInfo.Runtime, dockerInfo.Version),
 			Uptime:         container.Uptime,
 			RAMTotal:       container.MemTotal,
 			RAMUsed:        container.MemUsage,
@@ -193,49 +617,34 @@ func (a *Agent) gatherDockerContainers() []pbClient.DockerRecord {
 			CPUUsage:       container.CPUUsage,
 			DiskTotal:      container.DiskTotal,
 			DiskUsed:       container.DiskUsage,
+			StatsAvailable: container.StatsAvailable,
+			RAMUnlimited:   container.MemLimit == 0,
 			LastChecked:    pbClient.FlexibleTime{Time: time.Now()},
 			Timestamp:      time.Now().Format(time.RFC3339),
 			Status:         container.Status,
+			ComposeProject: container.ComposeProject,
+			ComposeService: container.ComposeService,
+			RestartCount:   container.RestartCount,
+			LastExitCode:   container.LastExitCode,
+			Image:          container.Image,
+			ImageRepo:      container.ImageRepo,
+			ImageTag:       container.ImageTag,
 		}
 		
 		dockerRecords = append(dockerRecords, dockerRecord)
 	}
 	
-	log.Printf("Prepared %d Docker records for sending", len(dockerRecords))
+	logging.Debugf("Prepared %d Docker records for sending", len(dockerRecords))
 	return dockerRecords
 }
 
-func (a *Agent) gatherDockerMetrics() []pbClient.DockerMetricsRecord {
+func (a *Agent) gatherDockerMetrics(dockerInfo DockerInfo) []pbClient.DockerMetricsRecord {
 	var dockerMetrics []pbClient.DockerMetricsRecord
 	
-	// Check if Docker monitoring is enabled in PocketBase AND Docker is available
-	if !a.serverRecord.Docker.Value {
-		log.Printf("Docker monitoring is disabled in PocketBase")
-		return dockerMetrics // Return empty slice if Docker is disabled in PocketBase
-	}
-	
-	collector := NewSystemCollector()
-	
-	// Check if Docker is actually available on the system
-	if !collector.IsDockerAvailable() {
-		log.Printf("Docker is not available on system, but monitoring is enabled in PocketBase")
-		return dockerMetrics
-	}
-	
-	dockerInfo := collector.GetDockerInfo()
-	
-	if !dockerInfo.Available {
-		log.Printf("Docker info indicates Docker is not available")
+	if !dockerInfo.Available || len(dockerInfo.Containers) == 0 {
 		return dockerMetrics
 	}
 	
-	if len(dockerInfo.Containers) == 0 {
-		log.Printf("No Docker containers found for metrics")
-		return dockerMetrics
-	}
-	
-	log.Printf("Collecting metrics for %d Docker containers", len(dockerInfo.Containers))
-	
 	for _, container := range dockerInfo.Containers {
 		// Calculate derived values
 		ramFree := container.MemTotal - container.MemUsage
@@ -248,119 +657,125 @@ func (a *Agent) gatherDockerMetrics() []pbClient.DockerMetricsRecord {
 			cpuFree = 0
 		}
 		
-		diskFree := container.DiskTotal - container.DiskUsage
-		if diskFree < 0 {
-			diskFree = 0
-		}
-		
 		// Calculate percentages safely
-		var ramPercentage, diskPercentage float64
+		var ramPercentage float64
 		if container.MemTotal > 0 {
 			ramPercentage = float64(container.MemUsage) / float64(container.MemTotal) * 100
 		}
-		if container.DiskTotal > 0 {
-			diskPercentage = float64(container.DiskUsage) / float64(container.DiskTotal) * 100
-		}
 		
-		// Format values with units and proper precision
-		ramTotalStr := fmt.Sprintf("%.2f GB", float64(container.MemTotal)/1024/1024/1024)
-		ramUsedStr := fmt.Sprintf("%.2f GB (%.1f%%)", float64(container.MemUsage)/1024/1024/1024, ramPercentage)
-		ramFreeStr := fmt.Sprintf("%.2f GB", float64(ramFree)/1024/1024/1024)
+		// Format values with units and proper precision. Usage that wasn't measured, e.g. of a
+		// stopped container or when docker stats failed, is left empty rather than charted as 0.
+		var ramTotalStr, ramUsedStr, ramFreeStr, cpuUsageStr, cpuFreeStr string
+		if container.StatsAvailable {
+			ramTotalStr = formatSize(container.MemTotal, a.config().MemoryUnit)
+			ramUsedStr = fmt.Sprintf("%s (%.1f%%)", formatSize(container.MemUsage, a.config().MemoryUnit), ramPercentage)
+			ramFreeStr = formatSize(ramFree, a.config().MemoryUnit)
+			cpuUsageStr = fmt.Sprintf("%.2f%%", container.CPUUsage)
+			cpuFreeStr = fmt.Sprintf("%.2f%%", cpuFree)
+		}
 		
 		cpuCoresStr := fmt.Sprintf("%d", runtime.NumCPU())
-		cpuUsageStr := fmt.Sprintf("%.2f%%", container.CPUUsage)
-		cpuFreeStr := fmt.Sprintf("%.2f%%", cpuFree)
 		
-		diskTotalStr := fmt.Sprintf("%.2f GB", float64(container.DiskTotal)/1024/1024/1024)
-		diskUsedStr := fmt.Sprintf("%.2f GB (%.1f%%)", float64(container.DiskUsage)/1024/1024/1024, diskPercentage)
-		diskFreeStr := fmt.Sprintf("%.2f GB", float64(diskFree)/1024/1024/1024)
+		// The writable layer grows into the host filesystem, and the rest of SizeRootFs is the
+		// image, so there is no free space or usage percentage to report
+		diskTotalStr := formatSize(container.DiskTotal, a.config().DiskUnit)
+		diskUsedStr := formatSize(container.DiskUsage, a.config().DiskUnit)
 		
-		// Create Docker metrics record with real data
+		// Create Docker metrics record with measured data only
 		dockerMetric := pbClient.DockerMetricsRecord{
 			DockerID:        container.ID,
 			Timestamp:       time.Now(),
+			SchemaVersion:   pbClient.SchemaVersion,
 			RAMTotal:        ramTotalStr,
 			RAMUsed:         ramUsedStr,
 			RAMFree:         ramFreeStr,
 			CPUCores:        cpuCoresStr,
 			CPUUsage:        cpuUsageStr,
 			CPUFree:         cpuFreeStr,
+			StatsAvailable:  container.StatsAvailable,
+			RAMUnlimited:    container.MemLimit == 0,
 			DiskTotal:       diskTotalStr,
 			DiskUsed:        diskUsedStr,
-			DiskFree:        diskFreeStr,
 			Status:          container.Status,
 			NetworkRxBytes:  container.NetworkRxBytes,
 			NetworkTxBytes:  container.NetworkTxBytes,
 			NetworkRxSpeed:  container.NetworkRxSpeed,
 			NetworkTxSpeed:  container.NetworkTxSpeed,
+			NetworkRx:       formatNetworkSize(container.NetworkRxBytes, a.config().NetworkUnit, ""),
+			NetworkTx:       formatNetworkSize(container.NetworkTxBytes, a.config().NetworkUnit, ""),
+			NetworkRxRate:   formatNetworkSize(container.NetworkRxSpeed, a.config().NetworkUnit, "/s"),
+			NetworkTxRate:   formatNetworkSize(container.NetworkTxSpeed, a.config().NetworkUnit, "/s"),
+			BlkioLimited:    container.Blkio.Limited,
+			IOPressureFull:  container.Blkio.IOPressureFullUsec,
+			BlkioBytes:      container.Blkio.Bytes,
 		}
 		
 		dockerMetrics = append(dockerMetrics, dockerMetric)
 	}
 	
-	log.Printf("Prepared %d Docker metrics records for sending", len(dockerMetrics))
+	logging.Debugf("Prepared %d Docker metrics records for sending", len(dockerMetrics))
 	return dockerMetrics
 }
 
-func (a *Agent) sendDockerRecords(dockerRecords []pbClient.DockerRecord) error {
+func (a *Agent) sendDockerRecords(ctx context.Context, dockerRecords []pbClient.DockerRecord) error {
 	if a.pocketBase == nil {
 		return fmt.Errorf("no PocketBase client available")
 	}
 	
 	if len(dockerRecords) == 0 {
-		log.Printf("No Docker records to send")
+		logging.Debugf("No Docker records to send")
 		return nil
 	}
 	
-	log.Printf("Sending %d Docker records to PocketBase", len(dockerRecords))
+	logging.Debugf("Sending %d Docker records to PocketBase", len(dockerRecords))
 	
 	for _, docker := range dockerRecords {
 		// Try to find existing Docker record
-		existingDocker, err := a.pocketBase.GetDockerByID(docker.DockerID)
+		existingDocker, err := a.pocketBase.GetDockerByID(ctx, docker.DockerID)
 		if err != nil {
 			// Docker record doesn't exist, create new one
-			log.Printf("Creating new Docker record for container %s (%s)", docker.Name, docker.DockerID)
-			if err := a.pocketBase.SaveDockerRecord(docker); err != nil {
-				log.Printf("Failed to save docker record %s: %v", docker.DockerID, err)
+			logging.Debugf("Creating new Docker record for container %s (%s)", docker.Name, docker.DockerID)
+			if err := a.pocketBase.SaveDockerRecord(ctx, docker); err != nil {
+				logging.Errorf("Failed to save docker record %s: %v", docker.DockerID, err)
 				return fmt.Errorf("failed to save docker record %s: %v", docker.DockerID, err)
 			}
-			log.Printf("Successfully created Docker record for %s", docker.Name)
+			logging.Debugf("Successfully created Docker record for %s", docker.Name)
 		} else {
 			// Update existing Docker record
-			log.Printf("Updating existing Docker record for container %s (%s)", docker.Name, docker.DockerID)
-			if err := a.pocketBase.UpdateDockerRecord(existingDocker.ID, docker); err != nil {
-				log.Printf("Failed to update docker record %s: %v", docker.DockerID, err)
+			logging.Debugf("Updating existing Docker record for container %s (%s)", docker.Name, docker.DockerID)
+			if err := a.pocketBase.UpdateDockerRecord(ctx, existingDocker.ID, docker); err != nil {
+				logging.Errorf("Failed to update docker record %s: %v", docker.DockerID, err)
 				return fmt.Errorf("failed to update docker record %s: %v", docker.DockerID, err)
 			}
-			log.Printf("Successfully updated Docker record for %s", docker.Name)
+			logging.Debugf("Successfully updated Docker record for %s", docker.Name)
 		}
 	}
 	
-	log.Printf("Successfully sent all Docker records")
+	logging.Debugf("Successfully sent all Docker records")
 	return nil
 }
 
-func (a *Agent) sendDockerMetrics(dockerMetrics []pbClient.DockerMetricsRecord) error {
+func (a *Agent) sendDockerMetrics(ctx context.Context, dockerMetrics []pbClient.DockerMetricsRecord) error {
 	if a.pocketBase == nil {
 		return fmt.Errorf("no PocketBase client available")
 	}
 	
 	if len(dockerMetrics) == 0 {
-		log.Printf("No Docker metrics to send")
+		logging.Debugf("No Docker metrics to send")
 		return nil
 	}
 	
-	log.Printf("Sending %d Docker metrics records to PocketBase", len(dockerMetrics))
+	logging.Debugf("Sending %d Docker metrics records to PocketBase", len(dockerMetrics))
 	
 	for _, metric := range dockerMetrics {
-		log.Printf("Sending metrics for Docker container %s", metric.DockerID)
-		if err := a.pocketBase.SaveDockerMetricsRecord(metric); err != nil {
-			log.Printf("Failed to save docker metrics for %s: %v", metric.DockerID, err)
+		logging.Debugf("Sending metrics for Docker container %s", metric.DockerID)
+		if err := a.pocketBase.SaveDockerMetricsRecord(ctx, metric); err != nil {
+			logging.Errorf("Failed to save docker metrics for %s: %v", metric.DockerID, err)
 			return fmt.Errorf("failed to save docker metrics for %s: %v", metric.DockerID, err)
 		}
-		log.Printf("Successfully sent metrics for Docker container %s", metric.DockerID)
+		logging.Debugf("Successfully sent metrics for Docker container %s", metric.DockerID)
 	}
 	
-	log.Printf("Successfully sent all Docker metrics")
+	logging.Debugf("Successfully sent all Docker metrics")
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"monitoring-agent/config"
+	pbClient "monitoring-agent/pocketbase"
+)
+
+func TestSendDetailedServerMetricsKeepsOnlyUnsavedRecords(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	pb, err := pbClient.NewPocketBaseClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb.SetRetryPolicy(0, 0)
+	a := newTestAgent(&config.Config{})
+	a.pocketBase = pb
+	for i := 0; i < 69; i++ {
+		a.pendingMetrics = append(a.pendingMetrics, pbClient.ServerMetricsRecord{ServerID: "queued"})
+	}
+
+	if err := a.sendDetailedServerMetrics(context.Background(), pbClient.ServerMetricsRecord{ServerID: "latest"}); err == nil {
+		t.Fatal("sendDetailedServerMetrics succeeded although the second batch failed")
+	}
+	if len(a.pendingMetrics) != 20 {
+		t.Fatalf("%d records pending, want the 20 from the failed batch", len(a.pendingMetrics))
+	}
+	if last := a.pendingMetrics[len(a.pendingMetrics)-1]; last.ServerID != "latest" {
+		t.Errorf("last pending record is %q, want the latest one", last.ServerID)
+	}
+}
+
+// fakeMetricsServer serves server_metrics records, refusing those whose server_id is "bad",
+// with the batch API disabled as in a default PocketBase
+type fakeMetricsServer struct {
+	batchRequests int
+	saved         []string
+}
+
+func (f *fakeMetricsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/batch" {
+		f.batchRequests++
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	var record pbClient.ServerMetricsRecord
+	json.NewDecoder(r.Body).Decode(&record)
+	if record.ServerID == "bad" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.saved = append(f.saved, record.ServerID)
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestSendDetailedServerMetricsWithBatchAPIDisabled(t *testing.T) {
+	fake := &fakeMetricsServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	pb, err := pbClient.NewPocketBaseClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestAgent(&config.Config{})
+	a.pocketBase = pb
+	a.pendingMetrics = []pbClient.ServerMetricsRecord{{ServerID: "queued-1"}, {ServerID: "bad"}, {ServerID: "queued-2"}}
+
+	if err := a.sendDetailedServerMetrics(context.Background(), pbClient.ServerMetricsRecord{ServerID: "latest"}); err == nil {
+		t.Error("sendDetailedServerMetrics hid the rejected record")
+	}
+	if len(a.pendingMetrics) != 0 {
+		t.Errorf("%d records still pending, want the rejected one dropped and the rest sent", len(a.pendingMetrics))
+	}
+	want := []string{"queued-1", "queued-2", "latest"}
+	if fmt.Sprint(fake.saved) != fmt.Sprint(want) {
+		t.Errorf("saved records %v, want %v", fake.saved, want)
+	}
+
+	// The batch API isn't tried again once it answered 403
+	a.pendingMetrics = []pbClient.ServerMetricsRecord{{ServerID: "queued-3"}}
+	if err := a.sendDetailedServerMetrics(context.Background(), pbClient.ServerMetricsRecord{ServerID: "next"}); err != nil {
+		t.Fatalf("sendDetailedServerMetrics: %v", err)
+	}
+	if fake.batchRequests != 1 {
+		t.Errorf("batch API requested %d times, want once", fake.batchRequests)
+	}
+}
+
+func TestDockerRecordsAndMetricsFromOneSample(t *testing.T) {
+	a := newTestAgent(&config.Config{})
+	info := DockerInfo{
+		Available: true,
+		Runtime:   "Docker",
+		Version:   "27.0.1",
+		Containers: []DockerStats{
+			{ID: "web", Name: "web", Status: "running", StatsAvailable: true, NetworkRxSpeed: 400, NetworkTxSpeed: 100},
+		},
+	}
+
+	records := a.gatherDockerContainers(info)
+	metrics := a.gatherDockerMetrics(info)
+	if len(records) != 1 || records[0].DockerID != "web" || records[0].OSTemplate != "Docker/27.0.1" {
+		t.Fatalf("records = %+v, want one for web from the given info", records)
+	}
+	if len(metrics) != 1 || metrics[0].DockerID != "web" {
+		t.Fatalf("metrics = %+v, want one for web from the given info", metrics)
+	}
+	if metrics[0].NetworkRxSpeed != 400 || metrics[0].NetworkTxSpeed != 100 {
+		t.Errorf("network speeds = %d, %d; want the sample's 400, 100", metrics[0].NetworkRxSpeed, metrics[0].NetworkTxSpeed)
+	}
+
+	if records, metrics := a.gatherDockerContainers(DockerInfo{}), a.gatherDockerMetrics(DockerInfo{}); len(records) != 0 || len(metrics) != 0 {
+		t.Errorf("got %d records and %d metrics without Docker, want none", len(records), len(metrics))
+	}
+}
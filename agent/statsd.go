@@ -0,0 +1,235 @@
+package agent
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"monitoring-agent/logging"
+	pbClient "monitoring-agent/pocketbase"
+)
+
+const (
+	maxStatsdMetrics      = 1000      // Distinct metric names aggregated at once, further names are dropped
+	maxStatsdTimerSamples = 10000     // Samples kept per timer between flushes
+	statsdGaugeExpiry     = time.Hour // Gauges not updated for this long are no longer reported
+)
+
+// statsdListener receives StatsD metrics over UDP and aggregates them until the next flush
+type statsdListener struct {
+	conn *net.UDPConn
+
+	mu       sync.Mutex
+	counters map[string]float64
+	gauges   map[string]statsdGauge
+	timers   map[string][]float64
+
+	// Discarded since the last flush, logged once per flush instead of per line
+	droppedMetrics int
+	droppedSamples int
+	invalidLines   int
+	lastInvalid    string // Example of the invalid lines, with the parse error
+}
+
+// statsdGauge is a gauge's last value and when it was set
+type statsdGauge struct {
+	value   float64
+	updated time.Time
+}
+
+// newStatsDListener binds the StatsD UDP listener on the local interface
+func newStatsDListener(port int) (*statsdListener, error) {
+	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for StatsD on %s: %v", addr, err)
+	}
+
+	return &statsdListener{
+		conn:     conn,
+		counters: make(map[string]float64),
+		gauges:   make(map[string]statsdGauge),
+		timers:   make(map[string][]float64),
+	}, nil
+}
+
+// serve reads packets until the listener is closed
+func (s *statsdListener) serve() {
+	buf := make([]byte, 65535)
+	for {
+		n, _, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		s.handlePacket(string(buf[:n]))
+	}
+}
+
+// handlePacket aggregates the lines of one packet. Invalid lines are counted and logged
+// with the next flush, so a misbehaving client can't flood the log.
+func (s *statsdListener) handlePacket(packet string) {
+	for _, line := range strings.Split(packet, "\n") {
+		if err := s.handleLine(strings.TrimSpace(line)); err != nil {
+			s.mu.Lock()
+			s.invalidLines++
+			s.lastInvalid = fmt.Sprintf("%q: %v", line, err)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// close stops the listener
+func (s *statsdListener) close() {
+	s.conn.Close()
+}
+
+// handleLine parses a single "name:value|type[|@rate]" line into the aggregates
+func (s *statsdListener) handleLine(line string) error {
+	if line == "" {
+		return nil
+	}
+
+	colon := strings.LastIndex(line, ":")
+	if colon <= 0 {
+		return fmt.Errorf("missing value")
+	}
+	name := line[:colon]
+
+	parts := strings.Split(line[colon+1:], "|")
+	if len(parts) < 2 {
+		return fmt.Errorf("missing type")
+	}
+
+	rawValue := parts[0]
+	value, err := strconv.ParseFloat(rawValue, 64)
+	if err != nil {
+		return fmt.Errorf("invalid value: %v", err)
+	}
+
+	sampleRate := 1.0
+	if len(parts) > 2 && strings.HasPrefix(parts[2], "@") {
+		if rate, err := strconv.ParseFloat(parts[2][1:], 64); err == nil && rate > 0 {
+			sampleRate = rate
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch parts[1] {
+	case "c":
+		if _, ok := s.counters[name]; ok || s.admit() {
+			s.counters[name] += value / sampleRate
+		}
+	case "g":
+		gauge, ok := s.gauges[name]
+		if !ok && !s.admit() {
+			return nil
+		}
+		// A leading sign adjusts the current gauge instead of replacing it
+		if strings.HasPrefix(rawValue, "+") || strings.HasPrefix(rawValue, "-") {
+			gauge.value += value
+		} else {
+			gauge.value = value
+		}
+		gauge.updated = timeNow()
+		s.gauges[name] = gauge
+	case "ms", "h":
+		samples, ok := s.timers[name]
+		if !ok && !s.admit() {
+			return nil
+		}
+		if len(samples) >= maxStatsdTimerSamples {
+			s.droppedSamples++
+			return nil
+		}
+		s.timers[name] = append(samples, value)
+	default:
+		return fmt.Errorf("unsupported type %q", parts[1])
+	}
+
+	return nil
+}
+
+// admit reports whether there is room for another metric name, counting the metric as
+// dropped if not. Called with mu held.
+func (s *statsdListener) admit() bool {
+	if len(s.counters)+len(s.gauges)+len(s.timers) >= maxStatsdMetrics {
+		s.droppedMetrics++
+		return false
+	}
+	return true
+}
+
+// flush returns the metrics aggregated since the previous flush, sorted by name.
+// Counters and timers reset each cycle; gauges keep their last value until they expire.
+func (s *statsdListener) flush() []pbClient.CustomMetric {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logDiscarded()
+
+	var metrics []pbClient.CustomMetric
+
+	for name, value := range s.counters {
+		metrics = append(metrics, pbClient.CustomMetric{Name: name, Type: "counter", Value: value})
+	}
+	for name, gauge := range s.gauges {
+		if timeNow().Sub(gauge.updated) > statsdGaugeExpiry {
+			delete(s.gauges, name)
+			continue
+		}
+		metrics = append(metrics, pbClient.CustomMetric{Name: name, Type: "gauge", Value: gauge.value})
+	}
+	for name, samples := range s.timers {
+		metric := pbClient.CustomMetric{Name: name, Type: "timer", Count: len(samples), Min: samples[0], Max: samples[0]}
+		sum := 0.0
+		for _, sample := range samples {
+			sum += sample
+			if sample < metric.Min {
+				metric.Min = sample
+			}
+			if sample > metric.Max {
+				metric.Max = sample
+			}
+		}
+		metric.Value = sum / float64(len(samples))
+		metrics = append(metrics, metric)
+	}
+
+	s.counters = make(map[string]float64)
+	s.timers = make(map[string][]float64)
+
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Name != metrics[j].Name {
+			return metrics[i].Name < metrics[j].Name
+		}
+		return metrics[i].Type < metrics[j].Type
+	})
+
+	return metrics
+}
+
+// logDiscarded reports the lines and metrics dropped since the previous flush and resets
+// the counts. Called with mu held.
+func (s *statsdListener) logDiscarded() {
+	if s.invalidLines > 0 {
+		logging.Warnf("Ignored %d invalid StatsD lines, e.g. %s", s.invalidLines, s.lastInvalid)
+	}
+	if s.droppedMetrics > 0 {
+		logging.Warnf("Dropped %d StatsD values for new metric names beyond the limit of %d metrics", s.droppedMetrics, maxStatsdMetrics)
+	}
+	if s.droppedSamples > 0 {
+		logging.Warnf("Dropped %d StatsD timer samples beyond the limit of %d per timer", s.droppedSamples, maxStatsdTimerSamples)
+	}
+	s.invalidLines, s.lastInvalid, s.droppedMetrics, s.droppedSamples = 0, "", 0, 0
+}
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pbClient "monitoring-agent/pocketbase"
+)
+
+// newTestStatsD returns a listener without a socket, fed through handlePacket
+func newTestStatsD() *statsdListener {
+	return &statsdListener{
+		counters: make(map[string]float64),
+		gauges:   make(map[string]statsdGauge),
+		timers:   make(map[string][]float64),
+	}
+}
+
+func findMetric(metrics []pbClient.CustomMetric, name string) (pbClient.CustomMetric, bool) {
+	for _, metric := range metrics {
+		if metric.Name == name {
+			return metric, true
+		}
+	}
+	return pbClient.CustomMetric{}, false
+}
+
+func TestStatsDAggregation(t *testing.T) {
+	s := newTestStatsD()
+	s.handlePacket("hits:1|c\nhits:2|c|@0.5\nqueue:10|g\nqueue:-3|g\nlatency:20|ms\nlatency:40|ms")
+
+	metrics := s.flush()
+	if hits, _ := findMetric(metrics, "hits"); hits.Type != "counter" || hits.Value != 5 {
+		t.Errorf("hits = %+v, want counter 5", hits)
+	}
+	if queue, _ := findMetric(metrics, "queue"); queue.Type != "gauge" || queue.Value != 7 {
+		t.Errorf("queue = %+v, want gauge 7", queue)
+	}
+	if latency, _ := findMetric(metrics, "latency"); latency.Count != 2 || latency.Value != 30 || latency.Min != 20 || latency.Max != 40 {
+		t.Errorf("latency = %+v, want 2 samples averaging 30", latency)
+	}
+
+	// Only the gauge outlives the flush
+	if metrics := s.flush(); len(metrics) != 1 || metrics[0].Name != "queue" {
+		t.Errorf("second flush = %+v, want only the queue gauge", metrics)
+	}
+}
+
+func TestStatsDLimits(t *testing.T) {
+	s := newTestStatsD()
+
+	var lines []string
+	for i := 0; i < maxStatsdMetrics+10; i++ {
+		lines = append(lines, fmt.Sprintf("metric%d:1|c", i))
+	}
+	s.handlePacket(strings.Join(lines, "\n"))
+	s.handlePacket("late:1|g\nlate:1|ms")
+	s.handlePacket("metric0:1|c") // Known names keep aggregating
+	if s.droppedMetrics != 12 {
+		t.Errorf("dropped %d values, want 12 for names beyond the limit", s.droppedMetrics)
+	}
+
+	metrics := s.flush()
+	if len(metrics) != maxStatsdMetrics {
+		t.Errorf("flushed %d metrics, want the limit of %d", len(metrics), maxStatsdMetrics)
+	}
+	if metric, _ := findMetric(metrics, "metric0"); metric.Value != 2 {
+		t.Errorf("metric0 = %v, want 2", metric.Value)
+	}
+	if s.droppedMetrics != 0 {
+		t.Error("flush didn't reset the dropped count")
+	}
+
+	for i := 0; i < maxStatsdTimerSamples+5; i++ {
+		s.handlePacket("latency:1|ms")
+	}
+	if len(s.timers["latency"]) != maxStatsdTimerSamples || s.droppedSamples != 5 {
+		t.Errorf("kept %d samples and dropped %d, want %d and 5", len(s.timers["latency"]), s.droppedSamples, maxStatsdTimerSamples)
+	}
+}
+
+func TestStatsDGaugesExpire(t *testing.T) {
+	advance := fakeClock(t)
+	s := newTestStatsD()
+	s.handlePacket("stale:1|g\nfresh:1|g")
+
+	advance(statsdGaugeExpiry / 2)
+	s.handlePacket("fresh:2|g")
+	advance(statsdGaugeExpiry/2 + time.Minute)
+
+	metrics := s.flush()
+	if _, ok := findMetric(metrics, "stale"); ok {
+		t.Error("gauge not updated within the expiry was still reported")
+	}
+	if fresh, ok := findMetric(metrics, "fresh"); !ok || fresh.Value != 2 {
+		t.Errorf("fresh = %+v, want the recently updated gauge", fresh)
+	}
+	if _, ok := s.gauges["stale"]; ok {
+		t.Error("expired gauge was kept")
+	}
+}
+
+func TestStatsDInvalidLinesCounted(t *testing.T) {
+	s := newTestStatsD()
+	s.handlePacket("bad\nworse:x|c\nok:1|c\nodd:1|z")
+
+	if s.invalidLines != 3 || !strings.Contains(s.lastInvalid, "odd:1|z") {
+		t.Errorf("invalid lines = %d, last %s; want 3 ending with odd:1|z", s.invalidLines, s.lastInvalid)
+	}
+	if metrics := s.flush(); len(metrics) != 1 || metrics[0].Name != "ok" {
+		t.Errorf("flushed %+v, want only the valid line", metrics)
+	}
+	if s.invalidLines != 0 || s.lastInvalid != "" {
+		t.Error("flush didn't reset the invalid line count")
+	}
+}
@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StorageArray describes scrub and resilver state of an mdadm array or ZFS pool
+type StorageArray struct {
+	Name                string
+	Type                string    // "mdadm" or "zfs"
+	Operation           string    // Running operation (scrub, resilver, check, resync, recovery, reshape) or "none"
+	Progress            float64   // Percent complete of the running operation
+	EstimatedCompletion time.Time // Zero when nothing is running or no estimate is available
+	LastScrub           time.Time // Zero when unknown or never scrubbed
+	NeverScrubbed       bool      // ZFS reports "none requested" for pools that were never scrubbed
+}
+
+// ScrubOverdue reports whether the array hasn't completed a scrub within maxAge
+func (a StorageArray) ScrubOverdue(now time.Time, maxAge time.Duration) bool {
+	if maxAge <= 0 {
+		return false
+	}
+	if a.NeverScrubbed {
+		return true
+	}
+	return !a.LastScrub.IsZero() && now.Sub(a.LastScrub) > maxAge
+}
+
+var (
+	mdstatDeviceRe   = regexp.MustCompile(`^(md\d+)\s*:`)
+	mdstatProgressRe = regexp.MustCompile(`(resync|recovery|check|repair|reshape)\s*=\s*([0-9.]+)%.*?finish=([0-9.]+)min`)
+	zpoolScanDoneRe  = regexp.MustCompile(`(scrub repaired|resilvered) .* on (.+)$`)
+	zpoolSectionRe   = regexp.MustCompile(`^[a-z]+:`)
+	zpoolProgressRe  = regexp.MustCompile(`([0-9.]+)% done(?:, (?:(\d+) days? )?(\d+):(\d+):(\d+) to go)?`)
+)
+
+// getStorageArrays reports mdadm arrays and ZFS pools, skipping whichever isn't present
+func (sc *SystemCollector) getStorageArrays(ctx context.Context) []StorageArray {
+	arrays := sc.getMDArrays()
+	arrays = append(arrays, sc.getZFSPools(ctx)...)
+	return arrays
+}
+
+// getMDArrays parses /proc/mdstat for running check/resync/recovery operations.
+// The kernel keeps no record of when an md check last completed, so LastScrub stays zero.
+func (sc *SystemCollector) getMDArrays() []StorageArray {
+	file, err := os.Open("/proc/mdstat")
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var arrays []StorageArray
+	now := time.Now()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if match := mdstatDeviceRe.FindStringSubmatch(line); match != nil {
+			arrays = append(arrays, StorageArray{Name: match[1], Type: "mdadm", Operation: "none"})
+			continue
+		}
+
+		if len(arrays) == 0 {
+			continue
+		}
+		if match := mdstatProgressRe.FindStringSubmatch(line); match != nil {
+			current := &arrays[len(arrays)-1]
+			current.Operation = match[1]
+			current.Progress, _ = strconv.ParseFloat(match[2], 64)
+			if minutes, err := strconv.ParseFloat(match[3], 64); err == nil {
+				current.EstimatedCompletion = now.Add(time.Duration(minutes * float64(time.Minute)))
+			}
+		}
+	}
+
+	return arrays
+}
+
+// getZFSPools parses zpool status for scrub/resilver progress and the last completed scan
+func (sc *SystemCollector) getZFSPools(ctx context.Context) []StorageArray {
+	output, err := exec.CommandContext(ctx, "zpool", "status").Output()
+	if err != nil {
+		return nil
+	}
+	return parseZpoolStatus(string(output), time.Now())
+}
+
+// parseZpoolStatus parses the pool and scan sections of zpool status output
+func parseZpoolStatus(output string, now time.Time) []StorageArray {
+	var pools []StorageArray
+	inScan := false
+
+	for _, rawLine := range strings.Split(output, "\n") {
+		line := strings.TrimSpace(rawLine)
+
+		if strings.HasPrefix(line, "pool:") {
+			pools = append(pools, StorageArray{Name: strings.TrimSpace(strings.TrimPrefix(line, "pool:")), Type: "zfs", Operation: "none"})
+			inScan = false
+			continue
+		}
+		if len(pools) == 0 {
+			continue
+		}
+		current := &pools[len(pools)-1]
+
+		// The scan section is the "scan:" line plus its indented continuation lines
+		if strings.HasPrefix(line, "scan:") {
+			inScan = true
+			line = strings.TrimSpace(strings.TrimPrefix(line, "scan:"))
+		} else if zpoolSectionRe.MatchString(line) {
+			inScan = false
+		}
+		if !inScan {
+			continue
+		}
+
+		switch {
+		case line == "none requested":
+			current.NeverScrubbed = true
+		case strings.HasPrefix(line, "scrub in progress"):
+			current.Operation = "scrub"
+		case strings.HasPrefix(line, "resilver in progress"):
+			current.Operation = "resilver"
+		}
+
+		if match := zpoolScanDoneRe.FindStringSubmatch(line); match != nil && match[1] == "scrub repaired" {
+			if finished, err := time.ParseInLocation("Mon Jan 2 15:04:05 2006", strings.Join(strings.Fields(match[2]), " "), time.Local); err == nil {
+				current.LastScrub = finished
+			}
+		}
+
+		if match := zpoolProgressRe.FindStringSubmatch(line); match != nil {
+			current.Progress, _ = strconv.ParseFloat(match[1], 64)
+			if match[3] != "" {
+				days, _ := strconv.Atoi(match[2])
+				hours, _ := strconv.Atoi(match[3])
+				minutes, _ := strconv.Atoi(match[4])
+				seconds, _ := strconv.Atoi(match[5])
+				remaining := time.Duration(days)*24*time.Hour + time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+				current.EstimatedCompletion = now.Add(remaining)
+			}
+		}
+	}
+
+	return pools
+}
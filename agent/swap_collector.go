@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SwapDevice represents a single swap partition or file from /proc/swaps
+type SwapDevice struct {
+	Device   string
+	Type     string
+	Size     int64 // Bytes
+	Used     int64 // Bytes
+	Priority int
+}
+
+// getSwapDevices reads per-device swap usage from /proc/swaps
+func (sc *SystemCollector) getSwapDevices() []SwapDevice {
+	file, err := os.Open("/proc/swaps")
+	if err != nil {
+		return []SwapDevice{}
+	}
+	defer file.Close()
+
+	devices := []SwapDevice{}
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Skip header
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		// Size and Used are reported in KB
+		size, err1 := strconv.ParseInt(fields[2], 10, 64)
+		used, err2 := strconv.ParseInt(fields[3], 10, 64)
+		priority, err3 := strconv.Atoi(fields[4])
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+
+		devices = append(devices, SwapDevice{
+			Device:   strings.ReplaceAll(fields[0], `\040`, " "), // Spaces in paths are octal-escaped
+			Type:     fields[1],
+			Size:     size * 1024,
+			Used:     used * 1024,
+			Priority: priority,
+		})
+	}
+
+	return devices
+}
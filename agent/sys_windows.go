@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+	ntdll    = syscall.NewLazyDLL("ntdll.dll")
+
+	procGetSystemTimes           = kernel32.NewProc("GetSystemTimes")
+	procGetActiveProcessorCount  = kernel32.NewProc("GetActiveProcessorCount")
+	procGlobalMemoryStatusEx     = kernel32.NewProc("GlobalMemoryStatusEx")
+	procGetTickCount64           = kernel32.NewProc("GetTickCount64")
+	procGetDiskFreeSpaceExW      = kernel32.NewProc("GetDiskFreeSpaceExW")
+	procRtlGetVersion            = ntdll.NewProc("RtlGetVersion")
+	procNtQuerySystemInformation = ntdll.NewProc("NtQuerySystemInformation")
+)
+
+// filetimeTicks converts a FILETIME duration into 100ns ticks
+func filetimeTicks(ft syscall.Filetime) uint64 {
+	return uint64(ft.HighDateTime)<<32 | uint64(ft.LowDateTime)
+}
+
+// readRegistryString reads a string value below HKEY_LOCAL_MACHINE, returning "" when it's missing
+func readRegistryString(path, name string) string {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return ""
+	}
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return ""
+	}
+
+	var key syscall.Handle
+	if err := syscall.RegOpenKeyEx(syscall.HKEY_LOCAL_MACHINE, pathPtr, 0, syscall.KEY_READ, &key); err != nil {
+		return ""
+	}
+	defer syscall.RegCloseKey(key)
+
+	var valueType, size uint32
+	if err := syscall.RegQueryValueEx(key, namePtr, nil, &valueType, nil, &size); err != nil || size == 0 {
+		return ""
+	}
+	if valueType != syscall.REG_SZ && valueType != syscall.REG_EXPAND_SZ {
+		return ""
+	}
+
+	buf := make([]uint16, size/2+1)
+	if err := syscall.RegQueryValueEx(key, namePtr, nil, &valueType, (*byte)(unsafe.Pointer(&buf[0])), &size); err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(syscall.UTF16ToString(buf))
+}
@@ -0,0 +1,38 @@
+//go:build darwin || freebsd
+
+package agent
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// sysctlUint64 reads an unsigned integer sysctl. Some are a C int (FreeBSD's hw.pagesize and
+// vm.stats counters) and others 64-bit (hw.memsize), so both widths are accepted.
+func sysctlUint64(name string) (uint64, error) {
+	buf, err := unix.SysctlRaw(name)
+	if err != nil {
+		return 0, err
+	}
+
+	switch len(buf) {
+	case 4:
+		return uint64(binary.NativeEndian.Uint32(buf)), nil
+	case 8:
+		return binary.NativeEndian.Uint64(buf), nil
+	default:
+		return 0, fmt.Errorf("sysctl %s is %d bytes, not an integer", name, len(buf))
+	}
+}
+
+// getUptime returns system uptime in seconds, derived from kern.boottime
+func (sc *SystemCollector) getUptime() (int64, error) {
+	bootTime, err := unix.SysctlTimeval("kern.boottime")
+	if err != nil {
+		return 0, err
+	}
+	return int64(time.Since(time.Unix(bootTime.Unix())).Seconds()), nil
+}
@@ -2,16 +2,36 @@
 package agent
 
 import (
+	"context"
+	"sync"
 	"time"
 )
 
 // SystemCollector provides real system metrics
 type SystemCollector struct {
+	mu               sync.Mutex // Guards lazily initialized shared state and settings
+	deltaMu          sync.Mutex // Guards the previous samples that network, scheduling and process rates are computed from
 	lastCPUStats     CPUStats
+	lastPerCoreStats map[int]CPUStats
+	lastSchedStat    schedStat
+	schedStatInitialized bool
+	lastProcTimes    map[int]uint64 // Per-process utime+stime ticks from the previous sample
+	lastProcTime     time.Time
 	lastNetworkStats NetworkStats
 	lastNetworkTime  time.Time
+	lastInterfaceStats map[string]NetworkStats
+	lastInterfaceTime  time.Time
+	lastContainerNetwork map[string]containerNetworkSample // Per-container network counters from the previous sample
+	dockerAPI        *dockerAPIClient
+	dockerStatsConcurrency int
+	containerFilter  containerFilter   // Containers to monitor, the zero value keeps all
+	containerRuntime string            // Configured runtime: auto, docker or podman
+	diskRootPath     string            // Filesystem reported as the primary disk, "/" when unset
+	reportInterface  string            // Interface pinned for the reported IP and network stats
+	runtime          *containerRuntime // Detected runtime, nil until one is found
 	lastCPUTime      time.Time
 	initialized      bool
+	cpuSampler       *cpuSampler // Background CPU sampling, nil unless StartCPUSampling was called
 }
 
 type CPUStats struct {
@@ -39,13 +59,59 @@ type SystemInfo struct {
 	GoVersion       string
 	Platform        string
 	IPAddress       string
+	IPv6Address     string // Empty when the host has no global IPv6 address
 	OSType          string
+	DockerAvailable bool // Not detected by GetSystemInfo, set by callers that checked
 }
 
 func NewSystemCollector() *SystemCollector {
 	return &SystemCollector{}
 }
 
+// SetDockerStatsConcurrency sets how many containers have their stats collected in parallel
+func (sc *SystemCollector) SetDockerStatsConcurrency(n int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.dockerStatsConcurrency = n
+}
+
+// SetContainerFilter limits monitoring to containers whose name matches include and not
+// exclude, and that carry label ("key" or "key=value"). Empty values don't filter, and
+// patterns that don't compile are ignored since the configuration already rejects them.
+func (sc *SystemCollector) SetContainerFilter(include, exclude, label string) {
+	filter := newContainerFilter(include, exclude, label)
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.containerFilter = filter
+}
+
+// SetContainerRuntime forces the container runtime ("docker" or "podman"); "auto" probes both.
+// Changing it discards the previously detected runtime.
+func (sc *SystemCollector) SetContainerRuntime(runtime string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if runtime != sc.containerRuntime {
+		sc.runtime = nil
+		sc.dockerAPI = nil
+	}
+	sc.containerRuntime = runtime
+}
+
+// SetDiskRootPath sets the filesystem whose usage is reported as the primary disk
+func (sc *SystemCollector) SetDiskRootPath(path string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.diskRootPath = path
+}
+
+// SetReportInterface pins the reported IP address and network stats to the named interface.
+// An empty name restores auto-detection.
+func (sc *SystemCollector) SetReportInterface(name string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.reportInterface = name
+}
+
 // GetSystemInfo returns comprehensive system information
 func (sc *SystemCollector) GetSystemInfo() SystemInfo {
 	return sc.getSystemInfo()
@@ -56,27 +122,183 @@ func (sc *SystemCollector) GetRealHostname() string {
 	return sc.getRealHostname()
 }
 
-// GetCPUUsage returns real CPU usage percentage with proper timing and multiple samples
+// GetCPUUsage returns real CPU usage percentage, the latest background sample when
+// sampling runs or otherwise measured now with proper timing and multiple samples
 func (sc *SystemCollector) GetCPUUsage() float64 {
+	if usage, _, ok := sc.latestCPUUsage(); ok {
+		return usage
+	}
 	return sc.getCPUUsage()
 }
 
+// GetPerCoreCPUUsage returns usage percentages for each CPU core, ordered by core number
+func (sc *SystemCollector) GetPerCoreCPUUsage() []float64 {
+	if _, perCore, ok := sc.latestCPUUsage(); ok {
+		return perCore
+	}
+	return sc.getPerCoreCPUUsage()
+}
+
+// GetCPUBreakdown returns the percentage of CPU time spent in user, system, iowait, irq,
+// softirq, steal and idle
+func (sc *SystemCollector) GetCPUBreakdown() CPUBreakdown {
+	if breakdown, ok := sc.latestCPUBreakdown(); ok {
+		return breakdown
+	}
+	return sc.getCPUBreakdown()
+}
+
+// GetSchedulingLatency returns run-queue wait time and CPU pressure where the kernel exposes them
+func (sc *SystemCollector) GetSchedulingLatency() SchedulingLatency {
+	return sc.getSchedulingLatency()
+}
+
+// GetLoadAverage returns the 1, 5 and 15 minute load averages
+func (sc *SystemCollector) GetLoadAverage() (float64, float64, float64) {
+	return sc.getLoadAverage()
+}
+
 // GetMemoryUsage returns memory usage in bytes and percentage
 func (sc *SystemCollector) GetMemoryUsage() (used int64, total int64, percentage float64) {
 	return sc.getMemoryUsage()
 }
 
-// GetDiskUsage returns disk usage for root filesystem
+// GetSwapUsage returns swap usage in bytes and percentage
+func (sc *SystemCollector) GetSwapUsage() (used int64, total int64, percentage float64) {
+	return sc.getSwapUsage()
+}
+
+// GetHugePages returns huge page totals, free and reserved counts
+func (sc *SystemCollector) GetHugePages() HugePagesInfo {
+	return sc.getHugePages()
+}
+
+// GetBuddyInfo returns free page blocks per order and the share of free memory in blocks of at least highOrder
+func (sc *SystemCollector) GetBuddyInfo(highOrder int) (BuddyInfo, error) {
+	return sc.getBuddyInfo(highOrder)
+}
+
+// GetSwapDevices returns size, usage and priority for each swap device
+func (sc *SystemCollector) GetSwapDevices() []SwapDevice {
+	return sc.getSwapDevices()
+}
+
+// GetDiskUsage returns disk usage for the primary filesystem
 func (sc *SystemCollector) GetDiskUsage() (used int64, total int64, percentage float64) {
 	return sc.getDiskUsage()
 }
 
+// GetDiskStatus reports whether the primary filesystem is "ok", "stale" or "unavailable"
+func (sc *SystemCollector) GetDiskStatus() string {
+	return sc.getDiskStatus()
+}
+
+// GetInodeUsage returns inode usage for the primary filesystem
+func (sc *SystemCollector) GetInodeUsage() (used int64, total int64, percentage float64) {
+	return sc.getInodeUsage()
+}
+
 // GetNetworkStats returns real network statistics
 func (sc *SystemCollector) GetNetworkStats() NetworkStats {
 	return sc.getNetworkStats()
 }
 
+// GetFileHandleUsage returns system-wide open file handles and the configured maximum
+func (sc *SystemCollector) GetFileHandleUsage() (used int64, max int64, percentage float64) {
+	return sc.getFileHandleUsage()
+}
+
+// GetTCPStateCounts returns the number of TCP sockets in each connection state
+func (sc *SystemCollector) GetTCPStateCounts() map[string]int {
+	return sc.getTCPStateCounts()
+}
+
+// GetStorageArrays returns scrub and resilver state of mdadm arrays and ZFS pools
+func (sc *SystemCollector) GetStorageArrays(ctx context.Context) []StorageArray {
+	return sc.getStorageArrays(ctx)
+}
+
+// GetCoreDumps returns the number of core dumps on disk and the newest crashing executable
+func (sc *SystemCollector) GetCoreDumps() CoreDumpInfo {
+	return sc.getCoreDumps()
+}
+
+// GetConntrackUsage returns netfilter connection tracking table usage
+func (sc *SystemCollector) GetConntrackUsage() (count int64, max int64, percentage float64) {
+	return sc.getConntrackUsage()
+}
+
+// GetLinkStates returns operational state and link speed for each physical interface
+func (sc *SystemCollector) GetLinkStates() []LinkState {
+	return sc.getLinkStates()
+}
+
+// GetAllInterfaceStats returns network statistics for every non-loopback interface
+func (sc *SystemCollector) GetAllInterfaceStats() map[string]NetworkStats {
+	return sc.getAllInterfaceStats()
+}
+
 // GetSystemUptime returns system uptime in seconds
 func (sc *SystemCollector) GetSystemUptime() int64 {
 	return sc.getSystemUptime()
-}
\ No newline at end of file
+}
+
+// GetBootTime returns the time the host booted
+func (sc *SystemCollector) GetBootTime() time.Time {
+	return sc.getBootTime()
+}
+
+// GetOnlineCPUCount returns the number of online CPUs on the host
+func (sc *SystemCollector) GetOnlineCPUCount() int {
+	return sc.getOnlineCPUCount()
+}
+
+// GetEffectiveCPUCount returns the CPUs usable after affinity and cpuset restrictions
+func (sc *SystemCollector) GetEffectiveCPUCount() int {
+	return sc.getEffectiveCPUCount()
+}
+
+// GetKernelTaint returns the kernel taint mask and the names of the set flags
+func (sc *SystemCollector) GetKernelTaint() (int64, []string) {
+	return sc.getKernelTaint()
+}
+
+// GetTaskCounts returns the number of processes and threads on the host
+func (sc *SystemCollector) GetTaskCounts() (int, int) {
+	return sc.getTaskCounts()
+}
+
+// GetTopProcesses returns the n processes using the most "cpu" or "memory"
+func (sc *SystemCollector) GetTopProcesses(n int, sortBy string) []ProcessInfo {
+	return sc.getTopProcesses(n, sortBy)
+}
+
+// GetThermalZones returns the temperature of each thermal zone, empty when the host has none
+func (sc *SystemCollector) GetThermalZones() []ThermalZone {
+	return sc.getThermalZones()
+}
+
+// GetTimezone returns the host's configured timezone and current UTC offset
+func (sc *SystemCollector) GetTimezone() string {
+	return sc.getTimezone()
+}
+
+// GetLocale returns the agent process's effective locale
+func (sc *SystemCollector) GetLocale() string {
+	return sc.getLocale()
+}
+
+// GetEntropyInfo returns kernel entropy and hardware RNG health
+func (sc *SystemCollector) GetEntropyInfo() EntropyInfo {
+	return sc.getEntropyInfo()
+}
+
+// GetMonitoredProcesses returns thread counts for processes matching the given names
+func (sc *SystemCollector) GetMonitoredProcesses(names []string) []MonitoredProcess {
+	return sc.getMonitoredProcesses(names)
+}
+
+// GetSystemdTimers returns all systemd timer units with their last and next trigger times
+func (sc *SystemCollector) GetSystemdTimers(ctx context.Context) ([]SystemdTimer, error) {
+	return sc.getSystemdTimers(ctx)
+}
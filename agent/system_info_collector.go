@@ -3,6 +3,7 @@ package agent
 
 import (
 	"bufio"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"os"
@@ -19,10 +20,11 @@ func (sc *SystemCollector) getSystemInfo() SystemInfo {
 	info := SystemInfo{
 		Hostname:     hostname,
 		Architecture: runtime.GOARCH,
-		CPUCores:     runtime.NumCPU(),
+		CPUCores:     sc.getOnlineCPUCount(),
 		GoVersion:    runtime.Version(),
 		Platform:     runtime.GOOS,
 		IPAddress:    sc.getRealIPAddress(),
+		IPv6Address:  sc.getIPv6Address(),
 		OSType:       sc.getOSType(),
 	}
 	
@@ -59,20 +61,11 @@ func (sc *SystemCollector) getRealHostname() string {
 	return hostname
 }
 
-// getRealIPAddress returns the actual system IP address
+// getRealIPAddress returns the actual system IP address, the primary IPv6 address on hosts
+// without IPv4
 func (sc *SystemCollector) getRealIPAddress() string {
 	// Try to get the IP address from network interfaces
-	interfaces, err := net.Interfaces()
-	if err != nil {
-		return "unknown"
-	}
-
-	for _, iface := range interfaces {
-		// Skip loopback and down interfaces
-		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
-			continue
-		}
-
+	for _, iface := range sc.addressInterfaces() {
 		addrs, err := iface.Addrs()
 		if err != nil {
 			continue
@@ -94,102 +87,126 @@ func (sc *SystemCollector) getRealIPAddress() string {
 		}
 	}
 
+	if ipv6 := sc.getIPv6Address(); ipv6 != "" {
+		return ipv6
+	}
 	return "unknown"
 }
 
-// getOSType returns the operating system type
-func (sc *SystemCollector) getOSType() string {
-	switch runtime.GOOS {
-	case "linux":
-		return "Linux"
-	case "darwin":
-		return "macOS"
-	case "windows":
-		return "Windows"
-	case "freebsd":
-		return "FreeBSD"
-	case "openbsd":
-		return "OpenBSD"
-	case "netbsd":
-		return "NetBSD"
-	default:
-		return runtime.GOOS
-	}
-}
+// Address flags from /proc/net/if_inet6
+const (
+	ifaFlagTemporary  = 0x01 // Privacy extension address, rotated regularly
+	ifaFlagDeprecated = 0x20 // Preferred lifetime expired
+)
 
-// getOSInfo reads OS information from /etc/os-release
-func (sc *SystemCollector) getOSInfo() map[string]string {
-	file, err := os.Open("/etc/os-release")
-	if err != nil {
-		// Try alternative location
-		file, err = os.Open("/usr/lib/os-release")
+// getIPv6Address returns the primary global-scope IPv6 address, or "" when there is none.
+// Stable addresses are preferred over temporary or deprecated ones, which change over time,
+// and public addresses over unique local (fc00::/7) ones.
+func (sc *SystemCollector) getIPv6Address() string {
+	flags := readIPv6AddressFlags()
+
+	best, bestRank := "", 0
+	for _, iface := range sc.addressInterfaces() {
+		addrs, err := iface.Addrs()
 		if err != nil {
-			return nil
+			continue
 		}
-	}
-	defer file.Close()
 
-	osInfo := make(map[string]string)
-	scanner := bufio.NewScanner(file)
-	
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, "=") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				value := strings.Trim(strings.TrimSpace(parts[1]), `"`)
-				osInfo[key] = value
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() != nil || !ipNet.IP.IsGlobalUnicast() {
+				continue // IsGlobalUnicast excludes link-local and multicast addresses
+			}
+
+			rank := 4
+			if flags[ipNet.IP.String()]&(ifaFlagTemporary|ifaFlagDeprecated) != 0 {
+				rank -= 2
+			}
+			if ipNet.IP.IsPrivate() {
+				rank--
+			}
+			if rank > bestRank {
+				best, bestRank = ipNet.IP.String(), rank
 			}
 		}
 	}
-
-	return osInfo
+	return best
 }
 
-// getKernelVersion reads kernel version from /proc/version
-func (sc *SystemCollector) getKernelVersion() string {
-	file, err := os.Open("/proc/version")
+// readIPv6AddressFlags maps each IPv6 address in /proc/net/if_inet6 to its flags. It
+// returns nil where the file doesn't exist, leaving every address treated as stable.
+func readIPv6AddressFlags() map[string]uint64 {
+	file, err := os.Open("/proc/net/if_inet6")
 	if err != nil {
-		return ""
+		return nil
 	}
 	defer file.Close()
 
+	// Each line: address in hex, interface index, prefix length, scope, flags, name
+	flags := make(map[string]uint64)
 	scanner := bufio.NewScanner(file)
-	if scanner.Scan() {
-		version := scanner.Text()
-		// Extract version number from "Linux version x.x.x"
-		if strings.HasPrefix(version, "Linux version ") {
-			parts := strings.Fields(version)
-			if len(parts) >= 3 {
-				return parts[2]
-			}
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		raw, err := hex.DecodeString(fields[0])
+		if err != nil || len(raw) != net.IPv6len {
+			continue
 		}
+		value, err := strconv.ParseUint(fields[4], 16, 32)
+		if err != nil {
+			continue
+		}
+		flags[net.IP(raw).String()] = value
 	}
+	return flags
+}
 
-	return ""
+// Format renders the one-line summary stored in the server record's system_info, e.g.
+// "Ubuntu 22.04 | amd64 | Kernel: 5.15.0 | CPU: ... (4 cores) | RAM: 7.7 GB | Go go1.21 | IP: 10.0.0.5 | Docker: true".
+// DockerAvailable must be set by the caller.
+func (info SystemInfo) Format() string {
+	return fmt.Sprintf("%s %s | %s | Kernel: %s | CPU: %s (%d cores) | RAM: %.1f GB | Go %s | IP: %s | Docker: %t",
+		info.OSName,
+		info.OSVersion,
+		info.Architecture,
+		info.KernelVersion,
+		info.CPUModel,
+		info.CPUCores,
+		float64(info.TotalRAM)/1024/1024/1024,
+		info.GoVersion,
+		formatIPAddresses(info),
+		info.DockerAvailable,
+	)
 }
 
-// getCPUModel reads CPU model from /proc/cpuinfo
-func (sc *SystemCollector) getCPUModel() string {
-	file, err := os.Open("/proc/cpuinfo")
-	if err != nil {
-		return ""
+// formatIPAddresses lists the IPv4 and IPv6 addresses for the system info string
+func formatIPAddresses(info SystemInfo) string {
+	if info.IPv6Address == "" || info.IPv6Address == info.IPAddress {
+		return info.IPAddress
 	}
-	defer file.Close()
+	return info.IPAddress + ", " + info.IPv6Address
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "model name") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				return strings.TrimSpace(parts[1])
-			}
-		}
+// getOSType returns the operating system type
+func (sc *SystemCollector) getOSType() string {
+	switch runtime.GOOS {
+	case "linux":
+		return "Linux"
+	case "darwin":
+		return "macOS"
+	case "windows":
+		return "Windows"
+	case "freebsd":
+		return "FreeBSD"
+	case "openbsd":
+		return "OpenBSD"
+	case "netbsd":
+		return "NetBSD"
+	default:
+		return runtime.GOOS
 	}
-
-	return ""
 }
 
 // getSystemUptime returns system uptime in seconds
@@ -201,25 +218,3 @@ func (sc *SystemCollector) getSystemUptime() int64 {
 	}
 	return uptime
 }
-
-// getUptime reads system uptime from /proc/uptime
-func (sc *SystemCollector) getUptime() (int64, error) {
-	file, err := os.Open("/proc/uptime")
-	if err != nil {
-		return 0, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	if scanner.Scan() {
-		fields := strings.Fields(scanner.Text())
-		if len(fields) >= 1 {
-			uptime, err := strconv.ParseFloat(fields[0], 64)
-			if err == nil {
-				return int64(uptime), nil
-			}
-		}
-	}
-
-	return 0, fmt.Errorf("failed to parse uptime")
-}
\ No newline at end of file
@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// getOSInfo returns the macOS product version, keyed like /etc/os-release
+func (sc *SystemCollector) getOSInfo() map[string]string {
+	// kern.osproductversion exists from macOS 10.13.4
+	version, err := unix.Sysctl("kern.osproductversion")
+	if err != nil {
+		return nil
+	}
+
+	return map[string]string{
+		"NAME":    "macOS",
+		"VERSION": version,
+	}
+}
+
+// getKernelVersion returns the Darwin kernel release
+func (sc *SystemCollector) getKernelVersion() string {
+	release, err := unix.Sysctl("kern.osrelease")
+	if err != nil {
+		return ""
+	}
+	return release
+}
+
+// getCPUModel returns the CPU brand string
+func (sc *SystemCollector) getCPUModel() string {
+	model, err := unix.Sysctl("machdep.cpu.brand_string")
+	if err != nil {
+		return ""
+	}
+	return model
+}
@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// getOSInfo returns the FreeBSD release, keyed like /etc/os-release
+func (sc *SystemCollector) getOSInfo() map[string]string {
+	release, err := unix.Sysctl("kern.osrelease")
+	if err != nil {
+		return nil
+	}
+
+	return map[string]string{
+		"NAME":    "FreeBSD",
+		"VERSION": release,
+	}
+}
+
+// getKernelVersion returns the kernel release, e.g. 14.0-RELEASE-p3
+func (sc *SystemCollector) getKernelVersion() string {
+	release, err := unix.Sysctl("kern.osrelease")
+	if err != nil {
+		return ""
+	}
+	return release
+}
+
+// getCPUModel returns the CPU model from hw.model
+func (sc *SystemCollector) getCPUModel() string {
+	model, err := unix.Sysctl("hw.model")
+	if err != nil {
+		return ""
+	}
+	return model
+}
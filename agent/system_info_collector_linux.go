@@ -0,0 +1,108 @@
+//go:build linux
+
+package agent
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// getOSInfo reads OS information from /etc/os-release
+func (sc *SystemCollector) getOSInfo() map[string]string {
+	file, err := os.Open("/etc/os-release")
+	if err != nil {
+		// Try alternative location
+		file, err = os.Open("/usr/lib/os-release")
+		if err != nil {
+			return nil
+		}
+	}
+	defer file.Close()
+
+	osInfo := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.Contains(line, "=") {
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) == 2 {
+				key := strings.TrimSpace(parts[0])
+				value := strings.Trim(strings.TrimSpace(parts[1]), `"`)
+				osInfo[key] = value
+			}
+		}
+	}
+
+	return osInfo
+}
+
+// getKernelVersion reads kernel version from /proc/version
+func (sc *SystemCollector) getKernelVersion() string {
+	file, err := os.Open("/proc/version")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if scanner.Scan() {
+		version := scanner.Text()
+		// Extract version number from "Linux version x.x.x"
+		if strings.HasPrefix(version, "Linux version ") {
+			parts := strings.Fields(version)
+			if len(parts) >= 3 {
+				return parts[2]
+			}
+		}
+	}
+
+	return ""
+}
+
+// getCPUModel reads CPU model from /proc/cpuinfo
+func (sc *SystemCollector) getCPUModel() string {
+	file, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "model name") {
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) == 2 {
+				return strings.TrimSpace(parts[1])
+			}
+		}
+	}
+
+	return ""
+}
+
+// getUptime reads system uptime from /proc/uptime
+func (sc *SystemCollector) getUptime() (int64, error) {
+	file, err := os.Open("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 1 {
+			uptime, err := strconv.ParseFloat(fields[0], 64)
+			if err == nil {
+				return int64(uptime), nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("failed to parse uptime")
+}
@@ -0,0 +1,23 @@
+package agent
+
+import "testing"
+
+func TestSystemInfoFormat(t *testing.T) {
+	info := SystemInfo{
+		OSName:          "Ubuntu",
+		OSVersion:       "22.04",
+		Architecture:    "amd64",
+		KernelVersion:   "5.15.0",
+		CPUModel:        "Xeon",
+		CPUCores:        4,
+		TotalRAM:        8 * 1024 * 1024 * 1024,
+		GoVersion:       "go1.25",
+		IPAddress:       "10.0.0.5",
+		DockerAvailable: true,
+	}
+
+	want := "Ubuntu 22.04 | amd64 | Kernel: 5.15.0 | CPU: Xeon (4 cores) | RAM: 8.0 GB | Go go1.25 | IP: 10.0.0.5 | Docker: true"
+	if got := info.Format(); got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
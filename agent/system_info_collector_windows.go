@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// windowsVersionKey holds the product name and release of the installed Windows
+const windowsVersionKey = `SOFTWARE\Microsoft\Windows NT\CurrentVersion`
+
+// osVersionInfo mirrors RTL_OSVERSIONINFOW
+type osVersionInfo struct {
+	OSVersionInfoSize uint32
+	MajorVersion      uint32
+	MinorVersion      uint32
+	BuildNumber       uint32
+	PlatformID        uint32
+	CSDVersion        [128]uint16
+}
+
+// getOSInfo reads the product name and release from the registry, keyed like /etc/os-release
+func (sc *SystemCollector) getOSInfo() map[string]string {
+	name := readRegistryString(windowsVersionKey, "ProductName")
+	if name == "" {
+		return nil
+	}
+
+	// DisplayVersion (e.g. 22H2) replaced ReleaseId in Windows 10 20H2
+	version := readRegistryString(windowsVersionKey, "DisplayVersion")
+	if version == "" {
+		version = readRegistryString(windowsVersionKey, "ReleaseId")
+	}
+	if build := readRegistryString(windowsVersionKey, "CurrentBuild"); build != "" {
+		version = fmt.Sprintf("%s (build %s)", version, build)
+	}
+
+	return map[string]string{
+		"NAME":    name,
+		"VERSION": version,
+	}
+}
+
+// getKernelVersion returns the NT kernel version from RtlGetVersion, which unlike
+// GetVersionEx isn't capped at the version the binary's manifest declares
+func (sc *SystemCollector) getKernelVersion() string {
+	info := osVersionInfo{}
+	info.OSVersionInfoSize = uint32(unsafe.Sizeof(info))
+
+	if status, _, _ := procRtlGetVersion.Call(uintptr(unsafe.Pointer(&info))); status != 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d.%d.%d", info.MajorVersion, info.MinorVersion, info.BuildNumber)
+}
+
+// getCPUModel reads the processor name from the registry
+func (sc *SystemCollector) getCPUModel() string {
+	return readRegistryString(`HARDWARE\DESCRIPTION\System\CentralProcessor\0`, "ProcessorNameString")
+}
+
+// getUptime returns system uptime in seconds from GetTickCount64
+func (sc *SystemCollector) getUptime() (int64, error) {
+	if err := procGetTickCount64.Find(); err != nil {
+		return 0, err
+	}
+
+	ticks, _, _ := procGetTickCount64.Call()
+	return int64(ticks / 1000), nil
+}
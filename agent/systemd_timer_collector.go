@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SystemdTimer describes the schedule state of a systemd timer unit
+type SystemdTimer struct {
+	Unit          string
+	ActiveState   string
+	UnitFileState string
+	LastTrigger   time.Time
+	NextElapse    time.Time // Zero for monotonic-only timers
+}
+
+// Overdue reports whether an enabled timer has stopped firing on schedule
+func (t SystemdTimer) Overdue(now time.Time, grace time.Duration) bool {
+	if t.UnitFileState != "enabled" {
+		return false
+	}
+
+	// An enabled timer that isn't active will never fire
+	if t.ActiveState != "active" {
+		return true
+	}
+
+	return !t.NextElapse.IsZero() && now.After(t.NextElapse.Add(grace))
+}
+
+// getSystemdTimers queries systemctl for all timer units and their trigger times
+func (sc *SystemCollector) getSystemdTimers(ctx context.Context) ([]SystemdTimer, error) {
+	listOutput, err := exec.CommandContext(ctx, "systemctl", "list-units", "--type=timer", "--all", "--no-legend", "--plain", "--no-pager").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var units []string
+	for _, line := range strings.Split(string(listOutput), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && strings.HasSuffix(fields[0], ".timer") {
+			units = append(units, fields[0])
+		}
+	}
+	if len(units) == 0 {
+		return []SystemdTimer{}, nil
+	}
+
+	properties := []string{"-p", "Id", "-p", "ActiveState", "-p", "UnitFileState", "-p", "LastTriggerUSec", "-p", "NextElapseUSecRealtime"}
+	showOutput, err := exec.CommandContext(ctx, "systemctl", append(append([]string{"show", "--timestamp=unix"}, properties...), units...)...).Output()
+	if err != nil {
+		// Older systemd versions don't support --timestamp, use the default format
+		showOutput, err = exec.CommandContext(ctx, "systemctl", append(append([]string{"show"}, properties...), units...)...).Output()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return parseSystemdTimers(string(showOutput)), nil
+}
+
+// parseSystemdTimers parses systemctl show output, where units are separated by blank lines
+func parseSystemdTimers(output string) []SystemdTimer {
+	timers := []SystemdTimer{}
+
+	for _, block := range strings.Split(output, "\n\n") {
+		timer := SystemdTimer{}
+		for _, line := range strings.Split(block, "\n") {
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+
+			switch parts[0] {
+			case "Id":
+				timer.Unit = parts[1]
+			case "ActiveState":
+				timer.ActiveState = parts[1]
+			case "UnitFileState":
+				timer.UnitFileState = parts[1]
+			case "LastTriggerUSec":
+				timer.LastTrigger = parseSystemdTimestamp(parts[1])
+			case "NextElapseUSecRealtime":
+				timer.NextElapse = parseSystemdTimestamp(parts[1])
+			}
+		}
+
+		if timer.Unit != "" {
+			timers = append(timers, timer)
+		}
+	}
+
+	return timers
+}
+
+// parseSystemdTimestamp parses "@1704164645" or "Tue 2024-01-02 03:04:05 UTC", returning zero for "n/a"
+func parseSystemdTimestamp(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "n/a" || value == "0" {
+		return time.Time{}
+	}
+
+	if strings.HasPrefix(value, "@") {
+		seconds, err := strconv.ParseInt(strings.TrimPrefix(value, "@"), 10, 64)
+		if err != nil {
+			return time.Time{}
+		}
+		return time.Unix(seconds, 0)
+	}
+
+	parsed, err := time.ParseInLocation("Mon 2006-01-02 15:04:05 MST", value, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
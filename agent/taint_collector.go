@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// kernelTaintFlags names each bit of /proc/sys/kernel/tainted, in bit order
+var kernelTaintFlags = []string{
+	"proprietary_module",   // P
+	"forced_module_load",   // F
+	"unsafe_smp",           // S
+	"forced_module_unload", // R
+	"machine_check",        // M
+	"bad_page",             // B
+	"user_request",         // U
+	"kernel_oops",          // D
+	"acpi_overridden",      // A
+	"kernel_warning",       // W
+	"staging_driver",       // C
+	"firmware_workaround",  // I
+	"out_of_tree_module",   // O
+	"unsigned_module",      // E
+	"soft_lockup",          // L
+	"live_patched",         // K
+	"auxiliary",            // X
+	"randstruct_plugin",    // T
+	"test_module",          // N
+}
+
+// getKernelTaint reads the kernel taint mask and decodes it into flag names.
+// Returns zero and no flags when the file can't be read.
+func (sc *SystemCollector) getKernelTaint() (int64, []string) {
+	data, err := os.ReadFile("/proc/sys/kernel/tainted")
+	if err != nil {
+		return 0, nil
+	}
+	mask, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, nil
+	}
+
+	var flags []string
+	for bit, name := range kernelTaintFlags {
+		if mask&(1<<bit) != 0 {
+			flags = append(flags, name)
+		}
+	}
+	// Bits added by newer kernels are reported by number
+	for bit := len(kernelTaintFlags); bit < 63; bit++ {
+		if mask&(1<<bit) != 0 {
+			flags = append(flags, "bit_"+strconv.Itoa(bit))
+		}
+	}
+
+	return mask, flags
+}
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// tcpStateNames maps the hex state codes used in /proc/net/tcp to their names
+var tcpStateNames = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+// getTCPStateCounts counts IPv4 and IPv6 TCP sockets by connection state
+func (sc *SystemCollector) getTCPStateCounts() map[string]int {
+	counts := make(map[string]int)
+
+	for _, path := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		file, err := os.Open(path)
+		if err != nil {
+			continue // tcp6 is missing when IPv6 is disabled
+		}
+
+		scanner := bufio.NewScanner(file)
+		scanner.Scan() // Skip header
+		for scanner.Scan() {
+			// Format: sl local_address rem_address st ...
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 4 {
+				continue
+			}
+			if name, ok := tcpStateNames[strings.ToUpper(fields[3])]; ok {
+				counts[name]++
+			}
+		}
+		file.Close()
+	}
+
+	return counts
+}
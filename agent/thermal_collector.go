@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ThermalZone is one kernel thermal zone and its current temperature
+type ThermalZone struct {
+	Zone        string // e.g. "thermal_zone0"
+	Label       string // Zone type, e.g. "x86_pkg_temp" or "cpu-thermal"
+	TempCelsius float64
+}
+
+// getThermalZones reads every /sys/class/thermal zone, returning an empty slice when none exist
+func (sc *SystemCollector) getThermalZones() []ThermalZone {
+	zones := []ThermalZone{}
+
+	paths, _ := filepath.Glob("/sys/class/thermal/thermal_zone*")
+	sort.Strings(paths)
+	for _, path := range paths {
+		// temp is in millidegrees Celsius; unreadable zones (e.g. disabled sensors) are skipped
+		data, err := os.ReadFile(filepath.Join(path, "temp"))
+		if err != nil {
+			continue
+		}
+		milliCelsius, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		zone := ThermalZone{
+			Zone:        filepath.Base(path),
+			TempCelsius: float64(milliCelsius) / 1000,
+		}
+		if label, err := os.ReadFile(filepath.Join(path, "type")); err == nil {
+			zone.Label = strings.TrimSpace(string(label))
+		}
+		zones = append(zones, zone)
+	}
+
+	return zones
+}
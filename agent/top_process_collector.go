@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicksPerSecond is USER_HZ, which is 100 on every Linux platform Go supports
+const clockTicksPerSecond = 100
+
+// ProcessInfo describes a process's CPU and memory usage
+type ProcessInfo struct {
+	PID        int
+	Name       string
+	CPUPercent float64 // Share of one core since the previous sample
+	RSSBytes   int64
+}
+
+// getTopProcesses returns the n processes using the most "cpu" or "memory".
+// CPU usage needs two samples, so the first call takes a baseline and samples again shortly after.
+func (sc *SystemCollector) getTopProcesses(n int, sortBy string) []ProcessInfo {
+	sc.deltaMu.Lock()
+	defer sc.deltaMu.Unlock()
+
+	current := readProcessCPUTimes()
+	now := time.Now()
+
+	if sc.lastProcTimes == nil {
+		sc.lastProcTimes = current
+		sc.lastProcTime = now
+
+		time.Sleep(200 * time.Millisecond)
+
+		current = readProcessCPUTimes()
+		now = time.Now()
+	}
+
+	elapsed := now.Sub(sc.lastProcTime).Seconds()
+	processes := make([]ProcessInfo, 0, len(current))
+	for pid, ticks := range current {
+		// The process may have exited since its CPU times were read
+		status, err := readProcStatus(pid)
+		if err != nil {
+			continue
+		}
+
+		info := ProcessInfo{PID: pid, Name: status["Name"]}
+		if rss, err := strconv.ParseInt(strings.TrimSuffix(status["VmRSS"], " kB"), 10, 64); err == nil {
+			info.RSSBytes = rss * 1024
+		}
+		if previous, ok := sc.lastProcTimes[pid]; ok && elapsed > 0 && ticks >= previous {
+			info.CPUPercent = float64(ticks-previous) / clockTicksPerSecond / elapsed * 100
+		}
+		processes = append(processes, info)
+	}
+
+	sc.lastProcTimes = current
+	sc.lastProcTime = now
+
+	// Ties (mostly idle processes at 0% CPU) fall back to the other key for a stable order
+	sort.Slice(processes, func(i, j int) bool {
+		a, b := processes[i], processes[j]
+		if sortBy == "memory" {
+			if a.RSSBytes != b.RSSBytes {
+				return a.RSSBytes > b.RSSBytes
+			}
+			return a.CPUPercent > b.CPUPercent
+		}
+		if a.CPUPercent != b.CPUPercent {
+			return a.CPUPercent > b.CPUPercent
+		}
+		return a.RSSBytes > b.RSSBytes
+	})
+
+	if n >= 0 && len(processes) > n {
+		processes = processes[:n]
+	}
+	return processes
+}
+
+// parseProcStat returns the fields of a /proc/[pid]/stat line following the command name.
+// fields[0] is the state (field 3), so utime and stime (fields 14 and 15) are at 11 and 12.
+func parseProcStat(stat string) ([]string, error) {
+	// The command name may contain spaces or parentheses, so parse after the last ')'
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return nil, fmt.Errorf("no command name in stat line")
+	}
+	return strings.Fields(stat[end+1:]), nil
+}
+
+// readProcessCPUTimes returns utime+stime in clock ticks for every process in /proc
+func readProcessCPUTimes() map[int]uint64 {
+	times := make(map[int]uint64)
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return times
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		// Vanished processes simply fail to read and are skipped
+		data, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+
+		fields, err := parseProcStat(string(data))
+		if err != nil || len(fields) < 13 {
+			continue
+		}
+
+		utime, err1 := strconv.ParseUint(fields[11], 10, 64)
+		stime, err2 := strconv.ParseUint(fields[12], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		times[pid] = utime + stime
+	}
+
+	return times
+}
@@ -0,0 +1,14 @@
+package agent
+
+// metricsSink receives each cycle's summary metrics on the transports other than PocketBase
+type metricsSink interface {
+	SendMetrics(metrics SystemMetrics) error
+}
+
+// grpcMetricsClient pushes summary metrics when TRANSPORT=grpc. The implementation lives
+// behind the grpc build tag, which keeps gRPC and protobuf out of the default binary.
+type grpcMetricsClient interface {
+	metricsSink
+	IsConnected() bool
+	Close() error
+}
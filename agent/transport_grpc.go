@@ -0,0 +1,80 @@
+//go:build grpc
+
+package agent
+
+import (
+	"monitoring-agent/config"
+	agentgrpc "monitoring-agent/grpc"
+	"monitoring-agent/logging"
+	pb "monitoring-agent/proto"
+)
+
+// grpcSender adapts the gRPC client to grpcMetricsClient
+type grpcSender struct {
+	client *agentgrpc.GRPCClient
+	stream *agentgrpc.MetricsStream // Set when GRPC_STREAM_METRICS is enabled
+}
+
+// newGRPCMetricsClient connects to GRPC_SERVER_ADDR with the configured credentials
+func newGRPCMetricsClient(cfg *config.Config) (grpcMetricsClient, error) {
+	var opts []agentgrpc.ClientOption
+	if cfg.GRPCTLSEnabled {
+		opts = append(opts, agentgrpc.WithTLS(cfg.GRPCCACert))
+	} else if cfg.GRPCInsecure {
+		opts = append(opts, agentgrpc.WithInsecure())
+	}
+	if cfg.GRPCAuthToken != "" {
+		opts = append(opts, agentgrpc.WithAuthToken(cfg.GRPCAuthToken))
+	}
+
+	client, err := agentgrpc.NewGRPCClient(cfg.GRPCServerAddr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	sender := &grpcSender{client: client}
+	if cfg.GRPCStream {
+		sender.stream = client.StreamMetrics()
+	}
+	return sender, nil
+}
+
+// SendMetrics maps SystemMetrics onto a MetricsRequest and sends it
+func (s *grpcSender) SendMetrics(metrics SystemMetrics) error {
+	req := &pb.MetricsRequest{
+		AgentId:     metrics.AgentID,
+		Timestamp:   metrics.Timestamp.Unix(),
+		CpuUsage:    metrics.CPUUsage,
+		MemoryUsage: metrics.MemoryUsage,
+		DiskUsage:   metrics.DiskUsage,
+		NetworkStats: &pb.NetworkStats{
+			BytesSent:       metrics.NetworkStats.BytesSent,
+			BytesReceived:   metrics.NetworkStats.BytesReceived,
+			PacketsSent:     metrics.NetworkStats.PacketsSent,
+			PacketsReceived: metrics.NetworkStats.PacketsReceived,
+		},
+		Uptime:     metrics.Uptime,
+		GoRoutines: int32(metrics.GoRoutines),
+		Status:     metrics.Status,
+	}
+
+	if s.stream != nil {
+		return s.stream.Send(req)
+	}
+	_, err := s.client.SendMetrics(req)
+	return err
+}
+
+// IsConnected reports whether the gRPC connection is ready
+func (s *grpcSender) IsConnected() bool {
+	return s.client.IsConnected()
+}
+
+// Close ends the metrics stream, if any, and closes the gRPC connection
+func (s *grpcSender) Close() error {
+	if s.stream != nil {
+		if _, err := s.stream.Close(); err != nil {
+			logging.Warnf("Failed to close gRPC metrics stream: %v", err)
+		}
+	}
+	return s.client.Close()
+}
@@ -0,0 +1,14 @@
+//go:build !grpc
+
+package agent
+
+import (
+	"fmt"
+
+	"monitoring-agent/config"
+)
+
+// newGRPCMetricsClient fails in builds without the grpc tag
+func newGRPCMetricsClient(cfg *config.Config) (grpcMetricsClient, error) {
+	return nil, fmt.Errorf("this build does not include gRPC support, rebuild with -tags grpc")
+}
@@ -0,0 +1,35 @@
+package agent
+
+import "fmt"
+
+// unitSizes maps the configurable output units to their size in bytes
+var unitSizes = map[string]int64{
+	"bytes": 1,
+	"KB":    1 << 10,
+	"MB":    1 << 20,
+	"GB":    1 << 30,
+	"TB":    1 << 40,
+}
+
+// formatSize formats a byte count in the given unit, e.g. "1.50 GB"; "bytes" prints the raw count
+func formatSize(bytes int64, unit string) string {
+	size, ok := unitSizes[unit]
+	if !ok {
+		unit, size = "GB", unitSizes["GB"]
+	}
+	if size == 1 {
+		return fmt.Sprintf("%d", bytes)
+	}
+	return fmt.Sprintf("%.2f %s", float64(bytes)/float64(size), unit)
+}
+
+// formatNetworkSize formats a network counter or, with suffix "/s", a rate in the
+// NETWORK_UNIT for the display fields, e.g. "1.50 GB" or "12.00 MB/s". The *_bytes and
+// *_speed fields always stay in bytes; with the default "bytes" unit there is nothing to
+// add, so it returns "".
+func formatNetworkSize(bytes int64, unit, suffix string) string {
+	if _, ok := unitSizes[unit]; !ok || unit == "bytes" {
+		return ""
+	}
+	return formatSize(bytes, unit) + suffix
+}
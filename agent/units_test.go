@@ -0,0 +1,44 @@
+package agent
+
+import "testing"
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		unit  string
+		want  string
+	}{
+		{1536 << 20, "GB", "1.50 GB"},
+		{1536, "KB", "1.50 KB"},
+		{1023, "MB", "0.00 MB"},
+		{123456, "bytes", "123456"},
+		{3 << 30, "parsecs", "3.00 GB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSize(tt.bytes, tt.unit); got != tt.want {
+			t.Errorf("formatSize(%d, %q) = %q, want %q", tt.bytes, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNetworkSizeKeepsFractions(t *testing.T) {
+	tests := []struct {
+		bytes  int64
+		unit   string
+		suffix string
+		want   string
+	}{
+		// 1.9 MB used to be reported as 1 in network_rx_bytes
+		{1992294, "MB", "", "1.90 MB"},
+		{512 << 10, "MB", "/s", "0.50 MB/s"},
+		{123456, "bytes", "", ""},
+		{123456, "bytes", "/s", ""},
+	}
+
+	for _, tt := range tests {
+		if got := formatNetworkSize(tt.bytes, tt.unit, tt.suffix); got != tt.want {
+			t.Errorf("formatNetworkSize(%d, %q, %q) = %q, want %q", tt.bytes, tt.unit, tt.suffix, got, tt.want)
+		}
+	}
+}
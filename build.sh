@@ -174,7 +174,7 @@ make clean
 # Generate protobuf files if protoc is available
 if command -v protoc >/dev/null 2>&1; then
     echo "Generating protobuf files..."
-    protoc --go_out=. --go-grpc_out=. proto/monitoring.proto 2>/dev/null || {
+    protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative proto/monitoring.proto 2>/dev/null || {
         echo "Warning: Failed to generate protobuf files. Using existing files."
     }
 else
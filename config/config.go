@@ -1,17 +1,26 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
-	"log"
 	"net"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	"monitoring-agent/logging"
+
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
+	// Transport metrics are pushed over: pocketbase, http, grpc or influxdb
+	Transport    string
+	
 	// Server configuration
 	ServerURL    string
 	APIKey       string
@@ -19,22 +28,118 @@ type Config struct {
 	// PocketBase configuration
 	PocketBaseEnabled bool
 	PocketBaseURL     string
+	PocketBaseTimeout      time.Duration // Overall timeout of each PocketBase request
+	PocketBaseMaxIdleConns int           // Keep-alive connections kept open to PocketBase
+	PocketBaseCACert       string        // PEM file with CA certificates trusted for PocketBase
+	PocketBaseInsecureSkipVerify bool    // Skip TLS certificate verification (lab use only)
+	RegistrationTimeout    time.Duration // How long startup retries registering the server record
+	
+	// gRPC configuration
+	GRPCServerAddr string
+	GRPCTLSEnabled bool   // Encrypt the connection, verifying the server against GRPC_CA_CERT or the system pool
+	GRPCCACert     string // PEM file with CA certificates trusted for the gRPC server
+	GRPCAuthToken  string // Sent as a bearer token in the metadata of every RPC
+	GRPCInsecure   bool   // Must be set to connect without TLS
+	GRPCStream     bool   // Push metrics over one long-lived StreamMetrics RPC instead of a call per sample
+	
+	// InfluxDB configuration
+	InfluxDBURL    string
+	InfluxDBBucket string
+	InfluxDBOrg    string
+	InfluxDBToken  string // Sent as "Authorization: Token <token>"
 	
 	// Monitoring intervals
 	CheckInterval      time.Duration
 	ReportInterval     time.Duration
 	CommandCheckInterval time.Duration
+	MinCheckInterval     time.Duration // Floor applied to check intervals from the server record
+	MaxCheckInterval     time.Duration // Ceiling applied to check intervals from the server record
+	StatusPollInterval   time.Duration // How often the server record is fetched for pause state and check interval
+	PausedPollInterval   time.Duration // Server status poll interval while the server is paused
+	CheckIntervalJitter  int           // Random offset of up to this percent applied to the check interval
+	CollectionBudgetPercent int // Shed optional collectors when a cycle exceeds this share of the interval
+	CollectionTimeout    time.Duration // Abandon a collection cycle that runs longer than this (0 disables)
+	WarmupCycles         int    // Cycles reported as "initializing" after startup
 	
 	// Agent configuration
 	AgentID          string
+	AgentIDs         []string // Every AGENT_ID entry; AgentID is the first
 	MaxRetries       int
+	RetryBackoffBase time.Duration // Delay before the first retry, doubled after each attempt
 	RequestTimeout   time.Duration
+	StateDir         string // Directory for state persisted across restarts
+	DryRun           bool   // Print the records that would be sent and exit
+	
+	// Logging
+	LogFormat        string // text or json
+	LogLevel         string // debug, info, warn or error
+	LogMaxSizeMB     int    // Rotate the log file at this size (0 disables rotation)
+	LogMaxBackups    int    // Rotated files kept (0 keeps all)
+	LogMaxAgeDays    int    // Delete rotated files older than this (0 keeps all)
 	
 	// Health check configuration
 	HealthCheckPort  int
+	HealthCheckBind  string // Address the health server listens on, empty for all interfaces
+	ControlAuthToken string // Bearer token required by /control endpoints, empty leaves them open
+	HealthCheckTLSCert string // PEM certificate for serving the health server over HTTPS
+	HealthCheckTLSKey  string // PEM private key matching HealthCheckTLSCert
 	
 	// Remote control
 	RemoteControlEnabled bool
+	RemoteExecAllowlist  []string // Exact command lines the exec remote command may run (empty runs nothing)
+	
+	// Output units: bytes, KB, MB, GB or TB
+	MemoryUnit   string // RAM and swap strings
+	DiskUnit     string // Disk strings
+	NetworkUnit  string // Network display strings, the byte counters and speeds stay in bytes
+	
+	// Disk collection
+	DiskRootPath string // Filesystem reported as the primary disk
+	
+	// Network collection
+	ReportInterface string // Interface the reported IP and network stats come from, auto-detected when empty
+	
+	// Docker collection
+	ContainerRuntime       string // auto, docker or podman
+	DockerStatsConcurrency int // Containers whose stats are collected in parallel
+	DockerIncludePattern   string // Regex a container name must match to be monitored, empty matches all
+	DockerExcludePattern   string // Regex of container names to skip, empty skips none
+	DockerLabelFilter      string // label or label=value a container must carry, empty matches all
+	
+	// Optional collectors
+	EntropyMonitoringEnabled bool
+	MonitoredProcesses       []string // Process names to report thread counts for
+	ProcessThreadThreshold   int      // Warn when a monitored process exceeds this many threads (0 disables)
+	LinkMonitoringEnabled    bool
+	MinLinkSpeedMbps         int64    // Flag links negotiated below this speed (0 disables)
+	ConntrackWarnPercent     int      // Warn when the conntrack table exceeds this usage
+	FileHandleWarnPercent    int      // Warn when system-wide file handles exceed this usage
+	SynRecvWarnThreshold     int      // Warn when this many TCP sockets are in SYN_RECV (0 disables)
+	HugePagesMonitoringEnabled bool
+	FragmentationMonitoringEnabled bool
+	FragmentationHighOrder   int // Smallest allocation order counted as a high-order block
+	FragmentationWarnPercent int // Warn when less free memory than this is in high-order blocks
+	SystemdTimerMonitoringEnabled bool
+	SystemdTimerGrace        time.Duration // How late a timer may fire before it is reported as overdue
+	StorageArrayMonitoringEnabled bool
+	ScrubMaxAge              time.Duration // Flag arrays whose last scrub is older than this (0 disables)
+	CoreDumpMonitoringEnabled bool
+	KernelTaintMonitoringEnabled bool
+	EgressQuotaEnabled       bool
+	EgressQuotaGB            int64 // Monthly outbound quota, 0 tracks usage without a quota
+	EgressResetDay           int   // Day of the month (1-28) the quota period starts
+	StatsDEnabled            bool
+	StatsDPort               int // Local UDP port for application StatsD metrics
+	CustomMetrics            []CustomMetricCommand // Commands whose output is reported as custom metrics
+	CustomMetricTimeout      time.Duration         // How long each custom metric command may run
+	
+	// Alerting
+	AlertWebhookURL    string        // Receives alerts as JSON POSTs, empty disables alerting
+	AlertWebhookType   string        // Payload shape: generic, slack or discord
+	AlertCPUPercent    int           // Thresholds in percent, 0 disables each one
+	AlertMemoryPercent int
+	AlertDiskPercent   int
+	AlertDuration      time.Duration // How long a threshold must stay breached before the alert fires
 	
 	// Server identification - for server registration
 	ServerName   string
@@ -44,7 +149,49 @@ type Config struct {
 	ServerToken  string
 }
 
+var (
+	envFileOverride string            // Set by SetEnvFile, replaces the default search locations
+	overrides       map[string]string // Set by SetOverrides, applied over the environment
+	processEnv      map[string]string // Process environment before the first load added the file's values
+)
+
+// SetEnvFile makes Load read only the given environment file and fail when it can't be read
+func SetEnvFile(path string) {
+	envFileOverride = path
+}
+
+// SetOverrides sets values, keyed by environment variable name, that take precedence over
+// both the environment file and the process environment, e.g. from command-line flags
+func SetOverrides(values map[string]string) {
+	overrides = values
+}
+
 func Load() (*Config, error) {
+	return load(false)
+}
+
+// Reload loads the configuration again for SIGHUP. Values from the environment file
+// replace those the previous load set, otherwise edits to the file made since startup
+// would be ignored. Variables from the process environment still take precedence.
+func Reload() (*Config, error) {
+	return load(true)
+}
+
+func load(overrideEnv bool) (*Config, error) {
+	if processEnv == nil {
+		processEnv = make(map[string]string)
+		for _, entry := range os.Environ() {
+			if key, value, ok := strings.Cut(entry, "="); ok {
+				processEnv[key] = value
+			}
+		}
+	}
+
+	loadEnv := godotenv.Load
+	if overrideEnv {
+		loadEnv = godotenv.Overload
+	}
+
 	// Try to load environment file from multiple locations
 	envFiles := []string{
 		"/etc/monitoring-agent/monitoring-agent.env",
@@ -53,18 +200,48 @@ func Load() (*Config, error) {
 		"monitoring-agent.env",
 	}
 
+	// An explicitly configured environment file must exist
+	if envFileOverride != "" {
+		if _, err := os.Stat(envFileOverride); err != nil {
+			return nil, fmt.Errorf("environment file %s: %v", envFileOverride, err)
+		}
+		envFiles = []string{envFileOverride}
+	}
+
 	envLoaded := false
+	if isYAMLFile(envFileOverride) {
+		if err := loadYAML(envFileOverride, overrideEnv); err != nil {
+			return nil, err
+		}
+		logging.Infof("Successfully loaded YAML config file: %s", envFileOverride)
+		envLoaded = true
+	}
 	for _, envFile := range envFiles {
-		if err := godotenv.Load(envFile); err == nil {
-			log.Printf("Successfully loaded environment file: %s", envFile)
+		if envLoaded {
+			break
+		}
+		if err := loadEnv(envFile); err == nil {
+			logging.Infof("Successfully loaded environment file: %s", envFile)
 			envLoaded = true
 			break
 		}
 	}
 
 	if !envLoaded {
-		log.Printf("Warning: No environment file found in any of these locations: %v", envFiles)
-		log.Printf("Using system environment variables only")
+		logging.Warnf("No environment file found in any of these locations: %v", envFiles)
+		logging.Infof("Using system environment variables only")
+	}
+
+	// Reloading overwrote the environment with the file's values, restore the real ones
+	if overrideEnv {
+		for key, value := range processEnv {
+			os.Setenv(key, value)
+		}
+	}
+
+	// Command-line overrides win over everything loaded above
+	for key, value := range overrides {
+		os.Setenv(key, value)
 	}
 
 	// Auto-detect hostname if not set
@@ -90,13 +267,13 @@ func Load() (*Config, error) {
 	}
 
 	// Log some environment variables for debugging (without sensitive data)
-	log.Printf("Environment check:")
-	log.Printf("  - AGENT_ID: %s", getEnvSafe("AGENT_ID"))
-	log.Printf("  - POCKETBASE_ENABLED: %s", getEnvSafe("POCKETBASE_ENABLED"))
-	log.Printf("  - POCKETBASE_URL: %s", getEnvSafe("POCKETBASE_URL"))
-	log.Printf("  - SERVER_NAME: %s", getEnvSafe("SERVER_NAME"))
-	log.Printf("  - HOSTNAME (detected): %s", hostname)
-	log.Printf("  - IP_ADDRESS (detected): %s", ipAddress)
+	logging.Infof("Environment check:")
+	logging.Infof("  - AGENT_ID: %s", getEnvSafe("AGENT_ID"))
+	logging.Infof("  - POCKETBASE_ENABLED: %s", getEnvSafe("POCKETBASE_ENABLED"))
+	logging.Infof("  - POCKETBASE_URL: %s", getEnvSafe("POCKETBASE_URL"))
+	logging.Infof("  - SERVER_NAME: %s", getEnvSafe("SERVER_NAME"))
+	logging.Infof("  - HOSTNAME (detected): %s", hostname)
+	logging.Infof("  - IP_ADDRESS (detected): %s", ipAddress)
 
 	cfg := &Config{
 		// Basic configuration with minimal defaults
@@ -104,14 +281,95 @@ func Load() (*Config, error) {
 		APIKey:               getEnv("API_KEY", ""),
 		PocketBaseEnabled:    getBoolEnv("POCKETBASE_ENABLED", true), // Default to true
 		PocketBaseURL:        getEnv("POCKETBASE_URL", ""),
+		PocketBaseTimeout:      getDurationEnv("POCKETBASE_TIMEOUT", 30*time.Second),
+		PocketBaseMaxIdleConns: getIntEnv("POCKETBASE_MAX_IDLE_CONNS", 100),
+		PocketBaseCACert:       getEnv("POCKETBASE_CA_CERT", ""),
+		PocketBaseInsecureSkipVerify: getBoolEnv("POCKETBASE_INSECURE_SKIP_VERIFY", false),
+		RegistrationTimeout:    getDurationEnv("REGISTRATION_TIMEOUT", 2*time.Minute),
+		GRPCServerAddr:       getEnv("GRPC_SERVER_ADDR", "localhost:50051"),
+		GRPCTLSEnabled:       getBoolEnv("GRPC_TLS_ENABLED", true),
+		GRPCCACert:           getEnv("GRPC_CA_CERT", ""),
+		GRPCAuthToken:        getEnv("GRPC_AUTH_TOKEN", ""),
+		InfluxDBURL:          getEnv("INFLUXDB_URL", ""),
+		InfluxDBBucket:       getEnv("INFLUXDB_BUCKET", ""),
+		InfluxDBOrg:          getEnv("INFLUXDB_ORG", ""),
+		InfluxDBToken:        getEnv("INFLUXDB_TOKEN", ""),
+		GRPCInsecure:         getBoolEnv("GRPC_INSECURE", false),
+		GRPCStream:           getBoolEnv("GRPC_STREAM_METRICS", false),
 		CheckInterval:        getDurationEnv("CHECK_INTERVAL", 30*time.Second),
 		ReportInterval:       getDurationEnv("REPORT_INTERVAL", 5*time.Minute),
 		CommandCheckInterval: getDurationEnv("COMMAND_CHECK_INTERVAL", 10*time.Second),
+		MinCheckInterval:     getDurationEnv("MIN_CHECK_INTERVAL", 5*time.Second),
+		MaxCheckInterval:     getDurationEnv("MAX_CHECK_INTERVAL", time.Hour),
+		StatusPollInterval:   getDurationEnv("STATUS_POLL_INTERVAL", time.Minute),
+		PausedPollInterval:   getDurationEnv("PAUSED_POLL_INTERVAL", 10*time.Second),
+		CheckIntervalJitter:  getIntEnv("CHECK_INTERVAL_JITTER", 0),
+		CollectionBudgetPercent: getIntEnv("COLLECTION_BUDGET_PERCENT", 0),
+		CollectionTimeout:    getDurationEnv("COLLECTION_TIMEOUT", 25*time.Second),
+		WarmupCycles:         getIntEnv("WARMUP_CYCLES", 0),
 		AgentID:              getEnv("AGENT_ID", "monitoring-agent-001"), // Provide default
 		MaxRetries:           getIntEnv("MAX_RETRIES", 3),
+		RetryBackoffBase:     getDurationEnv("RETRY_BACKOFF_BASE", time.Second),
 		RequestTimeout:       getDurationEnv("REQUEST_TIMEOUT", 10*time.Second),
+		StateDir:             getEnv("STATE_DIR", "/var/lib/monitoring-agent"),
+		DryRun:               getBoolEnv("DRY_RUN", false),
+		LogFormat:            strings.ToLower(getEnv("LOG_FORMAT", "text")),
+		LogLevel:             strings.ToLower(getEnv("LOG_LEVEL", "info")),
+		LogMaxSizeMB:         getIntEnv("LOG_MAX_SIZE_MB", 100),
+		LogMaxBackups:        getIntEnv("LOG_MAX_BACKUPS", 5),
+		LogMaxAgeDays:        getIntEnv("LOG_MAX_AGE_DAYS", 30),
 		HealthCheckPort:      getIntEnv("HEALTH_CHECK_PORT", 8081),
+		HealthCheckBind:      getEnv("HEALTH_CHECK_BIND", ""),
+		ControlAuthToken:     getEnv("CONTROL_AUTH_TOKEN", ""),
+		HealthCheckTLSCert:   getEnv("HEALTH_CHECK_TLS_CERT", ""),
+		HealthCheckTLSKey:    getEnv("HEALTH_CHECK_TLS_KEY", ""),
 		RemoteControlEnabled: getBoolEnv("REMOTE_CONTROL_ENABLED", true), // Default to true
+		RemoteExecAllowlist:  getListEnv("REMOTE_EXEC_ALLOWLIST"),
+		MemoryUnit:           getUnitEnv("MEMORY_UNIT", "GB"),
+		DiskUnit:             getUnitEnv("DISK_UNIT", "GB"),
+		NetworkUnit:          getUnitEnv("NETWORK_UNIT", "bytes"),
+		DiskRootPath:         getEnv("DISK_ROOT_PATH", "/"),
+		ReportInterface:      getEnv("REPORT_INTERFACE", ""),
+		ContainerRuntime:     strings.ToLower(getEnv("CONTAINER_RUNTIME", "auto")),
+		DockerStatsConcurrency: getIntEnv("DOCKER_STATS_CONCURRENCY", 4),
+		DockerIncludePattern: getEnv("DOCKER_INCLUDE_PATTERN", ""),
+		DockerExcludePattern: getEnv("DOCKER_EXCLUDE_PATTERN", ""),
+		DockerLabelFilter:    getEnv("DOCKER_LABEL_FILTER", ""),
+		
+		// Optional collectors
+		EntropyMonitoringEnabled: getBoolEnv("ENTROPY_MONITORING_ENABLED", false),
+		MonitoredProcesses:       getListEnv("MONITORED_PROCESSES"),
+		ProcessThreadThreshold:   getIntEnv("PROCESS_THREAD_THRESHOLD", 0),
+		LinkMonitoringEnabled:    getBoolEnv("LINK_MONITORING_ENABLED", false),
+		MinLinkSpeedMbps:         int64(getIntEnv("MIN_LINK_SPEED_MBPS", 0)),
+		ConntrackWarnPercent:     getIntEnv("CONNTRACK_WARN_PERCENT", 90),
+		FileHandleWarnPercent:    getIntEnv("FILE_HANDLE_WARN_PERCENT", 90),
+		SynRecvWarnThreshold:     getIntEnv("SYN_RECV_WARN_THRESHOLD", 256),
+		HugePagesMonitoringEnabled: getBoolEnv("HUGEPAGES_MONITORING_ENABLED", false),
+		FragmentationMonitoringEnabled: getBoolEnv("FRAGMENTATION_MONITORING_ENABLED", false),
+		FragmentationHighOrder:   getIntEnv("FRAGMENTATION_HIGH_ORDER", 4),
+		FragmentationWarnPercent: getIntEnv("FRAGMENTATION_WARN_PERCENT", 5),
+		SystemdTimerMonitoringEnabled: getBoolEnv("SYSTEMD_TIMER_MONITORING_ENABLED", false),
+		SystemdTimerGrace:        getDurationEnv("SYSTEMD_TIMER_GRACE", 15*time.Minute),
+		StorageArrayMonitoringEnabled: getBoolEnv("STORAGE_ARRAY_MONITORING_ENABLED", false),
+		ScrubMaxAge:              getDurationEnv("SCRUB_MAX_AGE", 35*24*time.Hour),
+		CoreDumpMonitoringEnabled: getBoolEnv("CORE_DUMP_MONITORING_ENABLED", false),
+		KernelTaintMonitoringEnabled: getBoolEnv("KERNEL_TAINT_MONITORING_ENABLED", true),
+		EgressQuotaEnabled:       getBoolEnv("EGRESS_QUOTA_ENABLED", false),
+		EgressQuotaGB:            int64(getIntEnv("EGRESS_QUOTA_GB", 0)),
+		EgressResetDay:           getIntEnv("EGRESS_RESET_DAY", 1),
+		StatsDEnabled:            getBoolEnv("STATSD_ENABLED", false),
+		StatsDPort:               getIntEnv("STATSD_PORT", 8125),
+		CustomMetrics:            getCustomMetricsEnv("CUSTOM_METRICS"),
+		CustomMetricTimeout:      getDurationEnv("CUSTOM_METRIC_TIMEOUT", 5*time.Second),
+		
+		// Alerting
+		AlertWebhookURL:    getEnv("ALERT_WEBHOOK_URL", ""),
+		AlertWebhookType:   strings.ToLower(getEnv("ALERT_WEBHOOK_TYPE", "generic")),
+		AlertCPUPercent:    getIntEnv("ALERT_CPU_PERCENT", 0),
+		AlertMemoryPercent: getIntEnv("ALERT_MEMORY_PERCENT", 0),
+		AlertDiskPercent:   getIntEnv("ALERT_DISK_PERCENT", 0),
+		AlertDuration:      getDurationEnv("ALERT_DURATION", 5*time.Minute),
 		
 		// Server identification - use detected values as fallbacks
 		ServerName:   getEnv("SERVER_NAME", hostname), // Use hostname as fallback
@@ -121,6 +379,24 @@ func Load() (*Config, error) {
 		ServerToken:  getEnv("SERVER_TOKEN", ""),
 	}
 
+	// AGENT_ID may list several logical servers reported from this host, the first is the agent's own
+	cfg.AgentIDs = getListEnv("AGENT_ID")
+	if len(cfg.AgentIDs) > 0 {
+		cfg.AgentID = cfg.AgentIDs[0]
+	} else {
+		cfg.AgentIDs = []string{cfg.AgentID}
+	}
+
+	// Without TRANSPORT, POCKETBASE_ENABLED picks between PocketBase and the HTTP fallback as before
+	defaultTransport := "http"
+	if cfg.PocketBaseEnabled {
+		defaultTransport = "pocketbase"
+	}
+	cfg.Transport = strings.ToLower(getEnv("TRANSPORT", defaultTransport))
+	if cfg.Transport != "pocketbase" {
+		cfg.PocketBaseEnabled = false
+	}
+
 	// Validate required configuration
 	if err := validateConfig(cfg); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %v", err)
@@ -130,11 +406,15 @@ func Load() (*Config, error) {
 }
 
 func detectLocalIP() string {
-	// Try to get the local IP address by connecting to a remote address
+	// Try to get the local IP address by connecting to a remote address, over IPv6 on
+	// hosts without an IPv4 route
 	conn, err := net.Dial("udp", "8.8.8.8:80")
 	if err != nil {
-		log.Printf("Could not detect local IP address: %v", err)
-		return ""
+		var err6 error
+		if conn, err6 = net.Dial("udp", "[2001:4860:4860::8888]:80"); err6 != nil {
+			logging.Warnf("Could not detect local IP address: %v", err)
+			return ""
+		}
 	}
 	defer conn.Close()
 	
@@ -149,6 +429,11 @@ func validateConfig(cfg *Config) error {
 	if cfg.AgentID == "" {
 		errors = append(errors, "AGENT_ID is required")
 	}
+	switch cfg.Transport {
+	case "pocketbase", "http", "grpc", "influxdb":
+	default:
+		errors = append(errors, fmt.Sprintf("TRANSPORT must be pocketbase, http, grpc or influxdb (got %q)", cfg.Transport))
+	}
 
 	// Validate PocketBase configuration if enabled
 	if cfg.PocketBaseEnabled {
@@ -162,15 +447,162 @@ func validateConfig(cfg *Config) error {
 			errors = append(errors, "SERVER_TOKEN is required when POCKETBASE_ENABLED=true")
 		}
 		// IP_ADDRESS and HOSTNAME are now auto-detected, so no longer required
+		if cfg.PocketBaseCACert != "" {
+			if pem, err := os.ReadFile(cfg.PocketBaseCACert); err != nil {
+				errors = append(errors, fmt.Sprintf("POCKETBASE_CA_CERT could not be read: %v", err))
+			} else if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+				errors = append(errors, fmt.Sprintf("POCKETBASE_CA_CERT %s contains no PEM certificates", cfg.PocketBaseCACert))
+			}
+		}
+		if cfg.PocketBaseInsecureSkipVerify {
+			logging.Warnf("POCKETBASE_INSECURE_SKIP_VERIFY is set, PocketBase TLS certificates are not verified")
+		}
 	}
 
-	// Validate fallback HTTP configuration if PocketBase is disabled
-	if !cfg.PocketBaseEnabled {
+	// Validate fallback HTTP configuration
+	if cfg.Transport == "http" {
 		if cfg.ServerURL == "" {
-			errors = append(errors, "SERVER_URL is required when POCKETBASE_ENABLED=false")
+			errors = append(errors, "SERVER_URL is required when TRANSPORT=http (or POCKETBASE_ENABLED=false)")
 		}
 		if cfg.APIKey == "" {
-			log.Printf("Warning: API_KEY not set for HTTP fallback")
+			logging.Warnf("API_KEY not set for HTTP fallback")
+		}
+	}
+
+	// Validate gRPC configuration, plaintext must be asked for explicitly
+	if cfg.Transport == "grpc" {
+		if !grpcSupported {
+			errors = append(errors, "TRANSPORT=grpc requires a build with -tags grpc (make build TAGS=grpc)")
+		}
+		if cfg.GRPCServerAddr == "" {
+			errors = append(errors, "GRPC_SERVER_ADDR is required when TRANSPORT=grpc")
+		}
+		if !cfg.GRPCTLSEnabled && !cfg.GRPCInsecure {
+			errors = append(errors, "GRPC_TLS_ENABLED=false requires GRPC_INSECURE=true to connect without TLS")
+		}
+		if cfg.GRPCTLSEnabled && cfg.GRPCCACert != "" {
+			if pem, err := os.ReadFile(cfg.GRPCCACert); err != nil {
+				errors = append(errors, fmt.Sprintf("GRPC_CA_CERT could not be read: %v", err))
+			} else if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+				errors = append(errors, fmt.Sprintf("GRPC_CA_CERT %s contains no PEM certificates", cfg.GRPCCACert))
+			}
+		}
+	}
+
+	// Validate InfluxDB configuration
+	if cfg.Transport == "influxdb" {
+		if cfg.InfluxDBURL == "" {
+			errors = append(errors, "INFLUXDB_URL is required when TRANSPORT=influxdb")
+		} else if u, err := url.Parse(cfg.InfluxDBURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errors = append(errors, fmt.Sprintf("INFLUXDB_URL must be an http or https URL (got %q)", cfg.InfluxDBURL))
+		}
+		if cfg.InfluxDBBucket == "" {
+			errors = append(errors, "INFLUXDB_BUCKET is required when TRANSPORT=influxdb")
+		}
+		if cfg.InfluxDBToken == "" {
+			logging.Warnf("INFLUXDB_TOKEN not set, writes only succeed against an InfluxDB without authentication")
+		}
+	}
+
+	// Validate health server bind address, brackets around IPv6 addresses are optional
+	if cfg.HealthCheckBind != "" {
+		bind := strings.TrimSuffix(strings.TrimPrefix(cfg.HealthCheckBind, "["), "]")
+		if bind != "localhost" && net.ParseIP(bind) == nil {
+			errors = append(errors, fmt.Sprintf("HEALTH_CHECK_BIND must be an IP address or localhost (got %q)", cfg.HealthCheckBind))
+		}
+	}
+
+	// Validate health server TLS, loading the pair now so a bad one fails at startup
+	if (cfg.HealthCheckTLSCert == "") != (cfg.HealthCheckTLSKey == "") {
+		errors = append(errors, "HEALTH_CHECK_TLS_CERT and HEALTH_CHECK_TLS_KEY must be set together")
+	} else if cfg.HealthCheckTLSCert != "" {
+		if _, err := tls.LoadX509KeyPair(cfg.HealthCheckTLSCert, cfg.HealthCheckTLSKey); err != nil {
+			errors = append(errors, fmt.Sprintf("HEALTH_CHECK_TLS_CERT/HEALTH_CHECK_TLS_KEY could not be loaded: %v", err))
+		}
+	}
+
+	// Validate logging options
+	if cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+		errors = append(errors, fmt.Sprintf("LOG_FORMAT must be text or json (got %q)", cfg.LogFormat))
+	}
+	if _, ok := logging.ParseLevel(cfg.LogLevel); !ok {
+		errors = append(errors, fmt.Sprintf("LOG_LEVEL must be one of debug, info, warn, error (got %q)", cfg.LogLevel))
+	}
+
+	// Validate output units
+	for key, unit := range map[string]string{"MEMORY_UNIT": cfg.MemoryUnit, "DISK_UNIT": cfg.DiskUnit, "NETWORK_UNIT": cfg.NetworkUnit} {
+		if !isValidUnit(unit) {
+			errors = append(errors, fmt.Sprintf("%s must be one of bytes, KB, MB, GB, TB (got %q)", key, unit))
+		}
+	}
+
+	if cfg.MinCheckInterval > 0 && cfg.MaxCheckInterval > 0 && cfg.MaxCheckInterval < cfg.MinCheckInterval {
+		errors = append(errors, fmt.Sprintf("MAX_CHECK_INTERVAL (%v) must not be below MIN_CHECK_INTERVAL (%v)", cfg.MaxCheckInterval, cfg.MinCheckInterval))
+	}
+
+	if cfg.StatusPollInterval <= 0 {
+		errors = append(errors, fmt.Sprintf("STATUS_POLL_INTERVAL must be positive (got %v)", cfg.StatusPollInterval))
+	}
+
+	// Jitter beyond half the interval would let consecutive cycles nearly coincide
+	if cfg.CheckIntervalJitter < 0 || cfg.CheckIntervalJitter > 50 {
+		errors = append(errors, fmt.Sprintf("CHECK_INTERVAL_JITTER must be between 0 and 50 (got %d)", cfg.CheckIntervalJitter))
+	}
+
+	// Validate alerting
+	for key, percent := range map[string]int{"ALERT_CPU_PERCENT": cfg.AlertCPUPercent, "ALERT_MEMORY_PERCENT": cfg.AlertMemoryPercent, "ALERT_DISK_PERCENT": cfg.AlertDiskPercent} {
+		if percent < 0 || percent > 100 {
+			errors = append(errors, fmt.Sprintf("%s must be between 0 and 100 (got %d)", key, percent))
+		}
+	}
+	switch cfg.AlertWebhookType {
+	case "generic", "slack", "discord":
+	default:
+		errors = append(errors, fmt.Sprintf("ALERT_WEBHOOK_TYPE must be generic, slack or discord (got %q)", cfg.AlertWebhookType))
+	}
+	if cfg.AlertWebhookURL != "" {
+		if parsed, err := url.Parse(cfg.AlertWebhookURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errors = append(errors, fmt.Sprintf("ALERT_WEBHOOK_URL must be an http or https URL (got %q)", cfg.AlertWebhookURL))
+		}
+	} else if cfg.AlertCPUPercent > 0 || cfg.AlertMemoryPercent > 0 || cfg.AlertDiskPercent > 0 {
+		logging.Warnf("Alert thresholds are set but ALERT_WEBHOOK_URL is not, alerts will not be sent")
+	}
+
+	// Validate the primary disk path, e.g. a volume mounted into the agent's container
+	if _, err := os.Stat(cfg.DiskRootPath); err != nil {
+		errors = append(errors, fmt.Sprintf("DISK_ROOT_PATH %q is not accessible: %v", cfg.DiskRootPath, err))
+	}
+
+	names := make(map[string]bool)
+	for _, metric := range cfg.CustomMetrics {
+		switch {
+		case metric.Name == "" || metric.Command == "":
+			errors = append(errors, fmt.Sprintf("CUSTOM_METRICS entries must be name=command (got %q)", metric.Name+"="+metric.Command))
+		case strings.ContainsAny(metric.Name, " \t"):
+			errors = append(errors, fmt.Sprintf("CUSTOM_METRICS name %q must not contain whitespace", metric.Name))
+		case names[metric.Name]:
+			errors = append(errors, fmt.Sprintf("CUSTOM_METRICS name %q is used more than once", metric.Name))
+		}
+		names[metric.Name] = true
+	}
+	if len(cfg.CustomMetrics) > 0 && cfg.CustomMetricTimeout <= 0 {
+		errors = append(errors, "CUSTOM_METRIC_TIMEOUT must be positive")
+	}
+
+	if _, err := regexp.Compile(cfg.DockerIncludePattern); err != nil {
+		errors = append(errors, fmt.Sprintf("DOCKER_INCLUDE_PATTERN is not a valid regular expression: %v", err))
+	}
+	if _, err := regexp.Compile(cfg.DockerExcludePattern); err != nil {
+		errors = append(errors, fmt.Sprintf("DOCKER_EXCLUDE_PATTERN is not a valid regular expression: %v", err))
+	}
+	if strings.HasPrefix(cfg.DockerLabelFilter, "=") {
+		errors = append(errors, fmt.Sprintf("DOCKER_LABEL_FILTER must be label or label=value (got %q)", cfg.DockerLabelFilter))
+	}
+
+	// Interfaces such as VPN tunnels may only come up after the agent starts
+	if cfg.ReportInterface != "" {
+		if _, err := net.InterfaceByName(cfg.ReportInterface); err != nil {
+			logging.Warnf("REPORT_INTERFACE %q not found, the reported IP is unknown until it appears", cfg.ReportInterface)
 		}
 	}
 
@@ -179,10 +611,10 @@ func validateConfig(cfg *Config) error {
 		for _, err := range errors {
 			errorMsg += fmt.Sprintf("  - %s\n", err)
 		}
-		return fmt.Errorf(errorMsg)
+		return fmt.Errorf("%s", errorMsg)
 	}
 
-	log.Printf("Configuration validation passed")
+	logging.Infof("Configuration validation passed")
 	return nil
 }
 
@@ -208,6 +640,55 @@ func getEnvSafe(key string) string {
 	return value
 }
 
+// getUnitEnv reads an output unit, accepting any case ("mb" becomes "MB")
+func getUnitEnv(key, defaultValue string) string {
+	value := strings.TrimSpace(getEnv(key, defaultValue))
+	if strings.EqualFold(value, "bytes") {
+		return "bytes"
+	}
+	return strings.ToUpper(value)
+}
+
+// isValidUnit reports whether unit is a supported output unit
+func isValidUnit(unit string) bool {
+	switch unit {
+	case "bytes", "KB", "MB", "GB", "TB":
+		return true
+	}
+	return false
+}
+
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// CustomMetricCommand is a CUSTOM_METRICS entry. Command is split on whitespace and run
+// without a shell, its output is reported as the custom metric Name.
+type CustomMetricCommand struct {
+	Name    string
+	Command string
+}
+
+// getCustomMetricsEnv parses comma-separated name=command pairs. Entries without a name or
+// command are kept so validateConfig can reject them.
+func getCustomMetricsEnv(key string) []CustomMetricCommand {
+	var metrics []CustomMetricCommand
+	for _, entry := range getListEnv(key) {
+		name, command, _ := strings.Cut(entry, "=")
+		metrics = append(metrics, CustomMetricCommand{
+			Name:    strings.TrimSpace(name),
+			Command: strings.Join(strings.Fields(command), " "),
+		})
+	}
+	return metrics
+}
+
 func getIntEnv(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigGRPCTransportNeedsTag(t *testing.T) {
+	cfg := &Config{
+		AgentID:        "agent-1",
+		Transport:      "grpc",
+		GRPCServerAddr: "localhost:50051",
+		GRPCInsecure:   true,
+	}
+
+	err := validateConfig(cfg)
+	rejected := err != nil && strings.Contains(err.Error(), "requires a build with -tags grpc")
+	if rejected == grpcSupported {
+		t.Errorf("validateConfig(TRANSPORT=grpc) = %v with grpcSupported=%v", err, grpcSupported)
+	}
+}
+
+func TestReloadKeepsEnvironmentOverFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.env")
+	writeEnv := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Loading sets the file's values as environment variables, restore them afterwards
+	for _, key := range []string{"AGENT_ID", "POCKETBASE_ENABLED", "SERVER_URL", "LOG_LEVEL"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv("SERVER_NAME", "from-env")
+	processEnv = nil
+	SetEnvFile(path)
+	t.Cleanup(func() {
+		SetEnvFile("")
+		processEnv = nil
+	})
+
+	writeEnv("AGENT_ID=agent-1\nPOCKETBASE_ENABLED=false\nSERVER_URL=http://localhost:8090\nSERVER_NAME=from-file\nLOG_LEVEL=info\n")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.ServerName != "from-env" {
+		t.Errorf("ServerName after Load = %q, want the environment's value", cfg.ServerName)
+	}
+
+	writeEnv("AGENT_ID=agent-1\nPOCKETBASE_ENABLED=false\nSERVER_URL=http://localhost:8090\nSERVER_NAME=edited-file\nLOG_LEVEL=debug\n")
+	cfg, err = Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if cfg.ServerName != "from-env" {
+		t.Errorf("ServerName after Reload = %q, want the environment's value", cfg.ServerName)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("LogLevel after Reload = %q, want the edited file's value", cfg.LogLevel)
+	}
+}
+
+func TestGetCustomMetricsEnv(t *testing.T) {
+	t.Setenv("CUSTOM_METRICS", " nginx = systemctl  is-active nginx ,queue=/usr/local/bin/queue-depth,=orphan,empty=")
+
+	got := getCustomMetricsEnv("CUSTOM_METRICS")
+	want := []CustomMetricCommand{
+		{Name: "nginx", Command: "systemctl is-active nginx"},
+		{Name: "queue", Command: "/usr/local/bin/queue-depth"},
+		{Name: "", Command: "orphan"},
+		{Name: "empty", Command: ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("getCustomMetricsEnv = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	t.Setenv("CUSTOM_METRICS", "")
+	if got := getCustomMetricsEnv("CUSTOM_METRICS"); len(got) != 0 {
+		t.Errorf("getCustomMetricsEnv with nothing set = %+v, want none", got)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build grpc
+
+package config
+
+// grpcSupported reports whether this binary was built with the gRPC transport
+const grpcSupported = true
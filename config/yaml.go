@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlKeys maps each section and key of a YAML config file to the environment variable it sets
+var yamlKeys = map[string]map[string]string{
+	"agent": {
+		"id":                 "AGENT_ID",
+		"transport":          "TRANSPORT",
+		"max_retries":        "MAX_RETRIES",
+		"retry_backoff_base": "RETRY_BACKOFF_BASE",
+		"request_timeout":    "REQUEST_TIMEOUT",
+		"state_dir":          "STATE_DIR",
+		"dry_run":            "DRY_RUN",
+	},
+	"logging": {
+		"format":       "LOG_FORMAT",
+		"level":        "LOG_LEVEL",
+		"max_size_mb":  "LOG_MAX_SIZE_MB",
+		"max_backups":  "LOG_MAX_BACKUPS",
+		"max_age_days": "LOG_MAX_AGE_DAYS",
+	},
+	"server": {
+		"name":       "SERVER_NAME",
+		"hostname":   "HOSTNAME",
+		"ip_address": "IP_ADDRESS",
+		"os_type":    "OS_TYPE",
+		"token":      "SERVER_TOKEN",
+		"url":        "SERVER_URL",
+		"api_key":    "API_KEY",
+	},
+	"pocketbase": {
+		"enabled":              "POCKETBASE_ENABLED",
+		"url":                  "POCKETBASE_URL",
+		"timeout":              "POCKETBASE_TIMEOUT",
+		"max_idle_conns":       "POCKETBASE_MAX_IDLE_CONNS",
+		"ca_cert":              "POCKETBASE_CA_CERT",
+		"insecure_skip_verify": "POCKETBASE_INSECURE_SKIP_VERIFY",
+		"registration_timeout": "REGISTRATION_TIMEOUT",
+	},
+	"grpc": {
+		"server_addr": "GRPC_SERVER_ADDR",
+		"tls_enabled": "GRPC_TLS_ENABLED",
+		"ca_cert":     "GRPC_CA_CERT",
+		"auth_token":  "GRPC_AUTH_TOKEN",
+		"insecure":    "GRPC_INSECURE",
+		"stream":      "GRPC_STREAM_METRICS",
+	},
+	"influxdb": {
+		"url":    "INFLUXDB_URL",
+		"bucket": "INFLUXDB_BUCKET",
+		"org":    "INFLUXDB_ORG",
+		"token":  "INFLUXDB_TOKEN",
+	},
+	"intervals": {
+		"check":                     "CHECK_INTERVAL",
+		"min_check":                 "MIN_CHECK_INTERVAL",
+		"max_check":                 "MAX_CHECK_INTERVAL",
+		"status_poll":               "STATUS_POLL_INTERVAL",
+		"paused_poll":               "PAUSED_POLL_INTERVAL",
+		"check_jitter_percent":      "CHECK_INTERVAL_JITTER",
+		"report":                    "REPORT_INTERVAL",
+		"command_check":             "COMMAND_CHECK_INTERVAL",
+		"collection_budget_percent": "COLLECTION_BUDGET_PERCENT",
+		"collection_timeout":        "COLLECTION_TIMEOUT",
+		"warmup_cycles":             "WARMUP_CYCLES",
+	},
+	"health": {
+		"port":               "HEALTH_CHECK_PORT",
+		"bind":               "HEALTH_CHECK_BIND",
+		"control_auth_token": "CONTROL_AUTH_TOKEN",
+		"tls_cert":           "HEALTH_CHECK_TLS_CERT",
+		"tls_key":            "HEALTH_CHECK_TLS_KEY",
+	},
+	"remote_control": {
+		"enabled":        "REMOTE_CONTROL_ENABLED",
+		"exec_allowlist": "REMOTE_EXEC_ALLOWLIST",
+	},
+	"units": {
+		"memory":  "MEMORY_UNIT",
+		"disk":    "DISK_UNIT",
+		"network": "NETWORK_UNIT",
+	},
+	"disk": {
+		"root_path": "DISK_ROOT_PATH",
+	},
+	"network": {
+		"report_interface": "REPORT_INTERFACE",
+	},
+	"docker": {
+		"runtime":           "CONTAINER_RUNTIME",
+		"stats_concurrency": "DOCKER_STATS_CONCURRENCY",
+		"include_pattern":   "DOCKER_INCLUDE_PATTERN",
+		"exclude_pattern":   "DOCKER_EXCLUDE_PATTERN",
+		"label_filter":      "DOCKER_LABEL_FILTER",
+	},
+	"collectors": {
+		"entropy":             "ENTROPY_MONITORING_ENABLED",
+		"links":               "LINK_MONITORING_ENABLED",
+		"hugepages":           "HUGEPAGES_MONITORING_ENABLED",
+		"fragmentation":       "FRAGMENTATION_MONITORING_ENABLED",
+		"systemd_timers":      "SYSTEMD_TIMER_MONITORING_ENABLED",
+		"storage_arrays":      "STORAGE_ARRAY_MONITORING_ENABLED",
+		"core_dumps":          "CORE_DUMP_MONITORING_ENABLED",
+		"kernel_taint":        "KERNEL_TAINT_MONITORING_ENABLED",
+		"egress_quota":        "EGRESS_QUOTA_ENABLED",
+		"monitored_processes": "MONITORED_PROCESSES",
+	},
+	"thresholds": {
+		"process_threads":            "PROCESS_THREAD_THRESHOLD",
+		"min_link_speed_mbps":        "MIN_LINK_SPEED_MBPS",
+		"conntrack_warn_percent":     "CONNTRACK_WARN_PERCENT",
+		"file_handle_warn_percent":   "FILE_HANDLE_WARN_PERCENT",
+		"syn_recv_warn":              "SYN_RECV_WARN_THRESHOLD",
+		"fragmentation_high_order":   "FRAGMENTATION_HIGH_ORDER",
+		"fragmentation_warn_percent": "FRAGMENTATION_WARN_PERCENT",
+		"systemd_timer_grace":        "SYSTEMD_TIMER_GRACE",
+		"scrub_max_age":              "SCRUB_MAX_AGE",
+		"egress_quota_gb":            "EGRESS_QUOTA_GB",
+		"egress_reset_day":           "EGRESS_RESET_DAY",
+	},
+	"statsd": {
+		"enabled": "STATSD_ENABLED",
+		"port":    "STATSD_PORT",
+	},
+	"custom_metrics": {
+		"commands": "CUSTOM_METRICS",
+		"timeout":  "CUSTOM_METRIC_TIMEOUT",
+	},
+	"alerts": {
+		"webhook_url":    "ALERT_WEBHOOK_URL",
+		"webhook_type":   "ALERT_WEBHOOK_TYPE",
+		"cpu_percent":    "ALERT_CPU_PERCENT",
+		"memory_percent": "ALERT_MEMORY_PERCENT",
+		"disk_percent":   "ALERT_DISK_PERCENT",
+		"duration":       "ALERT_DURATION",
+	},
+}
+
+// isYAMLFile reports whether path should be parsed as YAML rather than KEY=value
+func isYAMLFile(path string) bool {
+	return strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
+}
+
+// loadYAML reads a sectioned YAML config file into environment variables so it flows
+// through the same parsing and validation as .env files. Like godotenv.Load, variables
+// already in the environment win unless override is set.
+func loadYAML(path string, override bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var sections map[string]map[string]interface{}
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return fmt.Errorf("invalid YAML in %s: %v", path, err)
+	}
+
+	var unknown []string
+	for section, values := range sections {
+		for key, value := range values {
+			envKey, ok := yamlKeys[section][key]
+			if !ok {
+				unknown = append(unknown, section+"."+key)
+				continue
+			}
+			if _, exists := os.LookupEnv(envKey); exists && !override {
+				continue
+			}
+			os.Setenv(envKey, yamlValue(value))
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown keys in %s: %s", path, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// yamlValue formats a YAML scalar, list or map the way the env parsers expect. Maps become
+// comma-separated key=value pairs in key order.
+func yamlValue(value interface{}) string {
+	if mapping, ok := value.(map[string]interface{}); ok {
+		keys := make([]string, 0, len(mapping))
+		for key := range mapping {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = key + "=" + fmt.Sprint(mapping[key])
+		}
+		return strings.Join(items, ",")
+	}
+	if list, ok := value.([]interface{}); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -1,58 +1,153 @@
-
 package grpc
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/connectivity"
+	"monitoring-agent/logging"
 	pb "monitoring-agent/proto"
 )
 
+const (
+	reconnectBackoffBase = time.Second
+	reconnectBackoffMax  = time.Minute
+)
+
 type GRPCClient struct {
+	address  string
+	dialOpts []grpc.DialOption
+
+	mu     sync.RWMutex // Guards conn and client, which are replaced on reconnect
 	conn   *grpc.ClientConn
 	client pb.MonitoringServiceClient
-	ctx    context.Context
+
+	connected atomic.Bool // Whether the connection was Ready when last observed
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{} // Closed when the connection watcher exits
 }
 
-func NewGRPCClient(serverAddress string) (*GRPCClient, error) {
-	conn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// NewGRPCClient connects to serverAddress over TLS (WithTLS) or, only when explicitly
+// allowed, plaintext (WithInsecure). The connection is established in the background
+// and watched for failures; IsConnected reports whether it's currently usable.
+func NewGRPCClient(serverAddress string, opts ...ClientOption) (*GRPCClient, error) {
+	dialOpts, err := dialOptions(opts)
 	if err != nil {
 		return nil, err
 	}
 
-	client := pb.NewMonitoringServiceClient(conn)
-	
-	return &GRPCClient{
-		conn:   conn,
-		client: client,
-		ctx:    context.Background(),
-	}, nil
+	conn, err := grpc.NewClient(serverAddress, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	conn.Connect()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &GRPCClient{
+		address:  serverAddress,
+		dialOpts: dialOpts,
+		conn:     conn,
+		client:   pb.NewMonitoringServiceClient(conn),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go c.watchConnection()
+
+	return c, nil
 }
 
-func (c *GRPCClient) SendMetrics(agentID string, cpuUsage, memoryUsage, diskUsage float64, goRoutines int, status string) (*pb.MetricsResponse, error) {
+// IsConnected reports whether the connection to the server is ready for RPCs
+func (c *GRPCClient) IsConnected() bool {
+	return c.connected.Load()
+}
+
+// service returns the stub for the current connection
+func (c *GRPCClient) service() pb.MonitoringServiceClient {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.client
+}
+
+// watchConnection follows the connection state until Close. An idle connection is woken
+// up; one stuck in TransientFailure or Shutdown is replaced with a fresh connection,
+// waiting with exponential backoff between attempts.
+func (c *GRPCClient) watchConnection() {
+	defer close(c.done)
+
+	backoff := reconnectBackoffBase
+	for {
+		c.mu.RLock()
+		conn := c.conn
+		c.mu.RUnlock()
+
+		state := conn.GetState()
+		wasConnected := c.connected.Swap(state == connectivity.Ready)
+
+		switch state {
+		case connectivity.Ready:
+			if !wasConnected {
+				logging.Infof("gRPC connection to %s established", c.address)
+			}
+			backoff = reconnectBackoffBase
+		case connectivity.Idle:
+			conn.Connect()
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			logging.Warnf("gRPC connection to %s is %s, reconnecting in %v", c.address, state, backoff)
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > reconnectBackoffMax {
+				backoff = reconnectBackoffMax
+			}
+
+			// The channel retries on its own and may have recovered while we waited
+			if conn.GetState() != connectivity.Ready {
+				c.reconnect(conn)
+			}
+			continue
+		}
+
+		if !conn.WaitForStateChange(c.ctx, state) {
+			return // Closed
+		}
+	}
+}
+
+// reconnect replaces old with a new connection to the same address
+func (c *GRPCClient) reconnect(old *grpc.ClientConn) {
+	conn, err := grpc.NewClient(c.address, c.dialOpts...)
+	if err != nil {
+		logging.Errorf("Failed to recreate gRPC connection to %s: %v", c.address, err)
+		return
+	}
+	conn.Connect()
+
+	c.mu.Lock()
+	c.conn = conn
+	c.client = pb.NewMonitoringServiceClient(conn)
+	c.mu.Unlock()
+
+	old.Close()
+}
+
+// SendMetrics pushes one metrics sample, stamping the current time when Timestamp is unset
+func (c *GRPCClient) SendMetrics(req *pb.MetricsRequest) (*pb.MetricsResponse, error) {
 	ctx, cancel := context.WithTimeout(c.ctx, 10*time.Second)
 	defer cancel()
 
-	req := &pb.MetricsRequest{
-		AgentId:     agentID,
-		Timestamp:   time.Now().Unix(),
-		CpuUsage:    cpuUsage,
-		MemoryUsage: memoryUsage,
-		DiskUsage:   diskUsage,
-		NetworkStats: &pb.NetworkStats{
-			BytesSent:       0, // Would implement actual network stats
-			BytesReceived:   0,
-			PacketsSent:     0,
-			PacketsReceived: 0,
-		},
-		Uptime:      int64(time.Hour.Seconds()), // Placeholder
-		GoRoutines:  int32(goRoutines),
-		Status:      status,
+	if req.Timestamp == 0 {
+		req.Timestamp = time.Now().Unix()
 	}
 
-	return c.client.SendMetrics(ctx, req)
+	return c.service().SendMetrics(ctx, req)
 }
 
 func (c *GRPCClient) SendHealthCheck(agentID, version string) (*pb.HealthResponse, error) {
@@ -65,7 +160,7 @@ func (c *GRPCClient) SendHealthCheck(agentID, version string) (*pb.HealthRespons
 		Version:   version,
 	}
 
-	return c.client.SendHealthCheck(ctx, req)
+	return c.service().SendHealthCheck(ctx, req)
 }
 
 func (c *GRPCClient) GetRemoteCommand(agentID string) (*pb.CommandResponse, error) {
@@ -76,7 +171,7 @@ func (c *GRPCClient) GetRemoteCommand(agentID string) (*pb.CommandResponse, erro
 		AgentId: agentID,
 	}
 
-	return c.client.GetRemoteCommand(ctx, req)
+	return c.service().GetRemoteCommand(ctx, req)
 }
 
 func (c *GRPCClient) UpdateAgentStatus(agentID, status, message string) (*pb.StatusResponse, error) {
@@ -89,12 +184,15 @@ func (c *GRPCClient) UpdateAgentStatus(agentID, status, message string) (*pb.Sta
 		Message: message,
 	}
 
-	return c.client.UpdateAgentStatus(ctx, req)
+	return c.service().UpdateAgentStatus(ctx, req)
 }
 
+// Close stops the connection watcher and closes the connection
 func (c *GRPCClient) Close() error {
-	if c.conn != nil {
-		return c.conn.Close()
-	}
-	return nil
+	c.cancel()
+	<-c.done
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.Close()
 }
\ No newline at end of file
@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	pb "monitoring-agent/proto"
+)
+
+func TestClientReconnectsAfterServerRestart(t *testing.T) {
+	server, address := startFakeServer(t, "127.0.0.1:0", &fakeServer{})
+
+	client, err := NewGRPCClient(address, WithInsecure())
+	if err != nil {
+		t.Fatalf("NewGRPCClient: %v", err)
+	}
+	defer client.Close()
+
+	waitFor(t, 5*time.Second, "the initial connection", client.IsConnected)
+
+	server.Stop()
+	waitFor(t, 5*time.Second, "the watcher to notice the server is gone", func() bool { return !client.IsConnected() })
+
+	// Let at least one backoff round replace the connection before the server returns
+	time.Sleep(reconnectBackoffBase + 200*time.Millisecond)
+
+	srv := &fakeServer{}
+	startFakeServer(t, address, srv)
+	waitFor(t, 10*time.Second, "the connection to come back", client.IsConnected)
+
+	if _, err := client.SendMetrics(&pb.MetricsRequest{AgentId: "agent-1"}); err != nil {
+		t.Fatalf("SendMetrics after reconnecting: %v", err)
+	}
+	if got := srv.count(); got != 1 {
+		t.Errorf("restarted server received %d messages, want 1", got)
+	}
+}
+
+func TestCloseStopsConnectionWatcher(t *testing.T) {
+	_, address := startFakeServer(t, "127.0.0.1:0", &fakeServer{})
+
+	client, err := NewGRPCClient(address, WithInsecure())
+	if err != nil {
+		t.Fatalf("NewGRPCClient: %v", err)
+	}
+	waitFor(t, 5*time.Second, "the initial connection", client.IsConnected)
+
+	closed := make(chan error, 1)
+	go func() { closed <- client.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return, the connection watcher is still running")
+	}
+}
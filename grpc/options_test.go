@@ -0,0 +1,166 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	pb "monitoring-agent/proto"
+)
+
+// selfSignedCert creates a certificate for 127.0.0.1 and writes it to a PEM file usable as CA
+func selfSignedCert(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "monitoring-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+// authRecorder is a unary interceptor remembering the authorization metadata of the last RPC
+type authRecorder struct {
+	mu    sync.Mutex
+	token []string
+}
+
+func (r *authRecorder) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	r.mu.Lock()
+	r.token = md.Get("authorization")
+	r.mu.Unlock()
+	return handler(ctx, req)
+}
+
+func (r *authRecorder) last() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.token
+}
+
+func TestDialOptionsRequireTLSOrExplicitInsecure(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ClientOption
+		wantErr bool
+	}{
+		{"no transport security", nil, true},
+		{"token without transport security", []ClientOption{WithAuthToken("secret")}, true},
+		{"explicit insecure", []ClientOption{WithInsecure()}, false},
+		{"system CA pool", []ClientOption{WithTLS("")}, false},
+		{"missing CA file", []ClientOption{WithTLS(filepath.Join(t.TempDir(), "missing.pem"))}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := dialOptions(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("dialOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfigRejectsFileWithoutCertificates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTLSConfig(path); err == nil {
+		t.Error("newTLSConfig accepted a file without PEM certificates")
+	}
+}
+
+func TestClientSendsTokenOverTLS(t *testing.T) {
+	cert, caFile := selfSignedCert(t)
+	auth := &authRecorder{}
+	_, address := startFakeServer(t, "127.0.0.1:0", &fakeServer{},
+		grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}})),
+		grpc.UnaryInterceptor(auth.intercept),
+	)
+
+	client, err := NewGRPCClient(address, WithTLS(caFile), WithAuthToken("secret"))
+	if err != nil {
+		t.Fatalf("NewGRPCClient: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.SendMetrics(&pb.MetricsRequest{AgentId: "agent-1"}); err != nil {
+		t.Fatalf("SendMetrics over TLS: %v", err)
+	}
+	if got := auth.last(); len(got) != 1 || got[0] != "Bearer secret" {
+		t.Errorf("authorization metadata = %q, want [\"Bearer secret\"]", got)
+	}
+}
+
+func TestClientRejectsUntrustedServer(t *testing.T) {
+	cert, _ := selfSignedCert(t)
+	_, otherCA := selfSignedCert(t)
+	_, address := startFakeServer(t, "127.0.0.1:0", &fakeServer{},
+		grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}})),
+	)
+
+	client, err := NewGRPCClient(address, WithTLS(otherCA))
+	if err != nil {
+		t.Fatalf("NewGRPCClient: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.SendMetrics(&pb.MetricsRequest{AgentId: "agent-1"}); err == nil {
+		t.Error("SendMetrics succeeded against a server signed by an untrusted CA")
+	}
+}
+
+func TestInsecureClientSendsToken(t *testing.T) {
+	auth := &authRecorder{}
+	_, address := startFakeServer(t, "127.0.0.1:0", &fakeServer{}, grpc.UnaryInterceptor(auth.intercept))
+
+	client, err := NewGRPCClient(address, WithInsecure(), WithAuthToken("secret"))
+	if err != nil {
+		t.Fatalf("NewGRPCClient: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.SendMetrics(&pb.MetricsRequest{AgentId: "agent-1"}); err != nil {
+		t.Fatalf("SendMetrics: %v", err)
+	}
+	if got := auth.last(); len(got) != 1 || got[0] != "Bearer secret" {
+		t.Errorf("authorization metadata = %q, want [\"Bearer secret\"]", got)
+	}
+}
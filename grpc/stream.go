@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"monitoring-agent/logging"
+	pb "monitoring-agent/proto"
+)
+
+// MetricsStream pushes MetricsRequest messages over a long-lived StreamMetrics RPC.
+// When the stream drops it is reopened on a later Send, waiting with exponential
+// backoff between attempts so a down server isn't hammered every collection cycle.
+type MetricsStream struct {
+	client *GRPCClient
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	stream      pb.MonitoringService_StreamMetricsClient
+	backoff     time.Duration // Delay before the next reconnect, doubled after each failure
+	nextAttempt time.Time     // Sends before this fail fast instead of reconnecting
+}
+
+// StreamMetrics returns a stream that opens lazily on the first Send
+func (c *GRPCClient) StreamMetrics() *MetricsStream {
+	ctx, cancel := context.WithCancel(c.ctx)
+	return &MetricsStream{
+		client:  c,
+		ctx:     ctx,
+		cancel:  cancel,
+		backoff: reconnectBackoffBase,
+	}
+}
+
+// Send pushes one metrics sample, stamping the current time when Timestamp is unset.
+// A failed send drops the stream; the sample is lost and the caller gets the error.
+func (s *MetricsStream) Send(req *pb.MetricsRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return errors.New("metrics stream is closed")
+	}
+
+	if s.stream == nil {
+		if wait := s.nextAttempt.Sub(time.Now()); wait > 0 {
+			return fmt.Errorf("metrics stream disconnected, reconnecting in %v", wait.Round(time.Second))
+		}
+
+		stream, err := s.client.service().StreamMetrics(s.ctx)
+		if err != nil {
+			s.scheduleReconnect()
+			return fmt.Errorf("failed to open metrics stream: %v", err)
+		}
+		s.stream = stream
+	}
+
+	if req.Timestamp == 0 {
+		req.Timestamp = time.Now().Unix()
+	}
+
+	if err := s.stream.Send(req); err != nil {
+		// Send reports io.EOF when the server ended the stream; the actual status comes from CloseAndRecv
+		if errors.Is(err, io.EOF) {
+			if _, recvErr := s.stream.CloseAndRecv(); recvErr != nil {
+				err = recvErr
+			}
+		}
+		s.stream = nil
+		s.scheduleReconnect()
+		return fmt.Errorf("metrics stream dropped: %v", err)
+	}
+
+	// A healthy send resets the backoff for the next disconnect
+	s.backoff = reconnectBackoffBase
+	return nil
+}
+
+// scheduleReconnect delays the next open attempt and doubles the backoff, capped at reconnectBackoffMax
+func (s *MetricsStream) scheduleReconnect() {
+	logging.Warnf("gRPC metrics stream unavailable, reconnecting in %v", s.backoff)
+	s.nextAttempt = time.Now().Add(s.backoff)
+	s.backoff *= 2
+	if s.backoff > reconnectBackoffMax {
+		s.backoff = reconnectBackoffMax
+	}
+}
+
+// Close half-closes the stream and returns the server's summary response, if a stream was open
+func (s *MetricsStream) Close() (*pb.MetricsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defer s.cancel()
+	if s.stream == nil {
+		return nil, nil
+	}
+
+	stream := s.stream
+	s.stream = nil
+	return stream.CloseAndRecv()
+}
@@ -0,0 +1,177 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "monitoring-agent/proto"
+)
+
+// fakeServer is a MonitoringService that records what it receives
+type fakeServer struct {
+	pb.UnimplementedMonitoringServiceServer
+
+	mu       sync.Mutex
+	received []*pb.MetricsRequest
+	dropAt   int // StreamMetrics fails once this many messages arrived (0 never drops)
+}
+
+func (s *fakeServer) SendMetrics(ctx context.Context, req *pb.MetricsRequest) (*pb.MetricsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.received = append(s.received, req)
+	return &pb.MetricsResponse{Success: true}, nil
+}
+
+func (s *fakeServer) StreamMetrics(stream pb.MonitoringService_StreamMetricsServer) error {
+	var count int64
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&pb.MetricsResponse{Success: true, Received: count})
+		}
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.received = append(s.received, req)
+		drop := s.dropAt > 0 && len(s.received) >= s.dropAt
+		s.mu.Unlock()
+
+		count++
+		if drop {
+			return status.Error(codes.Unavailable, "server going away")
+		}
+	}
+}
+
+func (s *fakeServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.received)
+}
+
+// startFakeServer serves srv on address ("127.0.0.1:0" picks a free port) until the test ends
+func startFakeServer(t *testing.T, address string, srv pb.MonitoringServiceServer, opts ...grpc.ServerOption) (*grpc.Server, string) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Fatalf("listen on %s: %v", address, err)
+	}
+	server := grpc.NewServer(opts...)
+	pb.RegisterMonitoringServiceServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return server, lis.Addr().String()
+}
+
+// waitFor polls cond until it holds or the timeout expires
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestMetricsStreamSendsOverOneStream(t *testing.T) {
+	srv := &fakeServer{}
+	_, address := startFakeServer(t, "127.0.0.1:0", srv)
+
+	client, err := NewGRPCClient(address, WithInsecure())
+	if err != nil {
+		t.Fatalf("NewGRPCClient: %v", err)
+	}
+	defer client.Close()
+
+	stream := client.StreamMetrics()
+	for i := 0; i < 3; i++ {
+		if err := stream.Send(&pb.MetricsRequest{AgentId: "agent-1", CpuUsage: float64(i)}); err != nil {
+			t.Fatalf("Send %d: %v", i, err)
+		}
+	}
+
+	resp, err := stream.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if resp.GetReceived() != 3 {
+		t.Errorf("server acknowledged %d messages, want 3", resp.GetReceived())
+	}
+	if got := srv.count(); got != 3 {
+		t.Errorf("server received %d messages, want 3", got)
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.received[0].GetTimestamp() == 0 {
+		t.Error("Send did not stamp the timestamp")
+	}
+}
+
+func TestMetricsStreamReconnectsWithBackoff(t *testing.T) {
+	srv := &fakeServer{dropAt: 2}
+	_, address := startFakeServer(t, "127.0.0.1:0", srv)
+
+	client, err := NewGRPCClient(address, WithInsecure())
+	if err != nil {
+		t.Fatalf("NewGRPCClient: %v", err)
+	}
+	defer client.Close()
+
+	stream := client.StreamMetrics()
+	defer stream.Close()
+
+	// The server ends the stream after the second message, which the client only notices
+	// on a later send
+	var dropErr error
+	for i := 0; i < 10 && dropErr == nil; i++ {
+		dropErr = stream.Send(&pb.MetricsRequest{AgentId: "agent-1"})
+		time.Sleep(20 * time.Millisecond)
+	}
+	if dropErr == nil {
+		t.Fatal("Send never reported the dropped stream")
+	}
+
+	// Within the backoff window sends fail fast instead of reopening the stream
+	if err := stream.Send(&pb.MetricsRequest{AgentId: "agent-1"}); err == nil {
+		t.Fatal("Send reopened the stream before the backoff elapsed")
+	}
+	stream.mu.Lock()
+	backoff := stream.backoff
+	stream.mu.Unlock()
+	if backoff != 2*reconnectBackoffBase {
+		t.Errorf("backoff after one drop is %v, want %v", backoff, 2*reconnectBackoffBase)
+	}
+
+	srv.mu.Lock()
+	srv.dropAt = 0
+	before := len(srv.received)
+	srv.mu.Unlock()
+
+	time.Sleep(reconnectBackoffBase)
+	if err := stream.Send(&pb.MetricsRequest{AgentId: "agent-1"}); err != nil {
+		t.Fatalf("Send after the backoff: %v", err)
+	}
+	waitFor(t, 5*time.Second, "the message on the reopened stream", func() bool { return srv.count() > before })
+
+	stream.mu.Lock()
+	backoff = stream.backoff
+	stream.mu.Unlock()
+	if backoff != reconnectBackoffBase {
+		t.Errorf("backoff after a healthy send is %v, want %v", backoff, reconnectBackoffBase)
+	}
+}
@@ -0,0 +1,185 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the severity of a log line
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel parses debug, info, warn or error (case-insensitive)
+func ParseLevel(s string) (Level, bool) {
+	for level, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return level, true
+		}
+	}
+	return LevelInfo, false
+}
+
+// minLevel is read on every write so SetLevel can change it at runtime
+var minLevel atomic.Int32
+
+// configured is set once Setup installed the writer that understands levelMarker
+var configured atomic.Bool
+
+// levelMarker tags lines from the leveled helpers, followed by the level digit
+const levelMarker = "\x1f"
+
+func init() {
+	minLevel.Store(int32(LevelInfo))
+}
+
+// SetLevel changes the minimum level written
+func SetLevel(level Level) {
+	minLevel.Store(int32(level))
+}
+
+// Setup routes the standard logger through a writer that drops lines below level and
+// formats them as "text" ("2006/01/02 15:04:05 WARN message") or "json". Existing log.Printf
+// calls keep working; their level is inferred from prefixes such as "Warning:" and "Failed".
+func Setup(out io.Writer, format string, level Level, agentID string) {
+	SetLevel(level)
+	log.SetFlags(log.Lshortfile)
+	log.SetOutput(&writer{out: out, json: format == "json", agentID: agentID})
+	configured.Store(true)
+}
+
+// Debugf logs routine per-cycle detail, hidden unless LOG_LEVEL=debug
+func Debugf(format string, args ...interface{}) {
+	logf(LevelDebug, format, args...)
+}
+
+// Infof logs normal operational messages
+func Infof(format string, args ...interface{}) {
+	logf(LevelInfo, format, args...)
+}
+
+// Warnf logs conditions that need attention but don't stop collection
+func Warnf(format string, args ...interface{}) {
+	logf(LevelWarn, format, args...)
+}
+
+// Errorf logs failed operations
+func Errorf(format string, args ...interface{}) {
+	logf(LevelError, format, args...)
+}
+
+// logf writes through the standard logger, tagging the line with its level
+func logf(level Level, format string, args ...interface{}) {
+	if level < Level(minLevel.Load()) {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	if configured.Load() {
+		message = levelMarker + strconv.Itoa(int(level)) + message
+	}
+	log.Output(3, message)
+}
+
+// writer formats lines written by the standard logger
+type writer struct {
+	mu      sync.Mutex
+	out     io.Writer
+	json    bool
+	agentID string
+}
+
+// jsonLine is one structured log entry
+type jsonLine struct {
+	Level     string `json:"level"`
+	Timestamp string `json:"timestamp"`
+	Component string `json:"component"`
+	AgentID   string `json:"agent_id,omitempty"`
+	Message   string `json:"message"`
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	line := strings.TrimSuffix(string(p), "\n")
+
+	// Lshortfile prefixes "file.go:123: ", the file names the component
+	component := ""
+	if idx := strings.Index(line, ": "); idx > 0 && strings.Contains(line[:idx], ".go:") {
+		file := line[:strings.Index(line, ".go:")]
+		component = strings.TrimSuffix(filepath.Base(file), "_collector")
+		line = line[idx+2:]
+	}
+
+	level := inferLevel(line)
+	if rest, ok := strings.CutPrefix(line, levelMarker); ok && rest != "" {
+		level = Level(rest[0] - '0')
+		line = rest[1:]
+	}
+	if level < Level(minLevel.Load()) {
+		return len(p), nil
+	}
+
+	var buf bytes.Buffer
+	now := time.Now()
+	if w.json {
+		entry := jsonLine{
+			Level:     level.String(),
+			Timestamp: now.Format(time.RFC3339Nano),
+			Component: component,
+			AgentID:   w.agentID,
+			Message:   line,
+		}
+		if err := json.NewEncoder(&buf).Encode(entry); err != nil {
+			return 0, err
+		}
+	} else {
+		buf.WriteString(now.Format("2006/01/02 15:04:05 "))
+		buf.WriteString(strings.ToUpper(level.String()))
+		buf.WriteByte(' ')
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// inferLevel guesses the level of an unleveled message from the wording this codebase uses
+func inferLevel(message string) Level {
+	switch {
+	case strings.HasPrefix(message, "Warning"):
+		return LevelWarn
+	case strings.HasPrefix(message, "FATAL"), strings.HasPrefix(message, "Failed"),
+		strings.HasPrefix(message, "Error"), strings.Contains(message, " error: "):
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
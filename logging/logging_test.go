@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"regexp"
+	"testing"
+)
+
+// captureLog sets up logging into a buffer for the duration of the test
+func captureLog(t *testing.T, format string, level Level) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	flags, output := log.Flags(), log.Writer()
+	Setup(&buf, format, level, "agent-1")
+	t.Cleanup(func() {
+		log.SetFlags(flags)
+		log.SetOutput(output)
+		configured.Store(false)
+		SetLevel(LevelInfo)
+	})
+	return &buf
+}
+
+func TestTextFormatShowsLevel(t *testing.T) {
+	buf := captureLog(t, "text", LevelInfo)
+
+	Debugf("hidden")
+	Infof("collected")
+	Warnf("disk almost full")
+	Errorf("push failed")
+
+	want := regexp.MustCompile(`^\d{4}/\d\d/\d\d \d\d:\d\d:\d\d INFO collected
+\d{4}/\d\d/\d\d \d\d:\d\d:\d\d WARN disk almost full
+\d{4}/\d\d/\d\d \d\d:\d\d:\d\d ERROR push failed
+$`)
+	if !want.Match(buf.Bytes()) {
+		t.Errorf("text output:\n%s", buf.String())
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	buf := captureLog(t, "json", LevelInfo)
+
+	Warnf("disk almost full")
+
+	var line jsonLine
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("output %q is not JSON: %v", buf.String(), err)
+	}
+	if line.Level != "warn" || line.Message != "disk almost full" || line.AgentID != "agent-1" || line.Component != "logging_test" {
+		t.Errorf("JSON line = %+v", line)
+	}
+}
@@ -0,0 +1,151 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// RotatingFile is a log file that rotates itself once it reaches a size limit, keeping
+// numbered backups (file.1 newest) pruned by count and age
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	file       *os.File
+	size       int64
+	maxSize    int64         // Bytes, 0 disables rotation
+	maxBackups int           // 0 keeps every backup
+	maxAge     time.Duration // 0 keeps backups regardless of age
+}
+
+// OpenRotatingFile opens path for appending. Limits default to no rotation until SetLimits is called.
+func OpenRotatingFile(path string) (*RotatingFile, error) {
+	r := &RotatingFile{path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetLimits configures rotation: maximum file size in MB, backups kept and backup age in days.
+// Zero disables the respective limit.
+func (r *RotatingFile) SetLimits(maxSizeMB, maxBackups, maxAgeDays int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maxSize = int64(maxSizeMB) * 1024 * 1024
+	r.maxBackups = maxBackups
+	r.maxAge = time.Duration(maxAgeDays) * 24 * time.Hour
+	r.prune()
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			// Keep logging to the current file rather than losing lines
+			fmt.Fprintf(os.Stderr, "log rotation failed: %v\n", err)
+		}
+	}
+
+	// The file couldn't be reopened after rotating; retry on every write and use stderr meanwhile
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return os.Stderr.Write(p)
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current log file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Close()
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// rotate shifts file.N to file.N+1, moves the current file to file.1 and reopens it. The file
+// is reopened for appending even when rotation fails, so logging carries on in the current
+// file; if that fails too r.file is left nil.
+func (r *RotatingFile) rotate() error {
+	err := r.file.Close()
+	r.file = nil
+	if err == nil {
+		err = r.shiftBackups()
+	}
+
+	if openErr := r.open(); openErr != nil {
+		if err != nil {
+			return fmt.Errorf("%v, and reopening %s failed: %v", err, r.path, openErr)
+		}
+		return openErr
+	}
+	return err
+}
+
+// shiftBackups renames the backups and the current file up by one and prunes the oldest
+func (r *RotatingFile) shiftBackups() error {
+	last := r.maxBackups
+	if last <= 0 {
+		// Unlimited backups: shift everything that exists
+		for last = 1; fileExists(r.backupPath(last)); last++ {
+		}
+	}
+	os.Remove(r.backupPath(last))
+	for i := last - 1; i >= 1; i-- {
+		os.Rename(r.backupPath(i), r.backupPath(i+1))
+	}
+	if err := os.Rename(r.path, r.backupPath(1)); err != nil {
+		return err
+	}
+
+	r.prune()
+	return nil
+}
+
+// prune removes backups beyond maxBackups or older than maxAge
+func (r *RotatingFile) prune() {
+	cutoff := time.Now().Add(-r.maxAge)
+	for i := 1; ; i++ {
+		info, err := os.Stat(r.backupPath(i))
+		if err != nil {
+			return
+		}
+		if (r.maxBackups > 0 && i > r.maxBackups) || (r.maxAge > 0 && info.ModTime().Before(cutoff)) {
+			os.Remove(r.backupPath(i))
+		}
+	}
+}
+
+func (r *RotatingFile) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", r.path, n)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
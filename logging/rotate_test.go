@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFileRotatesAtSizeLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.log")
+	r, err := OpenRotatingFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.maxSize = 10
+	r.maxBackups = 1
+
+	r.Write([]byte("first line\n"))
+	r.Write([]byte("second\n"))
+
+	if data, _ := os.ReadFile(path + ".1"); string(data) != "first line\n" {
+		t.Errorf("backup holds %q, want the first line", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "second\n" {
+		t.Errorf("log file holds %q, want the second line", data)
+	}
+}
+
+func TestRotatingFileKeepsLoggingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.log")
+	r, err := OpenRotatingFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.maxSize = 10
+	r.maxBackups = 1
+
+	// A non-empty directory in place of the backup can be neither removed nor replaced
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	r.Write([]byte("first line\n"))
+	if err := r.rotate(); err == nil {
+		t.Fatal("rotate succeeded onto a directory")
+	}
+	if _, err := r.Write([]byte("second\n")); err != nil {
+		t.Fatalf("Write after a failed rotation: %v", err)
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "first line\nsecond\n" {
+		t.Errorf("log file holds %q, want both lines appended", data)
+	}
+}
@@ -2,71 +2,163 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
 	"monitoring-agent/agent"
 	"monitoring-agent/config"
+	"monitoring-agent/logging"
 )
 
 func main() {
+	selfTest := flag.Bool("selftest", false, "Run every collector once, report timing and fallbacks, then exit")
+	dryRun := flag.Bool("dry-run", false, "Print the server and metrics records that would be sent, then exit (overrides DRY_RUN)")
+	once := flag.Bool("once", false, "Run a single collection cycle, push the results and exit (for cron or systemd timers)")
+	showVersion := flag.Bool("version", false, "Print the agent version and exit")
+	envFile := flag.String("config", "", "Environment file to load instead of the default locations")
+	pocketBaseURL := flag.String("pocketbase-url", "", "PocketBase URL (overrides POCKETBASE_URL)")
+	agentID := flag.String("agent-id", "", "Agent ID (overrides AGENT_ID)")
+	checkInterval := flag.Duration("check-interval", 0, "Metrics collection interval (overrides CHECK_INTERVAL)")
+	healthPort := flag.Int("health-port", 0, "Health check server port (overrides HEALTH_CHECK_PORT)")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("monitoring-agent %s (commit %s, built %s)\n", agent.Version, agent.Commit, agent.BuildDate)
+		return
+	}
+
+	// Flags take precedence over environment variables, which take precedence over defaults
+	config.SetEnvFile(*envFile)
+	overrides := map[string]string{}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "pocketbase-url":
+			overrides["POCKETBASE_URL"] = *pocketBaseURL
+		case "agent-id":
+			overrides["AGENT_ID"] = *agentID
+		case "check-interval":
+			overrides["CHECK_INTERVAL"] = checkInterval.String()
+		case "health-port":
+			overrides["HEALTH_CHECK_PORT"] = strconv.Itoa(*healthPort)
+		case "dry-run":
+			overrides["DRY_RUN"] = strconv.FormatBool(*dryRun)
+		}
+	})
+	config.SetOverrides(overrides)
+
+	if *selfTest {
+		// Configuration is optional here so a broken .env doesn't hide collector problems
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error (continuing with defaults): %v\n", err)
+		}
+		if !agent.RunSelfTest(cfg, os.Stdout) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Set up logging to both stdout and file
-	logFile, err := os.OpenFile("/var/log/monitoring-agent/monitoring-agent.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	logFile, err := logging.OpenRotatingFile("/var/log/monitoring-agent/monitoring-agent.log")
 	if err != nil {
 		// If we can't write to the log file, just use stdout
-		log.Printf("Warning: Could not open log file: %v, using stdout only", err)
+		logging.Warnf("Could not open log file: %v, using stdout only", err)
 	} else {
 		defer logFile.Close()
 		log.SetOutput(logFile)
 	}
 
-	log.Println("=== Starting monitoring agent ===")
-	log.Printf("PID: %d", os.Getpid())
-	log.Printf("Working directory: %s", os.Getenv("PWD"))
-	log.Printf("User: %s", os.Getenv("USER"))
+	logging.Infof("=== Starting monitoring agent ===")
+	logging.Infof("Version: %s (commit %s, built %s)", agent.Version, agent.Commit, agent.BuildDate)
+	logging.Infof("PID: %d", os.Getpid())
+	logging.Infof("Working directory: %s", os.Getenv("PWD"))
+	logging.Infof("User: %s", os.Getenv("USER"))
 
 	// Load configuration with detailed error logging
-	log.Println("Loading configuration...")
+	logging.Infof("Loading configuration...")
 	cfg, err := config.Load()
 	if err != nil {
-		log.Printf("FATAL: Failed to load configuration: %v", err)
+		logging.Errorf("Failed to load configuration: %v", err)
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
 		os.Exit(1)
 	}
 
-	log.Printf("Configuration loaded successfully:")
-	log.Printf("  - Agent ID: %s", cfg.AgentID)
-	log.Printf("  - PocketBase Enabled: %t", cfg.PocketBaseEnabled)
-	log.Printf("  - PocketBase URL: %s", cfg.PocketBaseURL)
-	log.Printf("  - Server Name: %s", cfg.ServerName)
-	log.Printf("  - Check Interval: %v", cfg.CheckInterval)
-	log.Printf("  - Health Check Port: %d", cfg.HealthCheckPort)
+	// Switch to the configured rotation, format and level now that configuration is known
+	if logFile != nil {
+		logFile.SetLimits(cfg.LogMaxSizeMB, cfg.LogMaxBackups, cfg.LogMaxAgeDays)
+	}
+	logLevel, _ := logging.ParseLevel(cfg.LogLevel)
+	logging.Setup(log.Writer(), cfg.LogFormat, logLevel, cfg.AgentID)
+
+	// A dry run collects once and prints the records without registering or pushing anything
+	if cfg.DryRun {
+		if err := agent.RunDryRun(cfg, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Dry run failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logging.Infof("Configuration loaded successfully:")
+	logging.Infof("  - Agent ID: %s", cfg.AgentID)
+	logging.Infof("  - Transport: %s", cfg.Transport)
+	logging.Infof("  - PocketBase Enabled: %t", cfg.PocketBaseEnabled)
+	logging.Infof("  - PocketBase URL: %s", cfg.PocketBaseURL)
+	logging.Infof("  - Server Name: %s", cfg.ServerName)
+	logging.Infof("  - Check Interval: %v", cfg.CheckInterval)
+	logging.Infof("  - Health Check Port: %d", cfg.HealthCheckPort)
 
 	// Create and start the monitoring agent
-	log.Println("Creating monitoring agent...")
+	logging.Infof("Creating monitoring agent...")
 	monitoringAgent := agent.New(cfg)
 	
+	// One-shot mode exits non-zero when any push failed so cron and systemd notice
+	if *once {
+		if err := monitoringAgent.RunOnce(); err != nil {
+			logging.Errorf("Collection cycle failed: %v", err)
+			fmt.Fprintf(os.Stderr, "Collection cycle failed: %v\n", err)
+			os.Exit(1)
+		}
+		logging.Infof("Collection cycle completed")
+		return
+	}
+	
 	// Start monitoring in a goroutine
 	go func() {
-		log.Println("Starting monitoring agent...")
+		logging.Infof("Starting monitoring agent...")
 		if err := monitoringAgent.Start(); err != nil {
-			log.Printf("FATAL: Failed to start monitoring agent: %v", err)
+			logging.Errorf("Failed to start monitoring agent: %v", err)
 			fmt.Fprintf(os.Stderr, "Agent start error: %v\n", err)
 			os.Exit(1)
 		}
 	}()
 
-	log.Println("Monitoring agent started successfully")
+	logging.Infof("Monitoring agent started successfully")
+
+	// SIGHUP reloads the hot-reloadable settings, SIGINT/SIGTERM shut down gracefully
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+
+	for sig := range signals {
+		if sig != syscall.SIGHUP {
+			break
+		}
 
-	// Wait for interrupt signal to gracefully shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+		logging.Infof("Received SIGHUP, reloading configuration...")
+		newCfg, err := config.Reload()
+		if err != nil {
+			logging.Errorf("Configuration reload failed, keeping current settings: %v", err)
+			continue
+		}
+		monitoringAgent.Reload(newCfg)
+	}
 
-	log.Println("Shutting down monitoring agent...")
+	logging.Infof("Shutting down monitoring agent...")
 	monitoringAgent.Stop()
-	log.Println("Monitoring agent stopped")
+	logging.Infof("Monitoring agent stopped")
 }
\ No newline at end of file
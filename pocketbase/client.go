@@ -2,6 +2,7 @@ package pocketbase
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,18 +13,22 @@ import (
 type PocketBaseClient struct {
 	baseURL    string
 	httpClient *http.Client
+	maxRetries  int           // Retries for failed writes, 0 disables retrying
+	backoffBase time.Duration // Delay before the first retry
 }
 
-func NewPocketBaseClient(baseURL string) (*PocketBaseClient, error) {
+// NewPocketBaseClient creates a client for baseURL. Without options requests time out
+// after 30 seconds and use the default transport settings.
+func NewPocketBaseClient(baseURL string, opts ...ClientOption) (*PocketBaseClient, error) {
 	if baseURL == "" {
 		return nil, fmt.Errorf("PocketBase URL cannot be empty")
 	}
 
 	return &PocketBaseClient{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		baseURL:     baseURL,
+		httpClient:  newHTTPClient(opts),
+		maxRetries:  3,
+		backoffBase: time.Second,
 	}, nil
 }
 
@@ -31,8 +36,8 @@ func (c *PocketBaseClient) GetBaseURL() string {
 	return c.baseURL
 }
 
-func (c *PocketBaseClient) TestConnection() error {
-	resp, err := c.httpClient.Get(c.baseURL + "/api/health")
+func (c *PocketBaseClient) TestConnection(ctx context.Context) error {
+	resp, err := c.get(ctx, c.baseURL + "/api/health")
 	if err != nil {
 		return fmt.Errorf("connection test failed: %v", err)
 	}
@@ -45,10 +50,10 @@ func (c *PocketBaseClient) TestConnection() error {
 	return nil
 }
 
-func (c *PocketBaseClient) GetServerByID(serverID string) (*ServerRecord, error) {
-	url := fmt.Sprintf("%s/api/collections/servers/records?filter=server_id='%s'", c.baseURL, serverID)
+func (c *PocketBaseClient) GetServerByID(ctx context.Context, serverID string) (*ServerRecord, error) {
+	url := c.recordsURL("servers", "server_id="+filterValue(serverID))
 	
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get server: %v", err)
 	}
@@ -74,14 +79,14 @@ func (c *PocketBaseClient) GetServerByID(serverID string) (*ServerRecord, error)
 	return server, nil
 }
 
-func (c *PocketBaseClient) SaveServerMetrics(server ServerRecord) error {
+func (c *PocketBaseClient) SaveServerMetrics(ctx context.Context, server ServerRecord) error {
 	jsonData, err := json.Marshal(server)
 	if err != nil {
 		return fmt.Errorf("failed to marshal server record: %v", err)
 	}
 
 	url := fmt.Sprintf("%s/api/collections/servers/records", c.baseURL)
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.sendJSON(ctx, http.MethodPost, url, jsonData)
 	if err != nil {
 		return fmt.Errorf("failed to save server metrics: %v", err)
 	}
@@ -95,22 +100,38 @@ func (c *PocketBaseClient) SaveServerMetrics(server ServerRecord) error {
 	return nil
 }
 
-func (c *PocketBaseClient) UpdateServerStatus(recordID string, server ServerRecord) error {
+func (c *PocketBaseClient) UpdateServerStatus(ctx context.Context, recordID string, server ServerRecord) error {
 	jsonData, err := json.Marshal(server)
 	if err != nil {
 		return fmt.Errorf("failed to marshal server record: %v", err)
 	}
 
 	url := fmt.Sprintf("%s/api/collections/servers/records/%s", c.baseURL, recordID)
-	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(jsonData))
+	resp, err := c.sendJSON(ctx, http.MethodPatch, url, jsonData)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
+		return fmt.Errorf("failed to update server status: %v", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	defer resp.Body.Close()
 
-	resp, err := c.httpClient.Do(req)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update record, status: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// UpdateServerFields patches only the given fields of a server record
+func (c *PocketBaseClient) UpdateServerFields(ctx context.Context, recordID string, fields map[string]interface{}) error {
+	jsonData, err := json.Marshal(fields)
 	if err != nil {
-		return fmt.Errorf("failed to update server status: %v", err)
+		return fmt.Errorf("failed to marshal server fields: %v", err)
+	}
+
+	url := fmt.Sprintf("%s/api/collections/servers/records/%s", c.baseURL, recordID)
+	resp, err := c.sendJSON(ctx, http.MethodPatch, url, jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to update server fields: %v", err)
 	}
 	defer resp.Body.Close()
 
@@ -122,14 +143,14 @@ func (c *PocketBaseClient) UpdateServerStatus(recordID string, server ServerReco
 	return nil
 }
 
-func (c *PocketBaseClient) SaveServerMetricsRecord(metrics ServerMetricsRecord) error {
+func (c *PocketBaseClient) SaveServerMetricsRecord(ctx context.Context, metrics ServerMetricsRecord) error {
 	jsonData, err := json.Marshal(metrics)
 	if err != nil {
 		return fmt.Errorf("failed to marshal server metrics: %v", err)
 	}
 
 	url := fmt.Sprintf("%s/api/collections/server_metrics/records", c.baseURL)
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.sendJSON(ctx, http.MethodPost, url, jsonData)
 	if err != nil {
 		return fmt.Errorf("failed to save server metrics: %v", err)
 	}
@@ -137,16 +158,83 @@ func (c *PocketBaseClient) SaveServerMetricsRecord(metrics ServerMetricsRecord)
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to save server metrics, status: %d, body: %s", resp.StatusCode, string(body))
+		return &StatusError{Op: "save server metrics", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return nil
 }
 
+// StatusError is a request PocketBase answered with an unexpected status
+type StatusError struct {
+	Op         string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed to %s, status: %d, body: %s", e.Op, e.StatusCode, e.Body)
+}
+
+// Rejected reports whether PocketBase refused the request itself, e.g. a record failing
+// validation, so sending it again can't succeed. Authentication failures and rate limiting
+// may clear up and don't count.
+func (e *StatusError) Rejected() bool {
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return e.StatusCode >= 400 && e.StatusCode < 500
+}
+
+// batchMaxRequests is PocketBase's default limit on requests per batch
+const batchMaxRequests = 50
+
+// SaveServerMetricsRecordsBatch saves several server_metrics records through the /api/batch endpoint,
+// splitting them into batches of at most batchMaxRequests. Batch API support must be enabled in PocketBase.
+// It returns how many records from the start of records were saved, so a failure in a later batch
+// doesn't cause the earlier ones to be sent again.
+func (c *PocketBaseClient) SaveServerMetricsRecordsBatch(ctx context.Context, records []ServerMetricsRecord) (int, error) {
+	for start := 0; start < len(records); start += batchMaxRequests {
+		end := start + batchMaxRequests
+		if end > len(records) {
+			end = len(records)
+		}
+		
+		batch := BatchRequest{}
+		for _, record := range records[start:end] {
+			batch.Requests = append(batch.Requests, BatchRequestItem{
+				Method: http.MethodPost,
+				URL:    "/api/collections/server_metrics/records",
+				Body:   record,
+			})
+		}
+		
+		jsonData, err := json.Marshal(batch)
+		if err != nil {
+			return start, fmt.Errorf("failed to marshal server metrics batch: %v", err)
+		}
+		
+		url := fmt.Sprintf("%s/api/batch", c.baseURL)
+		resp, err := c.sendJSON(ctx, http.MethodPost, url, jsonData)
+		if err != nil {
+			return start, fmt.Errorf("failed to save server metrics batch: %v", err)
+		}
+		
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return start, &StatusError{Op: "save server metrics batch", StatusCode: resp.StatusCode, Body: string(body)}
+		}
+		resp.Body.Close()
+	}
+
+	return len(records), nil
+}
+
 // UpdateAgentStatus now updates the agent_status field in the servers collection
-func (c *PocketBaseClient) UpdateAgentStatus(status AgentStatusRecord) error {
+func (c *PocketBaseClient) UpdateAgentStatus(ctx context.Context, status AgentStatusRecord) error {
 	// Find the server record by agent_id (server_id)
-	server, err := c.GetServerByID(status.AgentID)
+	server, err := c.GetServerByID(ctx, status.AgentID)
 	if err != nil {
 		return fmt.Errorf("failed to find server record: %v", err)
 	}
@@ -162,7 +250,7 @@ func (c *PocketBaseClient) UpdateAgentStatus(status AgentStatusRecord) error {
 	}
 
 	url := fmt.Sprintf("%s/api/collections/servers/records/%s", c.baseURL, server.ID)
-	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create update request: %v", err)
 	}
@@ -182,10 +270,54 @@ func (c *PocketBaseClient) UpdateAgentStatus(status AgentStatusRecord) error {
 	return nil
 }
 
-func (c *PocketBaseClient) GetPendingCommands(agentID string) ([]CommandRecord, error) {
-	url := fmt.Sprintf("%s/api/collections/commands/records?filter=agent_id='%s'&&executed=false", c.baseURL, agentID)
+// SaveEvent records a host event in the server_events collection
+func (c *PocketBaseClient) SaveEvent(ctx context.Context, event EventRecord) error {
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+
+	url := fmt.Sprintf("%s/api/collections/server_events/records", c.baseURL)
+	resp, err := c.sendJSON(ctx, http.MethodPost, url, jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to save event: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to save event, status: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// SaveProbeResult records the outcome of a remote health probe in the probe_results collection
+func (c *PocketBaseClient) SaveProbeResult(ctx context.Context, result ProbeResultRecord) error {
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal probe result: %v", err)
+	}
+
+	url := fmt.Sprintf("%s/api/collections/probe_results/records", c.baseURL)
+	resp, err := c.sendJSON(ctx, http.MethodPost, url, jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to save probe result: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to save probe result, status: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+func (c *PocketBaseClient) GetPendingCommands(ctx context.Context, agentID string) ([]CommandRecord, error) {
+	url := c.recordsURL("commands", "agent_id="+filterValue(agentID)+" && executed=false")
 	
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get commands: %v", err)
 	}
@@ -210,7 +342,7 @@ func (c *PocketBaseClient) GetPendingCommands(agentID string) ([]CommandRecord,
 	return response.Items, nil
 }
 
-func (c *PocketBaseClient) MarkCommandExecuted(commandID string) error {
+func (c *PocketBaseClient) MarkCommandExecuted(ctx context.Context, commandID string) error {
 	data := map[string]bool{"executed": true}
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -218,7 +350,7 @@ func (c *PocketBaseClient) MarkCommandExecuted(commandID string) error {
 	}
 
 	url := fmt.Sprintf("%s/api/collections/commands/records/%s", c.baseURL, commandID)
-	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %v", err)
 	}
@@ -237,15 +369,38 @@ func (c *PocketBaseClient) MarkCommandExecuted(commandID string) error {
 	return nil
 }
 
+// SaveCommandResult marks a command executed and stores its output on the command record
+func (c *PocketBaseClient) SaveCommandResult(ctx context.Context, commandID string, result CommandResult) error {
+	result.Executed = true
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal command result: %v", err)
+	}
+
+	url := fmt.Sprintf("%s/api/collections/commands/records/%s", c.baseURL, commandID)
+	resp, err := c.sendJSON(ctx, http.MethodPatch, url, jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to save command result: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to save command result, status: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
 // SaveDockerRecord saves a Docker container record
-func (c *PocketBaseClient) SaveDockerRecord(docker DockerRecord) error {
+func (c *PocketBaseClient) SaveDockerRecord(ctx context.Context, docker DockerRecord) error {
 	jsonData, err := json.Marshal(docker)
 	if err != nil {
 		return fmt.Errorf("failed to marshal docker record: %v", err)
 	}
 
 	url := fmt.Sprintf("%s/api/collections/dockers/records", c.baseURL)
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.sendJSON(ctx, http.MethodPost, url, jsonData)
 	if err != nil {
 		return fmt.Errorf("failed to save docker record: %v", err)
 	}
@@ -260,14 +415,14 @@ func (c *PocketBaseClient) SaveDockerRecord(docker DockerRecord) error {
 }
 
 // SaveDockerMetricsRecord saves Docker container metrics
-func (c *PocketBaseClient) SaveDockerMetricsRecord(metrics DockerMetricsRecord) error {
+func (c *PocketBaseClient) SaveDockerMetricsRecord(ctx context.Context, metrics DockerMetricsRecord) error {
 	jsonData, err := json.Marshal(metrics)
 	if err != nil {
 		return fmt.Errorf("failed to marshal docker metrics: %v", err)
 	}
 
 	url := fmt.Sprintf("%s/api/collections/docker_metrics/records", c.baseURL)
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.sendJSON(ctx, http.MethodPost, url, jsonData)
 	if err != nil {
 		return fmt.Errorf("failed to save docker metrics: %v", err)
 	}
@@ -282,10 +437,10 @@ func (c *PocketBaseClient) SaveDockerMetricsRecord(metrics DockerMetricsRecord)
 }
 
 // GetDockerByID gets a Docker container record by docker_id
-func (c *PocketBaseClient) GetDockerByID(dockerID string) (*DockerRecord, error) {
-	url := fmt.Sprintf("%s/api/collections/dockers/records?filter=docker_id='%s'", c.baseURL, dockerID)
+func (c *PocketBaseClient) GetDockerByID(ctx context.Context, dockerID string) (*DockerRecord, error) {
+	url := c.recordsURL("dockers", "docker_id="+filterValue(dockerID))
 	
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get docker record: %v", err)
 	}
@@ -312,20 +467,14 @@ func (c *PocketBaseClient) GetDockerByID(dockerID string) (*DockerRecord, error)
 }
 
 // UpdateDockerRecord updates an existing Docker record
-func (c *PocketBaseClient) UpdateDockerRecord(recordID string, docker DockerRecord) error {
+func (c *PocketBaseClient) UpdateDockerRecord(ctx context.Context, recordID string, docker DockerRecord) error {
 	jsonData, err := json.Marshal(docker)
 	if err != nil {
 		return fmt.Errorf("failed to marshal docker record: %v", err)
 	}
 
 	url := fmt.Sprintf("%s/api/collections/dockers/records/%s", c.baseURL, recordID)
-	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.sendJSON(ctx, http.MethodPatch, url, jsonData)
 	if err != nil {
 		return fmt.Errorf("failed to update docker record: %v", err)
 	}
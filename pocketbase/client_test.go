@@ -0,0 +1,45 @@
+package pocketbase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSaveServerMetricsRecordsBatchReportsSavedPrefix(t *testing.T) {
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch struct {
+			Requests []json.RawMessage `json:"requests"`
+		}
+		json.NewDecoder(r.Body).Decode(&batch)
+		batches = append(batches, len(batch.Requests))
+
+		// Accept the first batch only
+		if len(batches) > 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewPocketBaseClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := make([]ServerMetricsRecord, batchMaxRequests+20)
+	saved, err := client.SaveServerMetricsRecordsBatch(context.Background(), records)
+	if err == nil {
+		t.Fatal("SaveServerMetricsRecordsBatch succeeded although the second batch was rejected")
+	}
+	if saved != batchMaxRequests {
+		t.Errorf("saved = %d, want %d", saved, batchMaxRequests)
+	}
+	if len(batches) != 2 || batches[0] != batchMaxRequests || batches[1] != 20 {
+		t.Errorf("batch sizes = %v, want [%d 20]", batches, batchMaxRequests)
+	}
+}
@@ -0,0 +1,22 @@
+package pocketbase
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// filterValue quotes a string for use inside a PocketBase filter expression,
+// escaping backslashes and single quotes so the value can't end the literal early
+func filterValue(value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `'`, `\'`)
+	return "'" + escaped + "'"
+}
+
+// recordsURL builds a collection list URL with a URL-encoded filter parameter
+func (c *PocketBaseClient) recordsURL(collection, filter string) string {
+	query := url.Values{}
+	query.Set("filter", filter)
+	return fmt.Sprintf("%s/api/collections/%s/records?%s", c.baseURL, collection, query.Encode())
+}
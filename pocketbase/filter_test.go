@@ -0,0 +1,78 @@
+package pocketbase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFilterValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"agent-1", `'agent-1'`},
+		{"it's", `'it\'s'`},
+		{`back\slash`, `'back\\slash'`},
+		{`x' || server_id != '`, `'x\' || server_id != \''`},
+		{`\'`, `'\\\''`},
+	}
+
+	for _, tt := range tests {
+		if got := filterValue(tt.value); got != tt.want {
+			t.Errorf("filterValue(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGetServerByIDEscapesFilter(t *testing.T) {
+	tests := []struct {
+		serverID   string
+		wantFilter string
+		wantQuery  string
+	}{
+		{"agent-1", `server_id='agent-1'`, `filter=server_id%3D%27agent-1%27`},
+		{"it's", `server_id='it\'s'`, `filter=server_id%3D%27it%5C%27s%27`},
+		{"a&b=c #1", `server_id='a&b=c #1'`, `filter=server_id%3D%27a%26b%3Dc+%231%27`},
+		{`x' || server_id != '`, `server_id='x\' || server_id != \''`, `filter=server_id%3D%27x%5C%27+%7C%7C+server_id+%21%3D+%5C%27%27`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serverID, func(t *testing.T) {
+			var gotPath, gotFilter, gotQuery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				gotFilter = r.URL.Query().Get("filter")
+				gotQuery = r.URL.RawQuery
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"items": []ServerRecord{{ID: "rec1", ServerID: tt.serverID}},
+				})
+			}))
+			defer server.Close()
+
+			client, err := NewPocketBaseClient(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			record, err := client.GetServerByID(context.Background(), tt.serverID)
+			if err != nil {
+				t.Fatalf("GetServerByID: %v", err)
+			}
+
+			if gotPath != "/api/collections/servers/records" {
+				t.Errorf("path = %s, want /api/collections/servers/records", gotPath)
+			}
+			if gotQuery != tt.wantQuery {
+				t.Errorf("query = %s, want %s", gotQuery, tt.wantQuery)
+			}
+			if gotFilter != tt.wantFilter {
+				t.Errorf("filter = %s, want %s", gotFilter, tt.wantFilter)
+			}
+			if record.ID != "rec1" {
+				t.Errorf("record ID = %q, want rec1", record.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package pocketbase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// headerTransport sets the agent's User-Agent and a unique X-Request-ID on every request,
+// so agent traffic can be identified and correlated in reverse-proxy logs
+type headerTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+// NewHeaderTransport wraps base to add the identifying headers. Headers already set on a
+// request are kept.
+func NewHeaderTransport(base http.RoundTripper, userAgent string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &headerTransport{base: base, userAgent: userAgent}
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	if t.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	if req.Header.Get("X-Request-ID") == "" {
+		req.Header.Set("X-Request-ID", newRequestID())
+	}
+	return t.base.RoundTrip(req)
+}
+
+// newRequestID returns 16 random bytes in hex, empty if the system's randomness fails
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
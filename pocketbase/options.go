@@ -0,0 +1,110 @@
+package pocketbase
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ClientOption customises the HTTP client used to talk to PocketBase
+type ClientOption func(*clientSettings)
+
+// clientSettings collects option values before the HTTP client is built
+type clientSettings struct {
+	timeout      time.Duration
+	maxIdleConns int
+	tlsConfig    *tls.Config
+	userAgent    string
+}
+
+// WithTimeout sets the overall timeout of each request (default 30s)
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(s *clientSettings) {
+		if timeout > 0 {
+			s.timeout = timeout
+		}
+	}
+}
+
+// WithMaxIdleConns sets how many keep-alive connections are kept open (default 100)
+func WithMaxIdleConns(n int) ClientOption {
+	return func(s *clientSettings) {
+		if n > 0 {
+			s.maxIdleConns = n
+		}
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for https PocketBase URLs
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(s *clientSettings) {
+		s.tlsConfig = tlsConfig
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(s *clientSettings) {
+		s.userAgent = userAgent
+	}
+}
+
+// newHTTPClient builds the HTTP client from the given options, starting from the
+// standard library's default transport so proxy settings keep working
+func newHTTPClient(opts []ClientOption) *http.Client {
+	settings := clientSettings{
+		timeout:      30 * time.Second,
+		maxIdleConns: 100,
+	}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = settings.maxIdleConns
+	if settings.maxIdleConns > transport.MaxIdleConnsPerHost {
+		// A single PocketBase host is all we talk to, let it use the whole pool
+		transport.MaxIdleConnsPerHost = settings.maxIdleConns
+	}
+	if settings.tlsConfig != nil {
+		transport.TLSClientConfig = settings.tlsConfig
+	}
+
+	return &http.Client{
+		Timeout:   settings.timeout,
+		Transport: NewHeaderTransport(transport, settings.userAgent),
+	}
+}
+
+// NewTLSConfig builds a TLS configuration trusting the CA certificates in caCertFile on top
+// of the system pool. Returns nil when neither option is set so the default transport applies.
+func NewTLSConfig(caCertFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	if caCertFile == "" && !insecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+
+	if caCertFile != "" {
+		pem, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %v", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", caCertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,80 @@
+package pocketbase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"monitoring-agent/logging"
+)
+
+// SetRetryPolicy configures how many times failed writes are retried and the initial backoff,
+// which doubles after every attempt
+func (c *PocketBaseClient) SetRetryPolicy(maxRetries int, backoffBase time.Duration) {
+	c.maxRetries = maxRetries
+	c.backoffBase = backoffBase
+}
+
+// get performs a GET request that is abandoned when ctx is done
+func (c *PocketBaseClient) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.httpClient.Do(req)
+}
+
+// sendJSON sends a JSON body, retrying transient failures according to the retry policy
+func (c *PocketBaseClient) sendJSON(ctx context.Context, method, url string, jsonData []byte) (*http.Response, error) {
+	return c.doWithRetry(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+}
+
+// doWithRetry performs the request built by newRequest, retrying connection errors, timeouts and 5xx
+// responses with exponential backoff. 4xx responses are returned immediately. After the last attempt
+// the final response or error is returned for the caller to report. Cancelling ctx aborts the
+// request in flight and any remaining backoff.
+func (c *PocketBaseClient) doWithRetry(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	backoff := c.backoffBase
+
+	for attempt := 0; ; attempt++ {
+		// The body is consumed by each attempt, so the request is rebuilt every time
+		req, err := newRequest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		retryable := (err != nil && ctx.Err() == nil) || (err == nil && resp.StatusCode >= http.StatusInternalServerError)
+		if !retryable || attempt >= c.maxRetries {
+			if err != nil && attempt > 0 {
+				err = fmt.Errorf("%v (after %d attempts)", err, attempt+1)
+			}
+			return resp, err
+		}
+
+		if err != nil {
+			logging.Warnf("PocketBase request %s %s failed (attempt %d/%d): %v, retrying in %v", req.Method, req.URL.Path, attempt+1, c.maxRetries+1, err, backoff)
+		} else {
+			logging.Warnf("PocketBase request %s %s returned %d (attempt %d/%d), retrying in %v", req.Method, req.URL.Path, resp.StatusCode, attempt+1, c.maxRetries+1, backoff)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,87 @@
+package pocketbase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendJSONRetriesServerErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewPocketBaseClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetRetryPolicy(3, time.Millisecond)
+
+	resp, err := client.sendJSON(context.Background(), http.MethodPost, server.URL, []byte("{}"))
+	if err != nil {
+		t.Fatalf("sendJSON: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 3 {
+		t.Errorf("got status %d after %d attempts, want 200 after 3", resp.StatusCode, attempts.Load())
+	}
+}
+
+func TestSendJSONStopsBackoffOnCancel(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client, err := NewPocketBaseClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetRetryPolicy(3, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.sendJSON(ctx, http.MethodPost, server.URL, []byte("{}"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("sendJSON error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("sendJSON returned after %v, the backoff ignored the cancelled context", elapsed)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("server saw %d attempts, want 1", attempts.Load())
+	}
+}
+
+func TestGetHonorsCancelledContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client, err := NewPocketBaseClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetServerByID(ctx, "agent-1"); err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("GetServerByID error = %v, want the context deadline", err)
+	}
+}
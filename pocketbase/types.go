@@ -7,6 +7,11 @@ import (
 	"time"
 )
 
+// SchemaVersion identifies the shape of the server_metrics and docker_metrics payloads. Bump
+// it whenever fields are added, renamed or change meaning, so the backend can tell records
+// from agents of different versions apart during rolling upgrades.
+const SchemaVersion = 8
+
 // FlexibleTime handles multiple timestamp formats from PocketBase
 type FlexibleTime struct {
 	time.Time
@@ -119,12 +124,14 @@ type ServerRecord struct {
 	Name           string       `json:"name"`
 	Hostname       string       `json:"hostname"`
 	IPAddress      string       `json:"ip_address"`
+	IPv6Address    string       `json:"ipv6_address,omitempty"`
 	OSType         string       `json:"os_type"`
 	Status         string       `json:"status"`
 	Uptime         string       `json:"uptime"`
 	RAMTotal       int64        `json:"ram_total"`
 	RAMUsed        int64        `json:"ram_used"`
 	CPUCores       int          `json:"cpu_cores"`
+	CPUCoresEffective int       `json:"cpu_cores_effective"` // Usable after affinity/cpuset restrictions
 	CPUUsage       float64      `json:"cpu_usage"`
 	DiskTotal      int64        `json:"disk_total"`
 	DiskUsed       int64        `json:"disk_used"`
@@ -135,9 +142,22 @@ type ServerRecord struct {
 	Timestamp      string       `json:"timestamp"`
 	Connection     string       `json:"connection"`
 	SystemInfo     string       `json:"system_info"`
+	OSName         string       `json:"os_name,omitempty"` // Structured copies of the system_info parts
+	OSVersion      string       `json:"os_version,omitempty"`
+	KernelVersion  string       `json:"kernel_version,omitempty"`
+	Architecture   string       `json:"architecture,omitempty"`
+	CPUModel       string       `json:"cpu_model,omitempty"`
+	GoVersion      string       `json:"go_version,omitempty"`
 	AgentStatus    string       `json:"agent_status,omitempty"`
 	CheckInterval  FlexibleInt  `json:"check_interval,omitempty"`
 	Docker         FlexibleBool `json:"docker,omitempty"`
+	DockerStopped  int          `json:"docker_stopped"`
+	DockerOldestStoppedAge int64 `json:"docker_oldest_stopped_age"` // Seconds
+	BootTime       FlexibleTime `json:"boot_time"`
+	Timezone       string       `json:"timezone"`
+	Locale         string       `json:"locale"`
+	KernelTaint    int64        `json:"kernel_taint"`
+	KernelTaintFlags string     `json:"kernel_taint_flags"` // Comma-separated, e.g. "out_of_tree_module,kernel_oops"
 	Created        FlexibleTime `json:"created,omitempty"`
 	Updated        FlexibleTime `json:"updated,omitempty"`
 }
@@ -146,24 +166,206 @@ type ServerMetricsRecord struct {
 	ID              string       `json:"id,omitempty"`
 	ServerID        string       `json:"server_id"`
 	Timestamp       time.Time    `json:"timestamp"`
+	SchemaVersion   int          `json:"schema_version"`
 	RAMTotal        string       `json:"ram_total"`
 	RAMUsed         string       `json:"ram_used"`
 	RAMFree         string       `json:"ram_free"`
+	RAMTotalBytes   int64        `json:"ram_total_bytes"`
+	RAMUsedBytes    int64        `json:"ram_used_bytes"`
+	RAMFreeBytes    int64        `json:"ram_free_bytes"`
+	RAMUsedPercent  float64      `json:"ram_used_percent"`
 	CPUCores        string       `json:"cpu_cores"`
+	CPUCoreCount    int          `json:"cpu_core_count"`
+	CPUCoresEffective int        `json:"cpu_cores_effective"`
 	CPUUsage        string       `json:"cpu_usage"`
 	CPUFree         string       `json:"cpu_free"`
+	CPUUsagePercent float64      `json:"cpu_usage_percent"`
+	CPUFreePercent  float64      `json:"cpu_free_percent"`
+	CPUStealPercent  float64     `json:"cpu_steal_percent"`  // Time taken by the hypervisor for other VMs
+	CPUIOWaitPercent float64     `json:"cpu_iowait_percent"` // Idle time with I/O outstanding
+	CPUBreakdown    *CPUBreakdownMetrics `json:"cpu_breakdown,omitempty"`
+	CPUPerCore      []float64    `json:"cpu_per_core"`
+	Load1           float64      `json:"load_1"`
+	Load5           float64      `json:"load_5"`
+	Load15          float64      `json:"load_15"`
+	SchedDelayMs    float64      `json:"sched_delay_ms,omitempty"`    // Average run-queue wait per timeslice
+	CPUPressureSome float64      `json:"cpu_pressure_some,omitempty"` // PSI cpu some avg10
+	SwapTotal       string       `json:"swap_total"`
+	SwapUsed        string       `json:"swap_used"`
+	SwapFree        string       `json:"swap_free"`
+	SwapTotalBytes  int64        `json:"swap_total_bytes"`
+	SwapUsedBytes   int64        `json:"swap_used_bytes"`
+	SwapFreeBytes   int64        `json:"swap_free_bytes"`
+	SwapUsedPercent float64      `json:"swap_used_percent"`
+	SwapDevices     []SwapDeviceMetrics `json:"swap_devices,omitempty"`
+	TopProcesses    []TopProcessMetrics `json:"top_processes,omitempty"` // Busiest processes by CPU
+	Agent           *AgentMetrics `json:"agent,omitempty"` // The monitoring agent's own resource usage
+	CPUTemp         float64      `json:"cpu_temp,omitempty"` // Hottest thermal zone in Celsius
+	ThermalZones    []ThermalZoneMetrics `json:"thermal_zones,omitempty"`
+	HugePagesTotal    int64      `json:"hugepages_total,omitempty"`
+	HugePagesFree     int64      `json:"hugepages_free,omitempty"`
+	HugePagesReserved int64      `json:"hugepages_reserved,omitempty"`
+	HugePageSize      int64      `json:"hugepage_size,omitempty"`
+	BuddyFreeBlocks      []int64 `json:"buddy_free_blocks,omitempty"`
+	HighOrderFreePercent float64 `json:"high_order_free_percent,omitempty"`
+	FragmentationAlert   bool    `json:"fragmentation_alert,omitempty"`
 	DiskTotal       string       `json:"disk_total"`
 	DiskUsed        string       `json:"disk_used"`
 	DiskFree        string       `json:"disk_free"`
+	DiskTotalBytes  int64        `json:"disk_total_bytes"`
+	DiskUsedBytes   int64        `json:"disk_used_bytes"`
+	DiskFreeBytes   int64        `json:"disk_free_bytes"`
+	DiskUsedPercent float64      `json:"disk_used_percent"`
+	DiskStatus      string       `json:"disk_status,omitempty"` // "stale" when the mount stopped answering (e.g. dead NFS server)
+	InodeTotal      int64        `json:"inode_total"`
+	InodeUsed       int64        `json:"inode_used"`
+	InodePercentage float64      `json:"inode_percentage"`
+	FDUsed          int64        `json:"fd_used"`
+	FDMax           int64        `json:"fd_max"`
+	FDAlert         bool         `json:"fd_alert"`
+	ProcessCount    int          `json:"process_count"`
+	ThreadCount     int          `json:"thread_count"`
 	Status          string       `json:"status"`
 	NetworkRxBytes  int64        `json:"network_rx_bytes"`
 	NetworkTxBytes  int64        `json:"network_tx_bytes"`
 	NetworkRxSpeed  int64        `json:"network_rx_speed"`
 	NetworkTxSpeed  int64        `json:"network_tx_speed"`
+	NetworkRx       string       `json:"network_rx,omitempty"`      // NETWORK_UNIT display strings, unset for "bytes"
+	NetworkTx       string       `json:"network_tx,omitempty"`
+	NetworkRxRate   string       `json:"network_rx_rate,omitempty"`
+	NetworkTxRate   string       `json:"network_tx_rate,omitempty"`
+	NetworkInterfaces   []InterfaceMetrics `json:"network_interfaces,omitempty"`
+	TCPSynRecv          int      `json:"tcp_syn_recv"`
+	TCPSynRecvAlert     bool     `json:"tcp_syn_recv_alert"`
+	ConntrackCount      int64    `json:"conntrack_count,omitempty"`
+	ConntrackMax        int64    `json:"conntrack_max,omitempty"`
+	ConntrackPercentage float64  `json:"conntrack_percentage,omitempty"`
+	ConntrackAlert      bool     `json:"conntrack_alert,omitempty"`
+	EntropyAvailable    int64    `json:"entropy_available,omitempty"`
+	HWRNGSource         string   `json:"hw_rng_source,omitempty"`
+	EntropySoftwareOnly bool     `json:"entropy_software_only,omitempty"`
+	MonitoredProcesses  []ProcessMetrics `json:"monitored_processes,omitempty"`
+	NetworkLinks        []LinkMetrics    `json:"network_links,omitempty"`
+	OverdueTimers       []TimerMetrics   `json:"overdue_timers,omitempty"`
+	CustomMetrics       []CustomMetric   `json:"custom_metrics,omitempty"`
+	StorageArrays       []StorageArrayMetrics `json:"storage_arrays,omitempty"`
+	CoreDumpsTotal      int      `json:"core_dumps_total,omitempty"`
+	CoreDumpsNew        int      `json:"core_dumps_new,omitempty"`
+	LastCrashExecutable string   `json:"last_crash_executable,omitempty"`
+	EgressBytes         int64    `json:"egress_bytes,omitempty"` // Outbound bytes in the current quota period
+	EgressPeriodStart   string   `json:"egress_period_start,omitempty"`
+	EgressQuotaPercent  float64  `json:"egress_quota_percent,omitempty"`
+	EgressQuotaExceeded bool     `json:"egress_quota_exceeded,omitempty"`
 	Created         FlexibleTime `json:"created,omitempty"`
 	Updated         FlexibleTime `json:"updated,omitempty"`
 }
 
+// SwapDeviceMetrics represents usage of a single swap device or file
+type SwapDeviceMetrics struct {
+	Device   string `json:"device"`
+	Type     string `json:"type"`
+	Size     int64  `json:"size"`
+	Used     int64  `json:"used"`
+	Priority int    `json:"priority"`
+}
+
+// ProcessMetrics represents a monitored process
+type ProcessMetrics struct {
+	Name        string `json:"name"`
+	PID         int    `json:"pid"`
+	Threads     int    `json:"threads"`
+	ThreadAlert bool   `json:"thread_alert"`
+}
+
+// InterfaceMetrics represents traffic on a single network interface
+type InterfaceMetrics struct {
+	Interface string `json:"interface"`
+	RxBytes   int64  `json:"rx_bytes"`
+	TxBytes   int64  `json:"tx_bytes"`
+	RxSpeed   int64  `json:"rx_speed"`
+	TxSpeed   int64  `json:"tx_speed"`
+	Rx        string `json:"rx,omitempty"` // NETWORK_UNIT display strings, unset for "bytes"
+	Tx        string `json:"tx,omitempty"`
+	RxRate    string `json:"rx_rate,omitempty"`
+	TxRate    string `json:"tx_rate,omitempty"`
+}
+
+// LinkMetrics represents the link state of a network interface
+type LinkMetrics struct {
+	Interface string `json:"interface"`
+	OperState string `json:"oper_state"`
+	SpeedMbps int64  `json:"speed_mbps"`
+	LowSpeed  bool   `json:"low_speed"`
+}
+
+// TimerMetrics represents a systemd timer that has stopped firing on schedule
+type TimerMetrics struct {
+	Unit        string `json:"unit"`
+	ActiveState string `json:"active_state"`
+	LastTrigger string `json:"last_trigger"`
+	NextElapse  string `json:"next_elapse"`
+}
+
+// StorageArrayMetrics represents scrub/resilver state of an mdadm array or ZFS pool
+type StorageArrayMetrics struct {
+	Name                string  `json:"name"`
+	Type                string  `json:"type"`
+	Operation           string  `json:"operation"`
+	Progress            float64 `json:"progress"`
+	EstimatedCompletion string  `json:"estimated_completion"`
+	LastScrub           string  `json:"last_scrub"`
+	ScrubOverdue        bool    `json:"scrub_overdue"`
+}
+
+// CustomMetric represents an application-defined metric aggregated over one cycle.
+// For timers Value is the mean and Count/Min/Max describe the samples. Command metrics
+// carry numeric output in Value and anything else in Text.
+type CustomMetric struct {
+	Name  string  `json:"name"`
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+	Text  string  `json:"text,omitempty"`
+	Error string  `json:"error,omitempty"` // Why a command metric failed or exited non-zero
+	Count int     `json:"count,omitempty"`
+	Min   float64 `json:"min,omitempty"`
+	Max   float64 `json:"max,omitempty"`
+}
+
+// TopProcessMetrics represents one of the busiest processes in a cycle
+type TopProcessMetrics struct {
+	PID        int     `json:"pid"`
+	Name       string  `json:"name"`
+	CPUPercent float64 `json:"cpu_percent"`
+	RSSBytes   int64   `json:"rss_bytes"`
+}
+
+// CPUBreakdownMetrics is the percentage of CPU time spent in each state since the previous sample
+type CPUBreakdownMetrics struct {
+	User    float64 `json:"user"` // Including niced processes
+	System  float64 `json:"system"`
+	IOWait  float64 `json:"iowait"`
+	IRQ     float64 `json:"irq"`
+	SoftIRQ float64 `json:"softirq"`
+	Steal   float64 `json:"steal"`
+	Idle    float64 `json:"idle"`
+}
+
+// AgentMetrics represents the monitoring agent's own resource usage
+type AgentMetrics struct {
+	CPUPercent   float64 `json:"cpu_percent"` // Share of one core since the previous sample
+	RSSBytes     int64   `json:"rss_bytes"`
+	PeakRSSBytes int64   `json:"peak_rss_bytes"`
+	Threads      int     `json:"threads"`
+	Goroutines   int     `json:"goroutines"`
+}
+
+// ThermalZoneMetrics represents the temperature of one kernel thermal zone
+type ThermalZoneMetrics struct {
+	Zone        string  `json:"zone"`
+	Label       string  `json:"label"`
+	TempCelsius float64 `json:"temp_celsius"`
+}
+
 type MetricsRecord struct {
 	AgentID       string    `json:"agent_id"`
 	Timestamp     time.Time `json:"timestamp"`
@@ -185,6 +387,40 @@ type AgentStatusRecord struct {
 	Message     string    `json:"message"`
 }
 
+// EventRecord represents a notable host event such as a reboot
+type EventRecord struct {
+	ID        string    `json:"id,omitempty"`
+	ServerID  string    `json:"server_id"`
+	Type      string    `json:"type"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ProbeResultRecord is the outcome of a check_http or check_tcp remote command
+type ProbeResultRecord struct {
+	ID         string    `json:"id,omitempty"`
+	ServerID   string    `json:"server_id"`
+	Type       string    `json:"type"`   // check_http or check_tcp
+	Target     string    `json:"target"` // URL or host:port
+	Up         bool      `json:"up"`
+	StatusCode int       `json:"status_code,omitempty"` // HTTP probes only
+	LatencyMs  float64   `json:"latency_ms"`
+	Error      string    `json:"error,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// BatchRequest is the body of a PocketBase /api/batch call
+type BatchRequest struct {
+	Requests []BatchRequestItem `json:"requests"`
+}
+
+// BatchRequestItem is a single operation within a batch
+type BatchRequestItem struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Body   interface{} `json:"body,omitempty"`
+}
+
 type CommandRecord struct {
 	ID         string       `json:"id"`
 	AgentID    string       `json:"agent_id"`
@@ -194,6 +430,15 @@ type CommandRecord struct {
 	CreatedAt  FlexibleTime `json:"created"`
 }
 
+// CommandResult is written back to a command record once it ran
+type CommandResult struct {
+	Executed bool   `json:"executed"`
+	Result   string `json:"result,omitempty"` // Outcome summary, or why the command was rejected
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+}
+
 // DockerRecord represents a Docker container record
 type DockerRecord struct {
 	ID             string       `json:"id,omitempty"`
@@ -209,11 +454,20 @@ type DockerRecord struct {
 	CPUUsage       float64      `json:"cpu_usage"`
 	DiskTotal      int64        `json:"disk_total"`
 	DiskUsed       int64        `json:"disk_used"`
+	StatsAvailable bool         `json:"stats_available"` // False when RAM and CPU usage couldn't be measured and are 0
+	RAMUnlimited   bool         `json:"ram_unlimited"`   // No memory limit, RAM total is the host's memory
 	LastChecked    FlexibleTime `json:"last_checked"`
 	TemplateID     string       `json:"template_id"`
 	NotificationID string       `json:"notification_id"`
 	Timestamp      string       `json:"timestamp"`
 	Status         string       `json:"status"`
+	ComposeProject string       `json:"compose_project"`
+	ComposeService string       `json:"compose_service"`
+	RestartCount   int          `json:"restart_count"`
+	LastExitCode   int          `json:"last_exit_code"`
+	Image          string       `json:"image"`
+	ImageRepo      string       `json:"image_repo"`
+	ImageTag       string       `json:"image_tag"`
 	Created        FlexibleTime `json:"created,omitempty"`
 	Updated        FlexibleTime `json:"updated,omitempty"`
 }
@@ -223,20 +477,30 @@ type DockerMetricsRecord struct {
 	ID              string       `json:"id,omitempty"`
 	DockerID        string       `json:"docker_id"`
 	Timestamp       time.Time    `json:"timestamp"`
+	SchemaVersion   int          `json:"schema_version"`
 	RAMTotal        string       `json:"ram_total"`
 	RAMUsed         string       `json:"ram_used"`
 	RAMFree         string       `json:"ram_free"`
 	CPUCores        string       `json:"cpu_cores"`
 	CPUUsage        string       `json:"cpu_usage"`
 	CPUFree         string       `json:"cpu_free"`
+	StatsAvailable  bool         `json:"stats_available"` // False when the RAM and CPU fields are left empty
+	RAMUnlimited    bool         `json:"ram_unlimited"`   // No memory limit, RAM total is the host's memory
 	DiskTotal       string       `json:"disk_total"`
 	DiskUsed        string       `json:"disk_used"`
-	DiskFree        string       `json:"disk_free"`
+	DiskFree        string       `json:"disk_free"` // Always empty, a container has no free space of its own
 	Status          string       `json:"status"`
 	NetworkRxBytes  int64        `json:"network_rx_bytes"`
 	NetworkTxBytes  int64        `json:"network_tx_bytes"`
 	NetworkRxSpeed  int64        `json:"network_rx_speed"`
 	NetworkTxSpeed  int64        `json:"network_tx_speed"`
+	NetworkRx       string       `json:"network_rx,omitempty"`      // NETWORK_UNIT display strings, unset for "bytes"
+	NetworkTx       string       `json:"network_tx,omitempty"`
+	NetworkRxRate   string       `json:"network_rx_rate,omitempty"`
+	NetworkTxRate   string       `json:"network_tx_rate,omitempty"`
+	BlkioLimited    bool         `json:"blkio_limited,omitempty"`
+	IOPressureFull  int64        `json:"io_pressure_full_usec,omitempty"` // Cumulative time all tasks stalled on I/O (cgroup v2 only)
+	BlkioBytes      int64        `json:"blkio_bytes,omitempty"`
 	Created         FlexibleTime `json:"created,omitempty"`
 	Updated         FlexibleTime `json:"updated,omitempty"`
 }
\ No newline at end of file